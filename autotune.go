@@ -0,0 +1,156 @@
+package concurrent
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// plateauHold is how many intervals the AutoTuner waits before growing the
+// limit again after growth stopped raising throughput.
+const plateauHold = 5
+
+// AutoTuner adjusts a concurrency limit to keep workers at a target
+// utilization, or average latency under an SLO. Jobs run between Acquire
+// and the release it returns, which lets the tuner sample busy time and
+// queue wait. Unlike scaling on queue depth, it only keeps extra workers
+// that raise throughput, so it converges for CPU-bound work where more
+// workers just add contention. Use it with WithAutoTune on a Pool, whose
+// worker count is then the upper bound.
+type AutoTuner struct {
+	sem     *Semaphore
+	options AutoTuneOptions
+	clock   Clock
+
+	mu         sync.Mutex
+	busy       time.Duration // busy time of jobs finished this interval
+	wait       time.Duration // queue wait of jobs started this interval
+	completed  int
+	started    int
+	last       time.Time
+	throughput float64 // jobs per second over the previous interval
+	grew       bool    // whether the last adjustment grew the limit
+	hold       int     // intervals left before growing again
+}
+
+// NewAutoTuner creates a tuner starting at the minimum limit.
+func NewAutoTuner(opts ...AutoTuneOption) *AutoTuner {
+	options := DefaultAutoTuneOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+	options.Min = max(options.Min, 1)
+	options.Max = max(options.Max, options.Min)
+	if options.Interval <= 0 {
+		options.Interval = time.Second
+	}
+	clock := clockOrReal(options.Clock)
+	return &AutoTuner{
+		sem:     NewSemaphore(options.Min),
+		options: options,
+		clock:   clock,
+		last:    clock.Now(),
+	}
+}
+
+// Limit returns the current concurrency limit.
+func (a *AutoTuner) Limit() int {
+	return a.sem.Capacity()
+}
+
+// Acquire blocks until the limit admits another job, or ctx is cancelled.
+// The returned release must be called when the job finishes.
+func (a *AutoTuner) Acquire(ctx context.Context) (release func(), err error) {
+	queued := a.clock.Now()
+	if err := a.sem.Acquire(ctx); err != nil {
+		return nil, err
+	}
+	start := a.clock.Now()
+	a.mu.Lock()
+	a.wait += start.Sub(queued)
+	a.started++
+	a.mu.Unlock()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			a.sem.Release()
+			a.mu.Lock()
+			a.busy += a.clock.Now().Sub(start)
+			a.completed++
+			a.mu.Unlock()
+		})
+	}, nil
+}
+
+// Run adjusts the limit every interval until ctx is cancelled, and returns
+// ctx's error.
+func (a *AutoTuner) Run(ctx context.Context) error {
+	ticker := a.clock.NewTicker(a.options.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-ticker.C():
+			a.adjust(now)
+		}
+	}
+}
+
+// Report implements Reporter.
+func (a *AutoTuner) Report() Report {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return Report{Kind: "auto_tuner", Stats: map[string]any{
+		"limit":      a.sem.Capacity(),
+		"in_use":     a.sem.InUse(),
+		"throughput": a.throughput,
+	}}
+}
+
+// adjust moves the limit one step based on the interval ending at now.
+func (a *AutoTuner) adjust(now time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	elapsed := now.Sub(a.last)
+	if elapsed <= 0 {
+		return
+	}
+	limit := a.sem.Capacity()
+	utilization := float64(a.busy) / (float64(limit) * float64(elapsed))
+	throughput := float64(a.completed) / elapsed.Seconds()
+	var latency, wait time.Duration
+	if a.completed > 0 {
+		latency = a.busy / time.Duration(a.completed)
+	}
+	if a.started > 0 {
+		wait = a.wait / time.Duration(a.started)
+	}
+
+	next := limit
+	switch {
+	case a.options.LatencySLO > 0 && latency > a.options.LatencySLO:
+		next--
+	case utilization < a.options.TargetUtilization:
+		// Idle capacity, unless jobs still had to queue for it.
+		if wait == 0 {
+			next--
+		}
+	case a.grew && throughput <= a.throughput*1.05:
+		// The last step up bought nothing: contention, so step back and
+		// stay put for a while.
+		next--
+		a.hold = plateauHold
+	case a.hold > 0:
+		a.hold--
+	default:
+		next++
+	}
+	next = min(max(next, a.options.Min), a.options.Max)
+	a.sem.SetCapacity(next)
+
+	a.grew = next > limit
+	a.throughput = throughput
+	a.busy, a.wait, a.completed, a.started = 0, 0, 0, 0
+	a.last = now
+}
@@ -0,0 +1,91 @@
+package concurrent
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAutoTuner(t *testing.T) {
+	// simulate feeds the tuner one interval in which every permitted worker
+	// was busy and throughput scaled with the limit up to capacity jobs.
+	simulate := func(a *AutoTuner, now time.Time, capacity int, latency time.Duration) {
+		limit := a.Limit()
+		a.mu.Lock()
+		a.busy = time.Duration(limit) * time.Second
+		a.completed = min(limit, capacity) * 100
+		a.started = a.completed
+		a.wait = time.Duration(a.started) * time.Millisecond
+		if latency > 0 {
+			a.busy = time.Duration(a.completed) * latency
+		}
+		a.mu.Unlock()
+		a.adjust(now)
+	}
+
+	t.Run("converges at contention", func(t *testing.T) {
+		clock := NewFakeClock(time.Now())
+		a := NewAutoTuner(WithAutoTuneBounds(1, 64), WithAutoTuneClock(clock))
+		now := clock.Now()
+		seen := map[int]bool{}
+		for i := range 60 {
+			now = now.Add(time.Second)
+			simulate(a, now, 4, 0)
+			if i >= 20 {
+				seen[a.Limit()] = true
+			}
+		}
+		for limit := range seen {
+			if limit < 3 || limit > 5 {
+				t.Errorf("Expected the limit to settle near 4, saw %d", limit)
+			}
+		}
+	})
+
+	t.Run("shrinks when idle", func(t *testing.T) {
+		clock := NewFakeClock(time.Now())
+		a := NewAutoTuner(WithAutoTuneBounds(1, 8), WithAutoTuneClock(clock))
+		a.sem.SetCapacity(8)
+		now := clock.Now()
+		for range 10 {
+			now = now.Add(time.Second)
+			a.adjust(now)
+		}
+		if l := a.Limit(); l != 1 {
+			t.Errorf("Expected the limit to shrink to 1, got %d", l)
+		}
+	})
+
+	t.Run("latency slo", func(t *testing.T) {
+		clock := NewFakeClock(time.Now())
+		a := NewAutoTuner(WithAutoTuneBounds(1, 8), WithLatencySLO(5*time.Millisecond), WithAutoTuneClock(clock))
+		a.sem.SetCapacity(8)
+		now := clock.Now().Add(time.Second)
+		simulate(a, now, 8, 10*time.Millisecond)
+		if l := a.Limit(); l != 7 {
+			t.Errorf("Expected the limit to shrink to 7, got %d", l)
+		}
+	})
+
+	t.Run("pool", func(t *testing.T) {
+		ctx := context.Background()
+		a := NewAutoTuner(WithAutoTuneBounds(2, 2))
+		var mu sync.Mutex
+		active, peak := 0, 0
+		results := NewPool(8, func(_ context.Context, v int) (int, error) {
+			mu.Lock()
+			active++
+			peak = max(peak, active)
+			mu.Unlock()
+			time.Sleep(time.Millisecond)
+			mu.Lock()
+			active--
+			mu.Unlock()
+			return v, nil
+		}, WithAutoTune(a)).RunSlice(ctx, make([]int, 20))
+		if len(results) != 20 || peak > 2 {
+			t.Errorf("Expected 20 results with at most 2 running, got %d and %d", len(results), peak)
+		}
+	})
+}
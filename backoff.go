@@ -0,0 +1,92 @@
+package concurrent
+
+import (
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// BackoffTicker delivers ticks at exponentially increasing intervals, for
+// reconnect loops and polling that should back off while nothing happens.
+// Call Reset after a success to start again from the base delay.
+type BackoffTicker struct {
+	c     chan time.Time
+	reset chan struct{}
+	stop  chan struct{}
+	once  sync.Once
+}
+
+// NewBackoffTicker creates a ticker whose first tick comes after
+// config.BaseDelay, with each following interval multiplied by
+// config.Multiplier up to config.MaxDelay. With config.Jitter each interval
+// is randomized by ±25%. Zero BaseDelay, MaxDelay and Multiplier take their
+// DefaultRetryConfig values; MaxRetries is ignored.
+func NewBackoffTicker(config RetryConfig) *BackoffTicker {
+	defaults := DefaultRetryConfig()
+	if config.BaseDelay <= 0 {
+		config.BaseDelay = defaults.BaseDelay
+	}
+	if config.MaxDelay <= 0 {
+		config.MaxDelay = defaults.MaxDelay
+	}
+	if config.Multiplier <= 0 {
+		config.Multiplier = defaults.Multiplier
+	}
+
+	t := &BackoffTicker{
+		c:     make(chan time.Time, 1),
+		reset: make(chan struct{}, 1),
+		stop:  make(chan struct{}),
+	}
+	go t.run(config)
+	return t
+}
+
+// C returns the channel on which ticks are delivered. Like time.Ticker, it
+// drops ticks for slow receivers.
+func (t *BackoffTicker) C() <-chan time.Time {
+	return t.c
+}
+
+// Reset restarts the backoff from the base delay.
+func (t *BackoffTicker) Reset() {
+	select {
+	case t.reset <- struct{}{}:
+	default:
+	}
+}
+
+// Stop turns off the ticker. Like time.Ticker.Stop, it does not close C.
+func (t *BackoffTicker) Stop() {
+	t.once.Do(func() { close(t.stop) })
+}
+
+func (t *BackoffTicker) run(config RetryConfig) {
+	clock := clockOrReal(config.Clock)
+	attempt := 0
+	for {
+		select {
+		case <-t.stop:
+			return
+		case <-t.reset:
+			attempt = 0
+		case now := <-clock.After(backoffDelay(attempt, config)):
+			select {
+			case t.c <- now:
+			default:
+			}
+			attempt++
+		}
+	}
+}
+
+// backoffDelay is calculateDelay with a random ±25% jitter.
+func backoffDelay(attempt int, config RetryConfig) time.Duration {
+	jitter := config.Jitter
+	config.Jitter = false
+	delay := calculateDelay(attempt, config)
+	if jitter {
+		delay = time.Duration(float64(delay) * (0.75 + 0.5*rand.Float64()))
+	}
+	return delay
+}
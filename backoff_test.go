@@ -0,0 +1,57 @@
+package concurrent
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoffTicker(t *testing.T) {
+	receive := func(t *testing.T, ticker *BackoffTicker) {
+		t.Helper()
+		select {
+		case <-ticker.C():
+		case <-time.After(time.Second):
+			t.Fatal("Expected a tick")
+		}
+	}
+
+	t.Run("intervals grow and reset", func(t *testing.T) {
+		clock := NewFakeClock(time.Now())
+		ticker := NewBackoffTicker(RetryConfig{
+			BaseDelay:  time.Second,
+			MaxDelay:   4 * time.Second,
+			Multiplier: 2,
+			Clock:      clock,
+		})
+		defer ticker.Stop()
+
+		for _, d := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second} {
+			clock.BlockUntil(1)
+			clock.Advance(d - time.Millisecond)
+			select {
+			case <-ticker.C():
+				t.Fatalf("Expected no tick before %v", d)
+			default:
+			}
+			clock.Advance(time.Millisecond)
+			receive(t, ticker)
+		}
+
+		clock.BlockUntil(1)
+		ticker.Reset()
+		// The abandoned 4s wait stays registered with the fake clock.
+		clock.BlockUntil(2)
+		clock.Advance(time.Second)
+		receive(t, ticker)
+	})
+
+	t.Run("jitter", func(t *testing.T) {
+		config := RetryConfig{BaseDelay: time.Second, MaxDelay: time.Minute, Multiplier: 2, Jitter: true}
+		for i := 0; i < 100; i++ {
+			d := backoffDelay(1, config)
+			if d < 1500*time.Millisecond || d > 2500*time.Millisecond {
+				t.Fatalf("Expected 2s ±25%%, got %v", d)
+			}
+		}
+	})
+}
@@ -0,0 +1,181 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrBatcherClosed is returned by Batcher.Add after the batcher has been closed.
+var ErrBatcherClosed = errors.New("concurrent: batcher is closed")
+
+// BatcherConfig holds the flush triggers for a Batcher.
+// A zero value disables the corresponding trigger.
+type BatcherConfig[T any] struct {
+	// MaxItems flushes once the batch holds this many items.
+	MaxItems int
+	// MaxAge flushes once the oldest item in the batch is this old.
+	MaxAge time.Duration
+	// MaxCost flushes before the summed Cost of the batch would exceed this value.
+	MaxCost int64
+	// Cost returns the cost of a single item, e.g. its size in bytes.
+	Cost func(T) int64
+	// OnError receives errors from flushes triggered by MaxAge,
+	// which have no caller to return them to.
+	OnError func(error)
+	// Name and Observer identify the batcher and receive an EventBatched
+	// for every non-empty flush.
+	Name     string
+	Observer Observer
+	// Clock times MaxAge. It defaults to RealClock.
+	Clock Clock
+}
+
+// Batcher accumulates items and hands them to a flush callback in batches.
+// Unlike the Batch stage, it is driven by direct Add calls, which makes it a
+// good fit for write-behind caches and bulk API clients.
+// Batches are flushed one at a time, in the order they were filled.
+type Batcher[T any] struct {
+	config  BatcherConfig[T]
+	flushFn func(context.Context, []T) error
+
+	mu        sync.Mutex
+	batch     []T
+	cost      int64
+	stopTimer chan struct{} // closed to cancel the pending MaxAge flush
+	gen       uint64
+	closed    bool
+	lastFlush chan struct{} // closed when the most recently started flush finishes
+}
+
+// NewBatcher creates a batcher that passes full batches to flush.
+func NewBatcher[T any](config BatcherConfig[T], flush func(context.Context, []T) error) *Batcher[T] {
+	return &Batcher[T]{config: config, flushFn: flush}
+}
+
+// Add appends an item to the current batch, flushing synchronously if a size
+// or cost trigger fires. It returns the error from any flush it performed.
+// It returns ErrBatcherClosed, without adding the item, if the batcher is
+// closed before the item joins a batch.
+func (b *Batcher[T]) Add(ctx context.Context, item T) error {
+	var c int64
+	if b.config.Cost != nil {
+		c = b.config.Cost(item)
+	}
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return ErrBatcherClosed
+	}
+
+	var firstErr error
+	// Flush first if this item would push the batch over its cost budget.
+	if b.config.MaxCost > 0 && len(b.batch) > 0 && b.cost+c > b.config.MaxCost {
+		if err := b.flushLocked(ctx); err != nil {
+			firstErr = err
+		}
+		b.mu.Lock()
+		// Close may have run its final flush while the lock was released.
+		if b.closed {
+			b.mu.Unlock()
+			return ErrBatcherClosed
+		}
+	}
+
+	b.batch = append(b.batch, item)
+	b.cost += c
+	if len(b.batch) == 1 && b.config.MaxAge > 0 {
+		b.startTimer()
+	}
+
+	full := b.config.MaxItems > 0 && len(b.batch) >= b.config.MaxItems
+	expensive := b.config.MaxCost > 0 && b.cost >= b.config.MaxCost
+	if full || expensive {
+		if err := b.flushLocked(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		return firstErr
+	}
+	b.mu.Unlock()
+	return firstErr
+}
+
+// Flush flushes the current batch, if any.
+func (b *Batcher[T]) Flush(ctx context.Context) error {
+	b.mu.Lock()
+	return b.flushLocked(ctx)
+}
+
+// Close flushes any remaining items and rejects further Adds.
+func (b *Batcher[T]) Close(ctx context.Context) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	return b.flushLocked(ctx)
+}
+
+// Len returns the number of items waiting in the current batch.
+func (b *Batcher[T]) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.batch)
+}
+
+func (b *Batcher[T]) startTimer() {
+	gen := b.gen
+	stop := make(chan struct{})
+	b.stopTimer = stop
+	expired := clockOrReal(b.config.Clock).After(b.config.MaxAge)
+	go func() {
+		select {
+		case <-stop:
+			return
+		case <-expired:
+		}
+		b.mu.Lock()
+		// The batch may already have been flushed by a size or cost trigger.
+		if b.gen != gen {
+			b.mu.Unlock()
+			return
+		}
+		if err := b.flushLocked(context.Background()); err != nil && b.config.OnError != nil {
+			b.config.OnError(err)
+		}
+	}()
+}
+
+// flushLocked must be called with b.mu held; it releases b.mu before
+// invoking the flush callback. Flushes wait for their predecessor on a
+// channel rather than a mutex, so a slow flush never leaves other callers
+// blocked on a lock, which also keeps the batcher usable inside
+// testing/synctest bubbles.
+func (b *Batcher[T]) flushLocked(ctx context.Context) error {
+	if b.stopTimer != nil {
+		close(b.stopTimer)
+		b.stopTimer = nil
+	}
+	batch := b.batch
+	b.batch = nil
+	b.cost = 0
+	b.gen++
+
+	prev := b.lastFlush
+	done := make(chan struct{})
+	b.lastFlush = done
+	b.mu.Unlock()
+	defer close(done)
+
+	if prev != nil {
+		<-prev
+	}
+	if len(batch) == 0 {
+		return nil
+	}
+	observer{name: b.config.Name, o: b.config.Observer}.emit(Event{Kind: EventBatched, Depth: len(batch)})
+	return b.flushFn(ctx, batch)
+}
@@ -0,0 +1,219 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type batchRecorder[T any] struct {
+	mu      sync.Mutex
+	batches [][]T
+}
+
+func (r *batchRecorder[T]) flush(_ context.Context, batch []T) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = append(r.batches, batch)
+	return nil
+}
+
+func (r *batchRecorder[T]) snapshot() [][]T {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([][]T(nil), r.batches...)
+}
+
+func TestBatcher(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("size trigger", func(t *testing.T) {
+		rec := &batchRecorder[int]{}
+		b := NewBatcher(BatcherConfig[int]{MaxItems: 3}, rec.flush)
+
+		for i := 0; i < 7; i++ {
+			if err := b.Add(ctx, i); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+		if got := len(rec.snapshot()); got != 2 {
+			t.Errorf("Expected 2 batches before close, got %d", got)
+		}
+		if b.Len() != 1 {
+			t.Errorf("Expected 1 pending item, got %d", b.Len())
+		}
+
+		if err := b.Close(ctx); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		batches := rec.snapshot()
+		if len(batches) != 3 || len(batches[2]) != 1 {
+			t.Errorf("Expected final batch of 1 item, got %v", batches)
+		}
+		if err := b.Add(ctx, 99); !errors.Is(err, ErrBatcherClosed) {
+			t.Errorf("Expected ErrBatcherClosed, got %v", err)
+		}
+	})
+
+	t.Run("age trigger", func(t *testing.T) {
+		rec := &batchRecorder[int]{}
+		b := NewBatcher(BatcherConfig[int]{MaxItems: 100, MaxAge: 20 * time.Millisecond}, rec.flush)
+
+		_ = b.Add(ctx, 1)
+		_ = b.Add(ctx, 2)
+
+		deadline := time.Now().Add(time.Second)
+		for len(rec.snapshot()) == 0 && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+		}
+		batches := rec.snapshot()
+		if len(batches) != 1 || len(batches[0]) != 2 {
+			t.Errorf("Expected one batch of 2 items, got %v", batches)
+		}
+	})
+
+	t.Run("age trigger on a fake clock", func(t *testing.T) {
+		clock := NewFakeClock(time.Now())
+		flushed := make(chan []int, 1)
+		b := NewBatcher(BatcherConfig[int]{MaxAge: time.Minute, Clock: clock}, func(_ context.Context, batch []int) error {
+			flushed <- batch
+			return nil
+		})
+
+		_ = b.Add(ctx, 1)
+		clock.Advance(59 * time.Second)
+		if b.Len() != 1 {
+			t.Fatalf("Expected the batch to wait for MaxAge, got %d items", b.Len())
+		}
+		clock.Advance(time.Second)
+		if batch := <-flushed; len(batch) != 1 {
+			t.Errorf("Expected one item, got %v", batch)
+		}
+	})
+
+	t.Run("cost trigger", func(t *testing.T) {
+		rec := &batchRecorder[string]{}
+		b := NewBatcher(BatcherConfig[string]{
+			MaxCost: 10,
+			Cost:    func(s string) int64 { return int64(len(s)) },
+		}, rec.flush)
+
+		for _, s := range []string{"aaaa", "bbbb", "cccc", "dddddddddd"} {
+			_ = b.Add(ctx, s)
+		}
+
+		batches := rec.snapshot()
+		// "cccc" would exceed the budget, so [aaaa bbbb] is flushed first;
+		// "dddddddddd" then does the same to [cccc] and fills a batch on its own.
+		if len(batches) != 3 {
+			t.Fatalf("Expected 3 batches, got %v", batches)
+		}
+		for _, batch := range batches {
+			var total int
+			for _, s := range batch {
+				total += len(s)
+			}
+			if total > 10 {
+				t.Errorf("Batch %v exceeds cost budget", batch)
+			}
+		}
+	})
+
+	t.Run("close during cost flush", func(t *testing.T) {
+		rec := &batchRecorder[string]{}
+		entered := make(chan struct{}, 1)
+		release := make(chan struct{})
+		b := NewBatcher(BatcherConfig[string]{
+			MaxCost: 10,
+			Cost:    func(s string) int64 { return int64(len(s)) },
+		}, func(ctx context.Context, batch []string) error {
+			if len(rec.snapshot()) == 0 {
+				entered <- struct{}{}
+				<-release
+			}
+			return rec.flush(ctx, batch)
+		})
+		_ = b.Add(ctx, "aaaa")
+		_ = b.Add(ctx, "bbbb")
+
+		errc := make(chan error, 1)
+		go func() { errc <- b.Add(ctx, "cccc") }() // flushes [aaaa bbbb] first
+		<-entered
+		closed := make(chan error, 1)
+		go func() { closed <- b.Close(ctx) }()
+		for {
+			b.mu.Lock()
+			done := b.closed
+			b.mu.Unlock()
+			if done {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		close(release)
+
+		if err := <-errc; !errors.Is(err, ErrBatcherClosed) {
+			t.Errorf("Expected ErrBatcherClosed, got %v", err)
+		}
+		if err := <-closed; err != nil {
+			t.Errorf("Unexpected close error: %v", err)
+		}
+		if batches := rec.snapshot(); len(batches) != 1 || len(batches[0]) != 2 {
+			t.Errorf("Expected only [aaaa bbbb] to be flushed, got %v", batches)
+		}
+	})
+
+	t.Run("concurrent add and close", func(t *testing.T) {
+		rec := &batchRecorder[int]{}
+		b := NewBatcher(BatcherConfig[int]{
+			MaxCost: 3,
+			Cost:    func(int) int64 { return 1 },
+		}, rec.flush)
+		var mu sync.Mutex
+		accepted := make(map[int]bool)
+		var wg sync.WaitGroup
+		for g := range 8 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range 100 {
+					item := g*100 + i
+					if err := b.Add(ctx, item); !errors.Is(err, ErrBatcherClosed) {
+						mu.Lock()
+						accepted[item] = true
+						mu.Unlock()
+					}
+				}
+			}()
+		}
+		time.Sleep(time.Millisecond)
+		if err := b.Close(ctx); err != nil {
+			t.Fatalf("Unexpected close error: %v", err)
+		}
+		wg.Wait()
+
+		flushed := make(map[int]bool)
+		for _, batch := range rec.snapshot() {
+			for _, item := range batch {
+				flushed[item] = true
+			}
+		}
+		for item := range accepted {
+			if !flushed[item] {
+				t.Errorf("Item %d was accepted but never flushed", item)
+			}
+		}
+	})
+
+	t.Run("flush error", func(t *testing.T) {
+		errFlush := errors.New("flush failed")
+		b := NewBatcher(BatcherConfig[int]{MaxItems: 1}, func(context.Context, []int) error {
+			return errFlush
+		})
+		if err := b.Add(ctx, 1); !errors.Is(err, errFlush) {
+			t.Errorf("Expected flush error, got %v", err)
+		}
+	})
+}
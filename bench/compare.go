@@ -0,0 +1,94 @@
+package bench
+
+import "fmt"
+
+// Delta is the change in one benchmark between two sets of results.
+type Delta struct {
+	Key    string  `json:"key"`
+	Old    Stats   `json:"old"`
+	New    Stats   `json:"new"`
+	Change float64 `json:"change_percent"` // positive means slower
+}
+
+// Comparison is the outcome of comparing two benchmark runs.
+type Comparison struct {
+	Tolerance float64 `json:"tolerance_percent"`
+	Deltas    []Delta `json:"deltas"`
+	Added     []Stats `json:"added,omitempty"`
+	Removed   []Stats `json:"removed,omitempty"`
+}
+
+// Compare compares mean ns/op of every benchmark present in both old and new.
+// Changes within tolerance percent are treated as noise by Regressions and
+// Improvements.
+func Compare(old, new []Result, tolerance float64) Comparison {
+	cmp := Comparison{Tolerance: tolerance}
+	oldStats := make(map[string]Stats)
+	for _, s := range Summarize(old) {
+		oldStats[s.Key()] = s
+	}
+	seen := make(map[string]bool)
+	for _, s := range Summarize(new) {
+		key := s.Key()
+		prev, ok := oldStats[key]
+		if !ok {
+			cmp.Added = append(cmp.Added, s)
+			continue
+		}
+		seen[key] = true
+		var change float64
+		if prev.MeanNsPerOp > 0 {
+			change = (s.MeanNsPerOp - prev.MeanNsPerOp) / prev.MeanNsPerOp * 100
+		}
+		cmp.Deltas = append(cmp.Deltas, Delta{Key: key, Old: prev, New: s, Change: change})
+	}
+	for _, s := range Summarize(old) {
+		if !seen[s.Key()] {
+			cmp.Removed = append(cmp.Removed, s)
+		}
+	}
+	return cmp
+}
+
+// Regressions returns the deltas that got slower by more than the tolerance.
+func (c Comparison) Regressions() []Delta {
+	var out []Delta
+	for _, d := range c.Deltas {
+		if d.Change > c.Tolerance {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// Improvements returns the deltas that got faster by more than the tolerance.
+func (c Comparison) Improvements() []Delta {
+	var out []Delta
+	for _, d := range c.Deltas {
+		if d.Change < -c.Tolerance {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// Violation is a benchmark that exceeded a threshold.
+type Violation struct {
+	Stats
+	Limit float64 `json:"limit"`
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %.0f ns/op exceeds %.0f ns/op", v.Key(), v.MeanNsPerOp, v.Limit)
+}
+
+// CheckThreshold returns the benchmarks whose mean ns/op exceeds maxNsPerOp.
+func CheckThreshold(stats []Stats, maxNsPerOp float64) []Violation {
+	var out []Violation
+	for _, s := range stats {
+		if s.MeanNsPerOp > maxNsPerOp {
+			out = append(out, Violation{Stats: s, Limit: maxNsPerOp})
+		}
+	}
+	return out
+}
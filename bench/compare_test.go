@@ -0,0 +1,47 @@
+package bench
+
+import "testing"
+
+func TestCompare(t *testing.T) {
+	old := []Result{
+		{Name: "Fast", Procs: 8, NsPerOp: 100},
+		{Name: "Slow", Procs: 8, NsPerOp: 100},
+		{Name: "Stable", Procs: 8, NsPerOp: 100},
+		{Name: "Gone", Procs: 8, NsPerOp: 100},
+	}
+	cur := []Result{
+		{Name: "Fast", Procs: 8, NsPerOp: 80},
+		{Name: "Slow", Procs: 8, NsPerOp: 130},
+		{Name: "Stable", Procs: 8, NsPerOp: 103},
+		{Name: "New", Procs: 8, NsPerOp: 50},
+	}
+
+	cmp := Compare(old, cur, 5)
+
+	if r := cmp.Regressions(); len(r) != 1 || r[0].Key != "Slow-8" || r[0].Change != 30 {
+		t.Errorf("Expected Slow-8 to regress by 30%%, got %+v", r)
+	}
+	if i := cmp.Improvements(); len(i) != 1 || i[0].Key != "Fast-8" {
+		t.Errorf("Expected Fast-8 to improve, got %+v", i)
+	}
+	if len(cmp.Added) != 1 || cmp.Added[0].Name != "New" {
+		t.Errorf("Expected New to be added, got %+v", cmp.Added)
+	}
+	if len(cmp.Removed) != 1 || cmp.Removed[0].Name != "Gone" {
+		t.Errorf("Expected Gone to be removed, got %+v", cmp.Removed)
+	}
+}
+
+func TestCheckThreshold(t *testing.T) {
+	stats := Summarize([]Result{
+		{Name: "A", Procs: 1, NsPerOp: 500},
+		{Name: "B", Procs: 1, NsPerOp: 5000},
+	})
+	violations := CheckThreshold(stats, 1000)
+	if len(violations) != 1 || violations[0].Name != "B" {
+		t.Fatalf("Expected B to violate the threshold, got %+v", violations)
+	}
+	if got := violations[0].String(); got != "B-1: 5000 ns/op exceeds 1000 ns/op" {
+		t.Errorf("Unexpected violation message: %q", got)
+	}
+}
@@ -0,0 +1,184 @@
+// Package bench parses `go test -bench` output and detects performance
+// regressions, so benchmark checks can run from Go code and CI scripts
+// instead of ad-hoc text processing.
+//
+//	old, _ := bench.ParseFile("old.txt")
+//	cur, _ := bench.ParseFile("new.txt")
+//	cmp := bench.Compare(old, cur, 5)
+//	if regressions := cmp.Regressions(); len(regressions) > 0 {
+//		log.Fatalf("%d benchmarks regressed", len(regressions))
+//	}
+package bench
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Result is a single benchmark line from `go test -bench` output.
+type Result struct {
+	Name        string             `json:"name"`
+	Pkg         string             `json:"pkg,omitempty"`
+	Procs       int                `json:"procs"`
+	Iterations  int64              `json:"iterations"`
+	NsPerOp     float64            `json:"ns_per_op"`
+	BytesPerOp  int64              `json:"bytes_per_op"`
+	AllocsPerOp int64              `json:"allocs_per_op"`
+	MBPerSec    float64            `json:"mb_per_sec,omitempty"`
+	Extra       map[string]float64 `json:"extra,omitempty"` // custom metrics from b.ReportMetric, keyed by unit
+}
+
+// Parse reads `go test -bench` output and returns every benchmark result in
+// order. Non-benchmark lines are ignored; the most recent "pkg:" header is
+// attached to each result.
+func Parse(r io.Reader) ([]Result, error) {
+	var results []Result
+	var pkg string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		if p, ok := strings.CutPrefix(line, "pkg: "); ok {
+			pkg = strings.TrimSpace(p)
+			continue
+		}
+		if !strings.HasPrefix(line, "Benchmark") {
+			continue
+		}
+		result, ok, err := parseLine(line)
+		if err != nil {
+			return results, fmt.Errorf("bench: line %d: %w", lineNo, err)
+		}
+		if ok {
+			result.Pkg = pkg
+			results = append(results, result)
+		}
+	}
+	return results, scanner.Err()
+}
+
+// ParseFile parses the `go test -bench` output stored in path.
+func ParseFile(path string) ([]Result, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// parseLine parses one benchmark line. It reports false for lines that start
+// with "Benchmark" but are not results, such as a benchmark's log output.
+func parseLine(line string) (Result, bool, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 || len(fields)%2 != 0 {
+		return Result{}, false, nil
+	}
+	iterations, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return Result{}, false, nil
+	}
+
+	result := Result{Name: strings.TrimPrefix(fields[0], "Benchmark"), Procs: 1, Iterations: iterations}
+	if i := strings.LastIndexByte(result.Name, '-'); i >= 0 {
+		if procs, err := strconv.Atoi(result.Name[i+1:]); err == nil {
+			result.Name, result.Procs = result.Name[:i], procs
+		}
+	}
+
+	for i := 2; i < len(fields); i += 2 {
+		value, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return Result{}, false, fmt.Errorf("invalid value %q for %s", fields[i], fields[i+1])
+		}
+		switch unit := fields[i+1]; unit {
+		case "ns/op":
+			result.NsPerOp = value
+		case "B/op":
+			result.BytesPerOp = int64(value)
+		case "allocs/op":
+			result.AllocsPerOp = int64(value)
+		case "MB/s":
+			result.MBPerSec = value
+		default:
+			if result.Extra == nil {
+				result.Extra = make(map[string]float64)
+			}
+			result.Extra[unit] = value
+		}
+	}
+	return result, true, nil
+}
+
+// Stats aggregates the runs of one benchmark, e.g. from -count=N.
+type Stats struct {
+	Name        string  `json:"name"`
+	Procs       int     `json:"procs"`
+	Runs        int     `json:"runs"`
+	MeanNsPerOp float64 `json:"mean_ns_per_op"`
+	MinNsPerOp  float64 `json:"min_ns_per_op"`
+	MaxNsPerOp  float64 `json:"max_ns_per_op"`
+	StdDevNs    float64 `json:"std_dev_ns"`
+	BytesPerOp  int64   `json:"bytes_per_op"`
+	AllocsPerOp int64   `json:"allocs_per_op"`
+}
+
+// Key identifies the benchmark the stats belong to, including GOMAXPROCS.
+func (s Stats) Key() string {
+	return fmt.Sprintf("%s-%d", s.Name, s.Procs)
+}
+
+// Summarize groups results by benchmark name and GOMAXPROCS and computes
+// per-benchmark statistics, sorted by key. Memory figures are the maximum
+// seen across runs.
+func Summarize(results []Result) []Stats {
+	groups := make(map[string][]Result)
+	var keys []string
+	for _, r := range results {
+		key := fmt.Sprintf("%s-%d", r.Name, r.Procs)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], r)
+	}
+	sort.Strings(keys)
+
+	stats := make([]Stats, 0, len(keys))
+	for _, key := range keys {
+		runs := groups[key]
+		s := Stats{
+			Name:       runs[0].Name,
+			Procs:      runs[0].Procs,
+			Runs:       len(runs),
+			MinNsPerOp: math.Inf(1),
+			MaxNsPerOp: math.Inf(-1),
+		}
+		var sum float64
+		for _, r := range runs {
+			sum += r.NsPerOp
+			s.MinNsPerOp = min(s.MinNsPerOp, r.NsPerOp)
+			s.MaxNsPerOp = max(s.MaxNsPerOp, r.NsPerOp)
+			s.BytesPerOp = max(s.BytesPerOp, r.BytesPerOp)
+			s.AllocsPerOp = max(s.AllocsPerOp, r.AllocsPerOp)
+		}
+		s.MeanNsPerOp = sum / float64(len(runs))
+		if len(runs) > 1 {
+			var sq float64
+			for _, r := range runs {
+				d := r.NsPerOp - s.MeanNsPerOp
+				sq += d * d
+			}
+			s.StdDevNs = math.Sqrt(sq / float64(len(runs)-1))
+		}
+		stats = append(stats, s)
+	}
+	return stats
+}
@@ -0,0 +1,67 @@
+package bench
+
+import (
+	"strings"
+	"testing"
+)
+
+const sampleOutput = `goos: linux
+goarch: amd64
+pkg: github.com/logimos/concurrent
+cpu: Intel(R) Xeon(R)
+BenchmarkPool-8          	  100000	     12000 ns/op	     512 B/op	       4 allocs/op
+BenchmarkPool-8          	  100000	     14000 ns/op	     512 B/op	       4 allocs/op
+BenchmarkPipeline/small-8	 2000000	       600 ns/op	  25.50 MB/s	      3.5 items/op
+BenchmarkPipeline logs something
+PASS
+ok  	github.com/logimos/concurrent	3.1s
+`
+
+func TestParse(t *testing.T) {
+	results, err := Parse(strings.NewReader(sampleOutput))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+
+	r := results[0]
+	if r.Name != "Pool" || r.Procs != 8 || r.Iterations != 100000 || r.NsPerOp != 12000 || r.BytesPerOp != 512 || r.AllocsPerOp != 4 {
+		t.Errorf("Unexpected result: %+v", r)
+	}
+	if r.Pkg != "github.com/logimos/concurrent" {
+		t.Errorf("Expected package to be recorded, got %q", r.Pkg)
+	}
+
+	sub := results[2]
+	if sub.Name != "Pipeline/small" || sub.MBPerSec != 25.5 || sub.Extra["items/op"] != 3.5 {
+		t.Errorf("Expected sub-benchmark with custom metric, got %+v", sub)
+	}
+
+	t.Run("invalid value", func(t *testing.T) {
+		_, err := Parse(strings.NewReader("BenchmarkX-4 10 abc ns/op\n"))
+		if err == nil {
+			t.Error("Expected error for malformed value")
+		}
+	})
+}
+
+func TestSummarize(t *testing.T) {
+	results, _ := Parse(strings.NewReader(sampleOutput))
+	stats := Summarize(results)
+	if len(stats) != 2 {
+		t.Fatalf("Expected 2 benchmarks, got %d", len(stats))
+	}
+
+	pool := stats[1]
+	if pool.Key() != "Pool-8" || pool.Runs != 2 {
+		t.Fatalf("Expected Pool-8 with 2 runs, got %+v", pool)
+	}
+	if pool.MeanNsPerOp != 13000 || pool.MinNsPerOp != 12000 || pool.MaxNsPerOp != 14000 {
+		t.Errorf("Unexpected ns/op statistics: %+v", pool)
+	}
+	if pool.StdDevNs < 1414 || pool.StdDevNs > 1415 {
+		t.Errorf("Expected sample standard deviation of ~1414, got %f", pool.StdDevNs)
+	}
+}
@@ -0,0 +1,209 @@
+package concurrent
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// Cache is a concurrency-safe LRU cache with optional per-entry TTL.
+// GetOrLoad deduplicates concurrent loads of the same key, so a burst of
+// misses triggers exactly one call to the loader.
+type Cache[K comparable, V any] struct {
+	mu         sync.Mutex
+	maxEntries int
+	ttl        time.Duration
+	ll         *list.List
+	items      map[K]*list.Element
+	loads      map[K]*cacheLoad[V]
+}
+
+type cacheEntry[K comparable, V any] struct {
+	key     K
+	value   V
+	expires time.Time
+}
+
+type cacheLoad[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+// NewCache creates a cache holding at most maxEntries entries, each expiring
+// ttl after it was stored. A maxEntries or ttl of zero disables that limit.
+func NewCache[K comparable, V any](maxEntries int, ttl time.Duration) *Cache[K, V] {
+	return &Cache[K, V]{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		ll:         list.New(),
+		items:      make(map[K]*list.Element),
+		loads:      make(map[K]*cacheLoad[V]),
+	}
+}
+
+// Get returns the cached value for key, if present and not expired.
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.getLocked(key)
+}
+
+func (c *Cache[K, V]) getLocked(key K) (V, bool) {
+	var zero V
+	el, ok := c.items[key]
+	if !ok {
+		return zero, false
+	}
+	e := el.Value.(*cacheEntry[K, V])
+	if !e.expires.IsZero() && time.Now().After(e.expires) {
+		c.removeElement(el)
+		return zero, false
+	}
+	c.ll.MoveToFront(el)
+	return e.value, true
+}
+
+// Set stores value under key, evicting the least recently used entry if the
+// cache is full.
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setLocked(key, value)
+}
+
+func (c *Cache[K, V]) setLocked(key K, value V) {
+	var expires time.Time
+	if c.ttl > 0 {
+		expires = time.Now().Add(c.ttl)
+	}
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*cacheEntry[K, V])
+		e.value = value
+		e.expires = expires
+		c.ll.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.ll.PushFront(&cacheEntry[K, V]{key: key, value: value, expires: expires})
+	if c.maxEntries > 0 && c.ll.Len() > c.maxEntries {
+		c.removeElement(c.ll.Back())
+	}
+}
+
+// Delete removes key from the cache.
+func (c *Cache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.removeElement(el)
+	}
+}
+
+// Len returns the number of entries in the cache, including expired entries
+// that have not been evicted yet.
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+func (c *Cache[K, V]) removeElement(el *list.Element) {
+	c.ll.Remove(el)
+	delete(c.items, el.Value.(*cacheEntry[K, V]).key)
+}
+
+// GetOrLoad returns the cached value for key, calling loader on a miss.
+// Concurrent callers missing on the same key share a single loader call,
+// which runs with the context of the caller that started it.
+// Errors are returned to every waiting caller and are not cached.
+// If loader panics, the panic is propagated to the caller that started it
+// and the other callers receive a *PanicError.
+func (c *Cache[K, V]) GetOrLoad(ctx context.Context, key K, loader func(context.Context, K) (V, error)) (V, error) {
+	c.mu.Lock()
+	if v, ok := c.getLocked(key); ok {
+		c.mu.Unlock()
+		return v, nil
+	}
+	if l, ok := c.loads[key]; ok {
+		c.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			var zero V
+			return zero, ctx.Err()
+		case <-l.done:
+			return l.value, l.err
+		}
+	}
+	l := &cacheLoad[V]{done: make(chan struct{})}
+	c.loads[key] = l
+	c.mu.Unlock()
+
+	c.load(ctx, key, l, loader)
+	return l.value, l.err
+}
+
+// errLoaderExited is returned to callers waiting on a loader that called
+// runtime.Goexit.
+var errLoaderExited = errors.New("concurrent: cache loader exited")
+
+// load runs loader for l and then releases its waiters. If loader panics,
+// the waiters get a *PanicError and the panic continues in the caller.
+func (c *Cache[K, V]) load(ctx context.Context, key K, l *cacheLoad[V], loader func(context.Context, K) (V, error)) {
+	returned := false
+	defer func() {
+		if !returned {
+			if v := recover(); v != nil {
+				l.err = &PanicError{Value: v, Stack: debug.Stack()}
+				defer panic(v)
+			} else {
+				l.err = errLoaderExited
+			}
+		}
+		c.mu.Lock()
+		delete(c.loads, key)
+		if l.err == nil {
+			c.setLocked(key, l.value)
+		}
+		c.mu.Unlock()
+		close(l.done)
+	}()
+	l.value, l.err = loader(ctx, key)
+	returned = true
+}
+
+// Memoize wraps fn with a cache holding up to maxEntries successful results
+// for ttl each. Concurrent calls for the same key share a single call to fn,
+// as with Cache.GetOrLoad. Errors are not cached unless WithNegativeTTL is
+// given.
+func Memoize[K comparable, V any](fn func(context.Context, K) (V, error), ttl time.Duration, maxEntries int, opts ...MemoizeOption) func(context.Context, K) (V, error) {
+	var options MemoizeOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	values := NewCache[K, V](maxEntries, ttl)
+	if options.NegativeTTL <= 0 {
+		return func(ctx context.Context, key K) (V, error) {
+			return values.GetOrLoad(ctx, key, fn)
+		}
+	}
+
+	failures := NewCache[K, error](maxEntries, options.NegativeTTL)
+	load := func(ctx context.Context, key K) (V, error) {
+		v, err := fn(ctx, key)
+		if err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+			failures.Set(key, err)
+		}
+		return v, err
+	}
+	return func(ctx context.Context, key K) (V, error) {
+		if err, ok := failures.Get(key); ok {
+			var zero V
+			return zero, err
+		}
+		return values.GetOrLoad(ctx, key, load)
+	}
+}
@@ -0,0 +1,238 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	t.Run("lru eviction", func(t *testing.T) {
+		c := NewCache[string, int](2, 0)
+		c.Set("a", 1)
+		c.Set("b", 2)
+		c.Get("a") // a is now most recently used
+		c.Set("c", 3)
+
+		if _, ok := c.Get("b"); ok {
+			t.Error("Expected b to be evicted")
+		}
+		if v, ok := c.Get("a"); !ok || v != 1 {
+			t.Errorf("Expected a=1, got %d, %v", v, ok)
+		}
+		if c.Len() != 2 {
+			t.Errorf("Expected 2 entries, got %d", c.Len())
+		}
+	})
+
+	t.Run("ttl expiry", func(t *testing.T) {
+		c := NewCache[string, int](0, 20*time.Millisecond)
+		c.Set("a", 1)
+		if _, ok := c.Get("a"); !ok {
+			t.Fatal("Expected a to be present")
+		}
+		time.Sleep(30 * time.Millisecond)
+		if _, ok := c.Get("a"); ok {
+			t.Error("Expected a to have expired")
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		c := NewCache[string, int](0, 0)
+		c.Set("a", 1)
+		c.Delete("a")
+		if _, ok := c.Get("a"); ok {
+			t.Error("Expected a to be deleted")
+		}
+	})
+
+	t.Run("singleflight load", func(t *testing.T) {
+		c := NewCache[string, int](10, 0)
+		var calls atomic.Int32
+		loader := func(context.Context, string) (int, error) {
+			calls.Add(1)
+			time.Sleep(20 * time.Millisecond)
+			return 42, nil
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				v, err := c.GetOrLoad(context.Background(), "k", loader)
+				if err != nil || v != 42 {
+					t.Errorf("Expected 42, got %d, %v", v, err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if calls.Load() != 1 {
+			t.Errorf("Expected loader to be called once, got %d", calls.Load())
+		}
+		if v, ok := c.Get("k"); !ok || v != 42 {
+			t.Errorf("Expected loaded value to be cached")
+		}
+	})
+
+	t.Run("load errors are not cached", func(t *testing.T) {
+		c := NewCache[string, int](10, 0)
+		_, err := c.GetOrLoad(context.Background(), "k", func(context.Context, string) (int, error) {
+			return 0, errors.New("load failed")
+		})
+		if err == nil {
+			t.Fatal("Expected error")
+		}
+		v, err := c.GetOrLoad(context.Background(), "k", func(context.Context, string) (int, error) {
+			return 7, nil
+		})
+		if err != nil || v != 7 {
+			t.Errorf("Expected 7, got %d, %v", v, err)
+		}
+	})
+
+	t.Run("waiter context cancellation", func(t *testing.T) {
+		c := NewCache[string, int](10, 0)
+		release := make(chan struct{})
+		started := make(chan struct{})
+		go func() {
+			_, _ = c.GetOrLoad(context.Background(), "k", func(context.Context, string) (int, error) {
+				close(started)
+				<-release
+				return 1, nil
+			})
+		}()
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := c.GetOrLoad(ctx, "k", func(context.Context, string) (int, error) { return 2, nil })
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected deadline exceeded, got %v", err)
+		}
+		close(release)
+	})
+
+	t.Run("loader panic", func(t *testing.T) {
+		c := NewCache[string, int](10, 0)
+		release := make(chan struct{})
+		started := make(chan struct{})
+		recovered := make(chan any, 1)
+		go func() {
+			defer func() { recovered <- recover() }()
+			_, _ = c.GetOrLoad(context.Background(), "k", func(context.Context, string) (int, error) {
+				close(started)
+				<-release
+				panic("boom")
+			})
+		}()
+		<-started
+
+		errc := make(chan error, 1)
+		go func() {
+			_, err := c.GetOrLoad(context.Background(), "k", func(context.Context, string) (int, error) { return 2, nil })
+			errc <- err
+		}()
+		// Let the second caller join the load before it panics.
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+
+		if v := <-recovered; v != "boom" {
+			t.Errorf("Expected the loader's panic in its caller, got %v", v)
+		}
+		var pe *PanicError
+		if err := <-errc; !errors.As(err, &pe) || pe.Value != "boom" {
+			t.Errorf("Expected a PanicError for the waiter, got %v", err)
+		}
+		v, err := c.GetOrLoad(context.Background(), "k", func(context.Context, string) (int, error) { return 3, nil })
+		if err != nil || v != 3 {
+			t.Errorf("Expected a fresh load after the panic, got %d, %v", v, err)
+		}
+	})
+}
+
+func TestMemoize(t *testing.T) {
+	t.Run("caches and deduplicates", func(t *testing.T) {
+		var calls atomic.Int32
+		release := make(chan struct{})
+		square := Memoize(func(_ context.Context, n int) (int, error) {
+			calls.Add(1)
+			<-release
+			return n * n, nil
+		}, time.Minute, 10)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if v, err := square(context.Background(), 3); err != nil || v != 9 {
+					t.Errorf("Expected 9, got %d, %v", v, err)
+				}
+			}()
+		}
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		square(context.Background(), 3)
+		if n := calls.Load(); n != 1 {
+			t.Errorf("Expected 1 call, got %d", n)
+		}
+	})
+
+	t.Run("errors not cached by default", func(t *testing.T) {
+		var calls atomic.Int32
+		fail := Memoize(func(context.Context, string) (int, error) {
+			calls.Add(1)
+			return 0, errors.New("boom")
+		}, time.Minute, 10)
+		fail(context.Background(), "a")
+		fail(context.Background(), "a")
+		if n := calls.Load(); n != 2 {
+			t.Errorf("Expected 2 calls, got %d", n)
+		}
+	})
+
+	t.Run("negative ttl", func(t *testing.T) {
+		var calls atomic.Int32
+		boom := errors.New("boom")
+		fail := Memoize(func(context.Context, string) (int, error) {
+			calls.Add(1)
+			return 0, boom
+		}, time.Minute, 10, WithNegativeTTL(20*time.Millisecond))
+
+		for i := 0; i < 3; i++ {
+			if _, err := fail(context.Background(), "a"); err != boom {
+				t.Errorf("Expected boom, got %v", err)
+			}
+		}
+		if n := calls.Load(); n != 1 {
+			t.Errorf("Expected the error to be cached, got %d calls", n)
+		}
+		time.Sleep(30 * time.Millisecond)
+		fail(context.Background(), "a")
+		if n := calls.Load(); n != 2 {
+			t.Errorf("Expected a retry after the negative TTL, got %d calls", n)
+		}
+	})
+
+	t.Run("cancellation not cached", func(t *testing.T) {
+		var calls atomic.Int32
+		fn := Memoize(func(ctx context.Context, _ string) (int, error) {
+			calls.Add(1)
+			return 0, ctx.Err()
+		}, time.Minute, 10, WithNegativeTTL(time.Minute))
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		fn(ctx, "a")
+		fn(context.Background(), "a")
+		if n := calls.Load(); n != 2 {
+			t.Errorf("Expected 2 calls, got %d", n)
+		}
+	})
+}
@@ -0,0 +1,187 @@
+package concurrent
+
+import (
+	"context"
+	"time"
+)
+
+// Drain discards items from ch until it is closed or ctx is cancelled,
+// and returns the number of items discarded.
+func Drain[T any](ctx context.Context, ch <-chan T) int {
+	n := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return n
+		case _, ok := <-ch:
+			if !ok {
+				return n
+			}
+			n++
+		}
+	}
+}
+
+// Collect reads items from ch into a slice until ch is closed, ctx is
+// cancelled, or limit items have been read. A limit of zero or less means
+// no limit.
+func Collect[T any](ctx context.Context, ch <-chan T, limit int) []T {
+	var items []T
+	for limit <= 0 || len(items) < limit {
+		select {
+		case <-ctx.Done():
+			return items
+		case item, ok := <-ch:
+			if !ok {
+				return items
+			}
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Bridge flattens a channel of channels into a single channel, reading each
+// inner channel to completion before moving to the next.
+// The output channel is closed when chans is closed or ctx is cancelled.
+func Bridge[T any](ctx context.Context, chans <-chan (<-chan T)) <-chan T {
+	output := make(chan T)
+	go func() {
+		defer close(output)
+		for {
+			var ch <-chan T
+			select {
+			case <-ctx.Done():
+				return
+			case c, ok := <-chans:
+				if !ok {
+					return
+				}
+				ch = c
+			}
+		drain:
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case item, ok := <-ch:
+					if !ok {
+						break drain
+					}
+					select {
+					case <-ctx.Done():
+						return
+					case output <- item:
+					}
+				}
+			}
+		}
+	}()
+	return output
+}
+
+// RecvBatch reads up to n items from ch, returning early when maxWait has
+// elapsed since the call or ctx is cancelled. The boolean result is false if
+// ch was closed; the returned batch may still hold items in that case.
+func RecvBatch[T any](ctx context.Context, ch <-chan T, n int, maxWait time.Duration, opts ...TimeOption) ([]T, bool) {
+	if n <= 0 {
+		n = 1
+	}
+	timeout := timeClock(opts).After(maxWait)
+
+	batch := make([]T, 0, n)
+	for len(batch) < n {
+		select {
+		case <-ctx.Done():
+			return batch, true
+		case <-timeout:
+			return batch, true
+		case item, ok := <-ch:
+			if !ok {
+				return batch, false
+			}
+			batch = append(batch, item)
+		}
+	}
+	return batch, true
+}
+
+// Recv receives the next item from input, reporting false once input is
+// closed or ctx is done. Unlike a plain select over both, it honors a
+// cancelled ctx even while input always has items ready.
+func Recv[T any](ctx context.Context, input <-chan T) (T, bool) {
+	var zero T
+	done := ctx.Done()
+	select {
+	case <-done:
+		return zero, false
+	default:
+	}
+	select {
+	case <-done:
+		return zero, false
+	case item, ok := <-input:
+		return item, ok
+	}
+}
+
+// Send sends item on output, reporting false if ctx is done first. Unlike a
+// plain select over both, it does not send once ctx is cancelled, even when
+// output is ready.
+func Send[T any](ctx context.Context, output chan<- T, item T) bool {
+	done := ctx.Done()
+	select {
+	case <-done:
+		return false
+	default:
+	}
+	select {
+	case <-done:
+		return false
+	case output <- item:
+		return true
+	}
+}
+
+// ReceivePreferring receives from preferred or other, whichever is ready,
+// taking preferred whenever both are, as for a control channel that must
+// not be starved by a firehose of data. It reports whether the item came
+// from preferred, and false once ctx is done or the channel it read is
+// closed; a cancelled ctx takes precedence over both channels.
+func ReceivePreferring[T any](ctx context.Context, preferred, other <-chan T) (item T, fromPreferred, ok bool) {
+	done := ctx.Done()
+	select {
+	case <-done:
+		return item, false, false
+	default:
+	}
+	select {
+	case item, ok = <-preferred:
+		return item, true, ok
+	default:
+	}
+	select {
+	case <-done:
+		return item, false, false
+	case item, ok = <-preferred:
+		return item, true, ok
+	case item, ok = <-other:
+		return item, false, ok
+	}
+}
+
+// emitSlice sends items on a new channel, closing it when done or when ctx is cancelled.
+func emitSlice[T any](ctx context.Context, items []T) <-chan T {
+	output := make(chan T)
+	go func() {
+		defer close(output)
+		for _, item := range items {
+			select {
+			case <-ctx.Done():
+				return
+			case output <- item:
+			}
+		}
+	}()
+	return output
+}
@@ -0,0 +1,176 @@
+package concurrent
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func sliceChan[T any](items ...T) <-chan T {
+	ch := make(chan T, len(items))
+	for _, item := range items {
+		ch <- item
+	}
+	close(ch)
+	return ch
+}
+
+func TestDrain(t *testing.T) {
+	if n := Drain(context.Background(), sliceChan(1, 2, 3)); n != 3 {
+		t.Errorf("Expected 3 drained items, got %d", n)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if n := Drain(ctx, make(chan int)); n != 0 {
+		t.Errorf("Expected 0 drained items after cancellation, got %d", n)
+	}
+}
+
+func TestCollect(t *testing.T) {
+	ctx := context.Background()
+
+	if got := Collect(ctx, sliceChan(1, 2, 3), 0); len(got) != 3 {
+		t.Errorf("Expected 3 items, got %v", got)
+	}
+	if got := Collect(ctx, sliceChan(1, 2, 3), 2); len(got) != 2 || got[1] != 2 {
+		t.Errorf("Expected [1 2], got %v", got)
+	}
+}
+
+func TestBridge(t *testing.T) {
+	ctx := context.Background()
+	chans := make(chan (<-chan int), 3)
+	chans <- sliceChan(1, 2)
+	chans <- sliceChan[int]()
+	chans <- sliceChan(3)
+	close(chans)
+
+	got := Collect(ctx, Bridge(ctx, chans), 0)
+	expected := []int{1, 2, 3}
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, got)
+	}
+	for i, v := range got {
+		if v != expected[i] {
+			t.Errorf("Expected %d at index %d, got %d", expected[i], i, v)
+		}
+	}
+}
+
+func TestRecvBatch(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("full batch", func(t *testing.T) {
+		batch, open := RecvBatch(ctx, sliceChan(1, 2, 3, 4), 3, time.Second)
+		if len(batch) != 3 || !open {
+			t.Errorf("Expected 3 items from open channel, got %v, %v", batch, open)
+		}
+	})
+
+	t.Run("closed channel", func(t *testing.T) {
+		batch, open := RecvBatch(ctx, sliceChan(1), 3, time.Second)
+		if len(batch) != 1 || open {
+			t.Errorf("Expected 1 item from closed channel, got %v, %v", batch, open)
+		}
+	})
+
+	t.Run("max wait", func(t *testing.T) {
+		ch := make(chan int, 1)
+		ch <- 1
+		start := time.Now()
+		batch, open := RecvBatch(ctx, ch, 3, 20*time.Millisecond)
+		if len(batch) != 1 || !open {
+			t.Errorf("Expected partial batch, got %v, %v", batch, open)
+		}
+		if time.Since(start) < 20*time.Millisecond {
+			t.Error("Returned before max wait elapsed")
+		}
+	})
+
+	t.Run("max wait on a fake clock", func(t *testing.T) {
+		clock := NewFakeClock(time.Now())
+		ch := make(chan int, 1)
+		ch <- 1
+		done := make(chan []int)
+		go func() {
+			batch, _ := RecvBatch(ctx, ch, 3, time.Hour, WithClock(clock))
+			done <- batch
+		}()
+
+		clock.BlockUntil(1)
+		clock.Advance(time.Hour)
+		if batch := <-done; len(batch) != 1 {
+			t.Errorf("Expected partial batch, got %v", batch)
+		}
+	})
+}
+
+func TestPreferringReceive(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	ready := make(chan int, 100)
+	for i := range 100 {
+		ready <- i
+	}
+
+	t.Run("recv prefers cancellation", func(t *testing.T) {
+		for range 50 {
+			if item, ok := Recv(cancelled, ready); ok {
+				t.Fatalf("Expected cancellation to win over ready data, got %d", item)
+			}
+		}
+	})
+
+	t.Run("send prefers cancellation", func(t *testing.T) {
+		output := make(chan int, 50)
+		for range 50 {
+			if Send(cancelled, output, 1) {
+				t.Fatal("Expected cancellation to win over ready output")
+			}
+		}
+		if len(output) != 0 {
+			t.Errorf("Expected no sends, got %d", len(output))
+		}
+	})
+
+	t.Run("prefers control channel", func(t *testing.T) {
+		control := make(chan int, 1)
+		control <- -1
+		item, preferred, ok := ReceivePreferring(context.Background(), control, ready)
+		if item != -1 || !preferred || !ok {
+			t.Errorf("Expected control item, got %d, %v, %v", item, preferred, ok)
+		}
+		item, preferred, ok = ReceivePreferring(context.Background(), control, ready)
+		if preferred || !ok {
+			t.Errorf("Expected data item once control is empty, got %d, %v, %v", item, preferred, ok)
+		}
+		if _, _, ok := ReceivePreferring(cancelled, control, ready); ok {
+			t.Error("Expected cancellation to win over both channels")
+		}
+	})
+
+	t.Run("map stops under firehose", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		input := make(chan int)
+		go func() {
+			for {
+				select {
+				case input <- 1:
+				case <-time.After(time.Second):
+					return
+				}
+			}
+		}()
+		output := Map(func(v int) int { return v })(ctx, input)
+		<-output
+		cancel()
+		received := 0
+		for range output {
+			received++
+		}
+		if received > 1 {
+			t.Errorf("Expected at most 1 item after cancel, got %d", received)
+		}
+	})
+}
@@ -0,0 +1,126 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// ErrChaos is the error injected by chaos wrappers when ChaosConfig.Err is nil.
+var ErrChaos = errors.New("concurrent: chaos injected failure")
+
+// ChaosConfig controls the faults injected by Chaos, ChaosStage and WithChaos.
+// Probabilities are in [0, 1]; each fault is decided independently per item.
+type ChaosConfig struct {
+	// LatencyProbability is the chance of delaying an item by a random
+	// duration up to MaxLatency.
+	LatencyProbability float64
+	MaxLatency         time.Duration
+	// ErrorProbability is the chance of failing an item with Err.
+	ErrorProbability float64
+	Err              error
+	// PanicProbability is the chance of panicking while handling an item.
+	PanicProbability float64
+	// Seed makes the injected faults reproducible. Zero uses a random seed.
+	Seed uint64
+}
+
+// chaos injects the faults described by a ChaosConfig.
+type chaos struct {
+	config ChaosConfig
+	mu     sync.Mutex
+	rng    *rand.Rand
+}
+
+func newChaos(config ChaosConfig) *chaos {
+	seed := config.Seed
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	if config.Err == nil {
+		config.Err = ErrChaos
+	}
+	return &chaos{config: config, rng: rand.New(rand.NewPCG(seed, seed))}
+}
+
+// roll draws all random decisions for one item up front, so the sequence of
+// faults depends only on the seed and the number of items.
+func (c *chaos) roll() (delay time.Duration, fail, panics bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.rng.Float64() < c.config.LatencyProbability && c.config.MaxLatency > 0 {
+		delay = time.Duration(c.rng.Int64N(int64(c.config.MaxLatency)) + 1)
+	}
+	fail = c.rng.Float64() < c.config.ErrorProbability
+	panics = c.rng.Float64() < c.config.PanicProbability
+	return delay, fail, panics
+}
+
+// inject applies the faults for one item. It returns a non-nil error if the
+// item should fail.
+func (c *chaos) inject(ctx context.Context) error {
+	delay, fail, panics := c.roll()
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	if panics {
+		panic(c.config.Err)
+	}
+	if fail {
+		return c.config.Err
+	}
+	return nil
+}
+
+// Chaos wraps fn so that calls are randomly delayed, failed or made to panic
+// according to config, for testing retry, breaker and backpressure settings
+// under failure. Failed calls do not invoke fn.
+func Chaos[T any, R any](config ChaosConfig, fn func(context.Context, T) (R, error)) func(context.Context, T) (R, error) {
+	c := newChaos(config)
+	return func(ctx context.Context, item T) (R, error) {
+		if err := c.inject(ctx); err != nil {
+			var zero R
+			return zero, err
+		}
+		return fn(ctx, item)
+	}
+}
+
+// ChaosStage creates a pass-through stage that randomly delays items, drops
+// them (the stage equivalent of an error) or panics according to config.
+func ChaosStage[T any](config ChaosConfig) Stage[T, T] {
+	return func(ctx context.Context, input <-chan T) <-chan T {
+		c := newChaos(config)
+		output := make(chan T)
+		go func() {
+			defer close(output)
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case item, ok := <-input:
+					if !ok {
+						return
+					}
+					if err := c.inject(ctx); err != nil {
+						continue
+					}
+					select {
+					case <-ctx.Done():
+						return
+					case output <- item:
+					}
+				}
+			}
+		}()
+		return output
+	}
+}
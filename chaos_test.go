@@ -0,0 +1,78 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestChaos(t *testing.T) {
+	identity := func(_ context.Context, v int) (int, error) { return v, nil }
+
+	t.Run("errors", func(t *testing.T) {
+		boom := errors.New("boom")
+		fn := Chaos(ChaosConfig{ErrorProbability: 1, Err: boom}, identity)
+		if _, err := fn(context.Background(), 1); !errors.Is(err, boom) {
+			t.Errorf("Expected injected error, got %v", err)
+		}
+
+		fn = Chaos(ChaosConfig{ErrorProbability: 1}, identity)
+		if _, err := fn(context.Background(), 1); !errors.Is(err, ErrChaos) {
+			t.Errorf("Expected ErrChaos, got %v", err)
+		}
+	})
+
+	t.Run("seeded", func(t *testing.T) {
+		run := func() []bool {
+			fn := Chaos(ChaosConfig{ErrorProbability: 0.5, Seed: 42}, identity)
+			var failed []bool
+			for i := 0; i < 20; i++ {
+				_, err := fn(context.Background(), i)
+				failed = append(failed, err != nil)
+			}
+			return failed
+		}
+		a, b := run(), run()
+		for i := range a {
+			if a[i] != b[i] {
+				t.Fatalf("Expected identical fault sequences for the same seed, got %v and %v", a, b)
+			}
+		}
+	})
+
+	t.Run("latency", func(t *testing.T) {
+		fn := Chaos(ChaosConfig{LatencyProbability: 1, MaxLatency: 20 * time.Millisecond}, identity)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := fn(ctx, 1); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected delay to honor cancellation, got %v", err)
+		}
+	})
+
+	t.Run("panics", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected injected panic")
+			}
+		}()
+		fn := Chaos(ChaosConfig{PanicProbability: 1}, identity)
+		_, _ = fn(context.Background(), 1)
+	})
+
+	t.Run("stage", func(t *testing.T) {
+		ctx := context.Background()
+		got := Collect(ctx, ChaosStage[int](ChaosConfig{ErrorProbability: 1})(ctx, sliceChan(1, 2, 3)), 0)
+		if len(got) != 0 {
+			t.Errorf("Expected every item to be dropped, got %v", got)
+		}
+	})
+
+	t.Run("pool option", func(t *testing.T) {
+		ctx := context.Background()
+		pool := NewPool[int, int](2, identity, WithChaos(ChaosConfig{ErrorProbability: 1}))
+		if n := Drain(ctx, pool.Run(ctx, sliceChan(1, 2, 3))); n != 0 {
+			t.Errorf("Expected all jobs to fail, got %d results", n)
+		}
+	})
+}
@@ -0,0 +1,198 @@
+package concurrent
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock abstracts the passage of time so that time-dependent behavior such as
+// retry backoff, rate limiter refills and circuit breaker resets can be tested
+// deterministically with a FakeClock instead of real sleeps.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker is the subset of time.Ticker used through a Clock.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// RealClock is the Clock backed by the time package. Components use it when
+// no clock is configured.
+var RealClock Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (realClock) NewTicker(d time.Duration) Ticker       { return realTicker{time.NewTicker(d)} }
+
+type realTicker struct{ t *time.Ticker }
+
+func (t realTicker) C() <-chan time.Time { return t.t.C }
+func (t realTicker) Stop()               { t.t.Stop() }
+
+// clockOrReal returns c, or RealClock if c is nil.
+func clockOrReal(c Clock) Clock {
+	if c == nil {
+		return RealClock
+	}
+	return c
+}
+
+// timeClock applies opts and returns the configured clock, or RealClock.
+func timeClock(opts []TimeOption) Clock {
+	var options TimeOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return clockOrReal(options.Clock)
+}
+
+// FakeClock is a Clock whose time only moves when Advance or Set is called.
+// Timers and tickers created from it fire synchronously during Advance.
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*fakeWaiter
+	changed chan struct{} // closed and replaced whenever waiters change
+}
+
+type fakeWaiter struct {
+	deadline time.Time
+	period   time.Duration // non-zero for tickers
+	ch       chan time.Time
+}
+
+// NewFakeClock creates a fake clock set to start.
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start, changed: make(chan struct{})}
+}
+
+// Now returns the fake clock's current time.
+func (f *FakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// After returns a channel that receives the fake time once the clock has
+// been advanced by at least d.
+func (f *FakeClock) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if d <= 0 {
+		ch <- f.now
+		return ch
+	}
+	f.addLocked(&fakeWaiter{deadline: f.now.Add(d), ch: ch})
+	return ch
+}
+
+// NewTicker returns a ticker that fires every d of fake time. Like
+// time.Ticker, it drops ticks for slow receivers.
+func (f *FakeClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("concurrent: non-positive interval for FakeClock.NewTicker")
+	}
+	w := &fakeWaiter{period: d, ch: make(chan time.Time, 1)}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.deadline = f.now.Add(d)
+	f.addLocked(w)
+	return &fakeTicker{clock: f, w: w}
+}
+
+// Advance moves the clock forward by d, firing every timer and ticker that
+// becomes due, in deadline order.
+func (f *FakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	target := f.now.Add(d)
+	f.mu.Unlock()
+	f.Set(target)
+}
+
+// Set moves the clock to t, firing every timer and ticker due by then.
+// Setting the clock backwards only changes Now.
+func (f *FakeClock) Set(t time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for {
+		sort.Slice(f.waiters, func(i, j int) bool { return f.waiters[i].deadline.Before(f.waiters[j].deadline) })
+		if len(f.waiters) == 0 || f.waiters[0].deadline.After(t) {
+			break
+		}
+		w := f.waiters[0]
+		f.now = w.deadline
+		select {
+		case w.ch <- w.deadline:
+		default:
+		}
+		if w.period > 0 {
+			w.deadline = w.deadline.Add(w.period)
+		} else {
+			f.removeLocked(w)
+		}
+	}
+	f.now = t
+}
+
+// Waiters returns the number of pending timers and tickers.
+func (f *FakeClock) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.waiters)
+}
+
+// BlockUntil blocks until at least n timers and tickers are pending, which
+// lets a test wait for a goroutine to start sleeping before advancing.
+func (f *FakeClock) BlockUntil(n int) {
+	for {
+		f.mu.Lock()
+		if len(f.waiters) >= n {
+			f.mu.Unlock()
+			return
+		}
+		changed := f.changed
+		f.mu.Unlock()
+		<-changed
+	}
+}
+
+func (f *FakeClock) addLocked(w *fakeWaiter) {
+	f.waiters = append(f.waiters, w)
+	f.notifyLocked()
+}
+
+func (f *FakeClock) removeLocked(w *fakeWaiter) {
+	for i, other := range f.waiters {
+		if other == w {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			f.notifyLocked()
+			return
+		}
+	}
+}
+
+func (f *FakeClock) notifyLocked() {
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+type fakeTicker struct {
+	clock *FakeClock
+	w     *fakeWaiter
+}
+
+func (t *fakeTicker) C() <-chan time.Time { return t.w.ch }
+
+func (t *fakeTicker) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	t.clock.removeLocked(t.w)
+}
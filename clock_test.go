@@ -0,0 +1,130 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("after", func(t *testing.T) {
+		clock := NewFakeClock(start)
+		ch := clock.After(time.Second)
+
+		clock.Advance(500 * time.Millisecond)
+		select {
+		case <-ch:
+			t.Fatal("Expected timer not to fire before its deadline")
+		default:
+		}
+
+		clock.Advance(500 * time.Millisecond)
+		select {
+		case at := <-ch:
+			if !at.Equal(start.Add(time.Second)) {
+				t.Errorf("Expected fire time %v, got %v", start.Add(time.Second), at)
+			}
+		default:
+			t.Fatal("Expected timer to fire at its deadline")
+		}
+		if clock.Waiters() != 0 {
+			t.Errorf("Expected no pending waiters, got %d", clock.Waiters())
+		}
+	})
+
+	t.Run("ticker", func(t *testing.T) {
+		clock := NewFakeClock(start)
+		ticker := clock.NewTicker(time.Second)
+		defer ticker.Stop()
+
+		ticks := 0
+		for i := 0; i < 3; i++ {
+			clock.Advance(time.Second)
+			select {
+			case <-ticker.C():
+				ticks++
+			default:
+			}
+		}
+		if ticks != 3 {
+			t.Errorf("Expected 3 ticks, got %d", ticks)
+		}
+
+		ticker.Stop()
+		if clock.Waiters() != 0 {
+			t.Errorf("Expected stopped ticker to be removed, got %d waiters", clock.Waiters())
+		}
+	})
+}
+
+func TestClockIntegration(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("retry", func(t *testing.T) {
+		clock := NewFakeClock(start)
+		config := RetryConfig{MaxRetries: 2, BaseDelay: time.Hour, MaxDelay: time.Hour, Multiplier: 1, Clock: clock}
+
+		attempts := 0
+		done := make(chan error, 1)
+		go func() {
+			done <- Retry(context.Background(), 0, func(context.Context, int) error {
+				attempts++
+				if attempts < 3 {
+					return errors.New("transient")
+				}
+				return nil
+			}, config)
+		}()
+
+		for i := 0; i < 2; i++ {
+			clock.BlockUntil(1)
+			clock.Advance(time.Hour)
+		}
+		if err := <-done; err != nil {
+			t.Errorf("Expected success, got %v", err)
+		}
+		if attempts != 3 {
+			t.Errorf("Expected 3 attempts, got %d", attempts)
+		}
+	})
+
+	t.Run("rate limiter", func(t *testing.T) {
+		clock := NewFakeClock(start)
+		rl := NewRateLimiter(2, time.Minute, WithLimiterClock(clock))
+		rl.Allow()
+		rl.Allow()
+
+		rl.Refill()
+		if rl.Allow() {
+			t.Fatal("Expected no tokens before the interval elapses")
+		}
+
+		clock.Advance(time.Minute)
+		rl.Refill()
+		if rl.Tokens() != 2 {
+			t.Errorf("Expected 2 tokens after one interval, got %d", rl.Tokens())
+		}
+	})
+
+	t.Run("circuit breaker", func(t *testing.T) {
+		clock := NewFakeClock(start)
+		cb := NewCircuitBreaker(1, time.Minute, WithBreakerClock(clock))
+		ctx := context.Background()
+
+		_ = cb.Execute(ctx, func() error { return errors.New("fail") })
+		if err := cb.Execute(ctx, func() error { return nil }); err == nil {
+			t.Fatal("Expected open breaker to reject")
+		}
+
+		clock.Advance(time.Minute)
+		if err := cb.Execute(ctx, func() error { return nil }); err != nil {
+			t.Errorf("Expected breaker to allow a probe after reset timeout, got %v", err)
+		}
+		if cb.State() != StateClosed {
+			t.Errorf("Expected breaker to close, got %v", cb.State())
+		}
+	})
+}
@@ -0,0 +1,96 @@
+package concurrent
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"sync"
+)
+
+// GzipCompress creates a stage that compresses each batch of byte slices
+// into one gzip stream, for example after Batch and before a network or disk
+// sink. Writers are pooled and reused across batches. An invalid level is
+// replaced by gzip.DefaultCompression. Wrap the stage with Parallel to
+// compress batches concurrently.
+func GzipCompress(level int) Stage[[][]byte, []byte] {
+	if _, err := gzip.NewWriterLevel(io.Discard, level); err != nil {
+		level = gzip.DefaultCompression
+	}
+	writers := sync.Pool{New: func() any {
+		w, _ := gzip.NewWriterLevel(io.Discard, level)
+		return w
+	}}
+	return func(ctx context.Context, input <-chan [][]byte) <-chan []byte {
+		return mapBytes(ctx, input, func(batch [][]byte) ([]byte, error) {
+			var buf bytes.Buffer
+			w := writers.Get().(*gzip.Writer)
+			defer writers.Put(w)
+			w.Reset(&buf)
+			for _, b := range batch {
+				if _, err := w.Write(b); err != nil {
+					return nil, err
+				}
+			}
+			if err := w.Close(); err != nil {
+				return nil, err
+			}
+			return buf.Bytes(), nil
+		})
+	}
+}
+
+// GzipDecompress creates a stage that decompresses each gzip stream, such as
+// one produced by GzipCompress. Invalid input is dropped and, in a Pipeline,
+// reported through SendError.
+func GzipDecompress() Stage[[]byte, []byte] {
+	var readers sync.Pool
+	return func(ctx context.Context, input <-chan []byte) <-chan []byte {
+		return mapBytes(ctx, input, func(data []byte) ([]byte, error) {
+			var r *gzip.Reader
+			if pooled, ok := readers.Get().(*gzip.Reader); ok {
+				if err := pooled.Reset(bytes.NewReader(data)); err != nil {
+					return nil, err
+				}
+				r = pooled
+			} else {
+				var err error
+				if r, err = gzip.NewReader(bytes.NewReader(data)); err != nil {
+					return nil, err
+				}
+			}
+			defer readers.Put(r)
+			return io.ReadAll(r)
+		})
+	}
+}
+
+// mapBytes applies fn to each item, dropping items that fail after
+// reporting the error with SendError.
+func mapBytes[T any](ctx context.Context, input <-chan T, fn func(T) ([]byte, error)) <-chan []byte {
+	output := make(chan []byte)
+	go func() {
+		defer close(output)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case item, ok := <-input:
+				if !ok {
+					return
+				}
+				result, err := fn(item)
+				if err != nil {
+					SendError(ctx, err)
+					continue
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case output <- result:
+				}
+			}
+		}
+	}()
+	return output
+}
@@ -0,0 +1,67 @@
+package concurrent
+
+import (
+	"compress/gzip"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestGzip(t *testing.T) {
+	batches := [][][]byte{
+		{[]byte("hello "), []byte("world")},
+		{[]byte(strings.Repeat("a", 1000))},
+		{},
+	}
+	expected := []string{"hello world", strings.Repeat("a", 1000), ""}
+
+	t.Run("round trip in parallel", func(t *testing.T) {
+		ctx := context.Background()
+		compressed := Parallel(GzipCompress(gzip.BestSpeed), 3, true)(ctx, emitSlice(ctx, batches))
+		results := Collect(ctx, GzipDecompress()(ctx, compressed), 0)
+		if len(results) != len(expected) {
+			t.Fatalf("Expected %d results, got %d", len(expected), len(results))
+		}
+		for i, r := range results {
+			if string(r) != expected[i] {
+				t.Errorf("Expected %q at %d, got %q", expected[i], i, r)
+			}
+		}
+	})
+
+	t.Run("invalid level", func(t *testing.T) {
+		ctx := context.Background()
+		compressed := GzipCompress(42)(ctx, emitSlice(ctx, batches[:1]))
+		results := Collect(ctx, GzipDecompress()(ctx, compressed), 0)
+		if len(results) != 1 || string(results[0]) != "hello world" {
+			t.Errorf("Expected [hello world], got %q", results)
+		}
+	})
+
+	t.Run("invalid input reported", func(t *testing.T) {
+		ctx := context.Background()
+		good := Collect(ctx, GzipCompress(gzip.DefaultCompression)(ctx, emitSlice(ctx, batches[:1])), 0)
+
+		pipeline := NewPipeline[[]byte](ctx)
+		defer pipeline.Close()
+		pipeline.AddStage(GzipDecompress())
+		errs := pipeline.Errors()
+		var collected []error
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for err := range errs {
+				collected = append(collected, err)
+			}
+		}()
+
+		results := pipeline.RunSlice([][]byte{[]byte("not gzip"), good[0]})
+		<-done
+		if len(results) != 1 || string(results[0]) != "hello world" {
+			t.Errorf("Expected [hello world], got %q", results)
+		}
+		if len(collected) != 1 {
+			t.Errorf("Expected 1 error, got %v", collected)
+		}
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -115,6 +116,368 @@ func TestPool(t *testing.T) {
 	})
 }
 
+// TestPoolR tests that PoolR reports failures as results
+func TestPoolR(t *testing.T) {
+	pool := NewPoolR[int, int](2, func(_ context.Context, v int) (int, error) {
+		if v%2 == 0 {
+			return 0, errors.New("even numbers fail")
+		}
+		return v, nil
+	})
+
+	jobs := make(chan int)
+	go func() {
+		for i := 0; i < 4; i++ {
+			jobs <- i
+		}
+		close(jobs)
+	}()
+
+	var ok, failed int
+	for r := range pool.Run(context.Background(), jobs) {
+		if r.Ok() {
+			ok++
+		} else {
+			failed++
+		}
+	}
+	if ok != 2 || failed != 2 {
+		t.Errorf("Expected 2 successes and 2 failures, got %d and %d", ok, failed)
+	}
+}
+
+// TestJobPool tests the job envelope pool
+func TestJobPool(t *testing.T) {
+	pool := NewJobPool[int, int](2, func(_ context.Context, v int) (int, error) {
+		time.Sleep(5 * time.Millisecond)
+		if v == 3 {
+			return 0, errors.New("three fails")
+		}
+		return v * 10, nil
+	})
+
+	jobs := make(chan int)
+	go func() {
+		for i := 0; i < 6; i++ {
+			jobs <- i
+		}
+		close(jobs)
+	}()
+
+	seen := make(map[int]bool)
+	workers := make(map[int]bool)
+	var queued bool
+	for job := range pool.Run(context.Background(), jobs) {
+		seen[job.Input] = true
+		workers[job.WorkerID] = true
+		if job.Input == 3 {
+			if job.Err == nil {
+				t.Error("Expected an error for input 3")
+			}
+		} else if job.Err != nil || job.Output != job.Input*10 {
+			t.Errorf("Expected %d for input %d, got %d (%v)", job.Input*10, job.Input, job.Output, job.Err)
+		}
+		if job.ProcessingTime() < 5*time.Millisecond {
+			t.Errorf("Expected processing time of at least 5ms, got %v", job.ProcessingTime())
+		}
+		if job.QueueTime() < 0 {
+			t.Errorf("Expected non-negative queue time, got %v", job.QueueTime())
+		}
+		queued = queued || job.QueueTime() > time.Millisecond
+	}
+	if len(seen) != 6 {
+		t.Errorf("Expected 6 jobs, got %d", len(seen))
+	}
+	if !workers[0] || !workers[1] || len(workers) != 2 {
+		t.Errorf("Expected worker IDs 0 and 1, got %v", workers)
+	}
+	if !queued {
+		t.Error("Expected some jobs to wait for a worker")
+	}
+}
+
+func TestJobPoolOptions(t *testing.T) {
+	metrics := NewMetrics()
+	var mu sync.Mutex
+	var keys []any
+	var attempts atomic.Int32
+	pool := NewJobPool[int, int](2, func(_ context.Context, v int) (int, error) {
+		if v == 1 {
+			attempts.Add(1)
+			return 0, errors.New("one fails")
+		}
+		return v, nil
+	}, WithMetrics(metrics), WithRetryConfig(2, time.Millisecond), WithAffinity(func(item any) any {
+		mu.Lock()
+		keys = append(keys, item)
+		mu.Unlock()
+		return item
+	}))
+
+	var failed []Job[int, int]
+	for job := range pool.Run(context.Background(), emitSlice(context.Background(), []int{0, 1, 2})) {
+		if job.Err != nil {
+			failed = append(failed, job)
+		}
+	}
+	if len(failed) != 1 || failed[0].Input != 1 {
+		t.Errorf("Expected job 1 to be delivered as failed, got %+v", failed)
+	}
+	if n := attempts.Load(); n != 3 {
+		t.Errorf("Expected the failed job to be retried twice, got %d attempts", n)
+	}
+	if metrics.ProcessedCount != 2 || metrics.ErrorCount != 1 {
+		t.Errorf("Expected 2 successes and 1 error, got %d and %d", metrics.ProcessedCount, metrics.ErrorCount)
+	}
+	for _, key := range keys {
+		if _, ok := key.(int); !ok {
+			t.Errorf("Expected the affinity key fn to receive an int, got %T", key)
+		}
+	}
+}
+
+// TestPoolDedup tests coalescing of duplicate jobs
+func TestPoolDedup(t *testing.T) {
+	var mu sync.Mutex
+	calls := make(map[string]int)
+	fn := func(_ context.Context, key string) (string, error) {
+		mu.Lock()
+		calls[key]++
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		return key + "!", nil
+	}
+	key := func(item any) any { return item }
+
+	t.Run("in flight", func(t *testing.T) {
+		clear(calls)
+		pool := NewPool(2, fn, WithDedup(key, 0))
+		jobs := make(chan string, 4)
+		for _, k := range []string{"a", "a", "a", "b"} {
+			jobs <- k
+		}
+		close(jobs)
+
+		var results []string
+		for r := range pool.Run(context.Background(), jobs) {
+			results = append(results, r)
+		}
+		if len(results) != 4 {
+			t.Errorf("Expected a result for every job, got %v", results)
+		}
+		if calls["a"] != 1 || calls["b"] != 1 {
+			t.Errorf("Expected one call per key, got %v", calls)
+		}
+	})
+
+	t.Run("ttl", func(t *testing.T) {
+		clear(calls)
+		pool := NewPool(1, fn, WithDedup(key, time.Minute))
+		for range 2 {
+			jobs := make(chan string, 1)
+			jobs <- "a"
+			close(jobs)
+			if got := Collect(context.Background(), pool.Run(context.Background(), jobs), 0); len(got) != 1 || got[0] != "a!" {
+				t.Errorf("Expected [a!], got %v", got)
+			}
+		}
+		if calls["a"] != 1 {
+			t.Errorf("Expected the result to be reused within the ttl, got %d calls", calls["a"])
+		}
+	})
+}
+
+// TestPoolAffinity tests routing of jobs by key
+func TestPoolAffinity(t *testing.T) {
+	type job struct{ key, seq int }
+	var mu sync.Mutex
+	workers := make(map[int]map[int]bool)
+	last := make(map[int]int)
+
+	pool := NewPool(4, func(ctx context.Context, j job) (int, error) {
+		id, _ := WorkerID(ctx)
+		mu.Lock()
+		defer mu.Unlock()
+		if workers[j.key] == nil {
+			workers[j.key] = make(map[int]bool)
+		}
+		workers[j.key][id] = true
+		if prev, ok := last[j.key]; ok && prev > j.seq {
+			t.Errorf("Expected key %d in order, got %d after %d", j.key, j.seq, prev)
+		}
+		last[j.key] = j.seq
+		return j.seq, nil
+	}, WithAffinity(func(item any) any { return item.(job).key }))
+
+	var items []job
+	for seq := 0; seq < 50; seq++ {
+		items = append(items, job{key: seq % 7, seq: seq})
+	}
+	if got := pool.RunSlice(context.Background(), items); len(got) != len(items) {
+		t.Errorf("Expected %d results, got %d", len(items), len(got))
+	}
+	for key, ids := range workers {
+		if len(ids) != 1 {
+			t.Errorf("Expected key %d on one worker, got %v", key, ids)
+		}
+	}
+}
+
+// TestPoolRunSources tests feeding one pool from several channels
+func TestPoolRunSources(t *testing.T) {
+	pool := NewPool(2, func(_ context.Context, v int) (int, error) {
+		return v, nil
+	})
+	control := make(chan int)
+	bulk := make(chan int, 100)
+	for i := 0; i < 100; i++ {
+		bulk <- i
+	}
+	close(bulk)
+
+	results := pool.RunSources(context.Background(), control, bulk)
+	// A control job gets through while the bulk channel is still full.
+	control <- -1
+	close(control)
+
+	sum, count := 0, 0
+	for v := range results {
+		sum += v
+		count++
+	}
+	if count != 101 || sum != 4950-1 {
+		t.Errorf("Expected 101 results summing to 4949, got %d summing to %d", count, sum)
+	}
+}
+
+// TestPoolDrainOnCancel tests finishing accepted jobs after cancellation
+func TestPoolDrainOnCancel(t *testing.T) {
+	run := func(t *testing.T, opts ...PoolOption) int {
+		started := make(chan struct{}, 10)
+		gate := make(chan struct{})
+		pool := NewPool(2, func(ctx context.Context, v int) (int, error) {
+			started <- struct{}{}
+			<-gate
+			if ctx.Err() != nil {
+				return 0, ctx.Err()
+			}
+			return v, nil
+		}, opts...)
+
+		jobs := make(chan int, 10)
+		for i := 0; i < 10; i++ {
+			jobs <- i
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		results := pool.Run(ctx, jobs)
+		<-started
+		<-started
+		cancel()
+		close(gate)
+		return len(Collect(context.Background(), results, 0))
+	}
+
+	t.Run("in flight", func(t *testing.T) {
+		if n := run(t, WithDrainOnCancel(DrainInFlight)); n != 2 {
+			t.Errorf("Expected the 2 running jobs to finish, got %d", n)
+		}
+	})
+
+	t.Run("buffered", func(t *testing.T) {
+		if n := run(t, WithDrainOnCancel(DrainBuffered)); n != 10 {
+			t.Errorf("Expected all 10 accepted jobs to finish, got %d", n)
+		}
+	})
+
+	t.Run("buffered with affinity", func(t *testing.T) {
+		n := run(t, WithDrainOnCancel(DrainBuffered), WithAffinity(func(item any) any { return item }))
+		if n != 10 {
+			t.Errorf("Expected all 10 accepted jobs to finish, got %d", n)
+		}
+	})
+
+	t.Run("none", func(t *testing.T) {
+		if n := run(t); n != 0 {
+			t.Errorf("Expected running jobs to see cancellation, got %d results", n)
+		}
+	})
+}
+
+// TestPoolErrorPolicy tests the handling of failed jobs
+func TestPoolErrorPolicy(t *testing.T) {
+	errOdd := errors.New("odd")
+	fn := func(_ context.Context, v int) (int, error) {
+		if v%2 != 0 {
+			return 0, fmt.Errorf("%d: %w", v, errOdd)
+		}
+		return v, nil
+	}
+	items := func() <-chan int {
+		jobs := make(chan int)
+		go func() {
+			defer close(jobs)
+			for i := 0; i < 100; i++ {
+				jobs <- i
+			}
+		}()
+		return jobs
+	}
+	consume := func(results <-chan int, errs <-chan error) (int, []error) {
+		var n int
+		var collected []error
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for err := range errs {
+				collected = append(collected, err)
+			}
+		}()
+		for range results {
+			n++
+		}
+		wg.Wait()
+		return n, collected
+	}
+
+	t.Run("drop", func(t *testing.T) {
+		results, errs := NewPool(4, fn).RunE(context.Background(), items())
+		n, collected := consume(results, errs)
+		if n != 50 || len(collected) != 0 {
+			t.Errorf("Expected 50 results and no errors, got %d and %v", n, collected)
+		}
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		ctx := context.Background()
+		jobs := items()
+		results, errs := NewPool(1, fn, WithErrorPolicy(ErrorStop)).RunE(ctx, jobs)
+		n, collected := consume(results, errs)
+		if n != 1 {
+			t.Errorf("Expected only the job before the failure to succeed, got %d", n)
+		}
+		if len(collected) != 1 || !errors.Is(collected[0], errOdd) {
+			t.Errorf("Expected the stopping error, got %v", collected)
+		}
+		// Unblock the producer.
+		Drain(ctx, jobs)
+	})
+
+	t.Run("route", func(t *testing.T) {
+		results, errs := NewPool(4, fn, WithErrorPolicy(ErrorRoute)).RunE(context.Background(), items())
+		n, collected := consume(results, errs)
+		if n != 50 || len(collected) != 50 {
+			t.Errorf("Expected 50 results and 50 errors, got %d and %d", n, len(collected))
+		}
+		for _, err := range collected {
+			var jobErr *JobError[int]
+			if !errors.As(err, &jobErr) || jobErr.Input%2 == 0 || !errors.Is(err, errOdd) {
+				t.Errorf("Expected a JobError for an odd input, got %v", err)
+			}
+		}
+	})
+}
+
 // TestMapConcurrent tests the concurrent map functionality
 func TestMapConcurrent(t *testing.T) {
 	t.Run("basic functionality", func(t *testing.T) {
@@ -207,6 +570,83 @@ func TestMapConcurrent(t *testing.T) {
 	})
 }
 
+// TestMapConcurrentStream tests completion-order streaming of MapConcurrent results
+func TestMapConcurrentStream(t *testing.T) {
+	t.Run("completion order", func(t *testing.T) {
+		ctx := context.Background()
+		release := make(chan struct{})
+		boom := errors.New("boom")
+		in := []int{0, 1, 2, 3, 4}
+		results := MapConcurrentStream(ctx, in, 5, func(_ context.Context, v int) (int, error) {
+			switch v {
+			case 0:
+				<-release
+			case 3:
+				return 0, boom
+			}
+			return v * 10, nil
+		})
+
+		seen := make(map[int]IndexedResult[int])
+		for i := 0; i < 4; i++ {
+			r := <-results
+			if r.Index == 0 {
+				t.Fatal("Expected the slow element to complete last")
+			}
+			seen[r.Index] = r
+		}
+		close(release)
+		r, ok := <-results
+		if !ok || r.Index != 0 || r.Value != 0 {
+			t.Errorf("Expected index 0 last, got %+v", r)
+		}
+		if _, ok := <-results; ok {
+			t.Error("Expected the output to be closed")
+		}
+
+		if seen[2].Value != 20 {
+			t.Errorf("Expected 20 at index 2, got %d", seen[2].Value)
+		}
+		if seen[3].Err != boom {
+			t.Errorf("Expected boom at index 3, got %v", seen[3].Err)
+		}
+	})
+
+	t.Run("bounded concurrency", func(t *testing.T) {
+		ctx := context.Background()
+		var running, peak atomic.Int32
+		in := make([]int, 20)
+		results := MapConcurrentStream(ctx, in, 3, func(_ context.Context, v int) (int, error) {
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			running.Add(-1)
+			return v, nil
+		})
+		if got := len(Collect(ctx, results, 0)); got != 20 {
+			t.Errorf("Expected 20 results, got %d", got)
+		}
+		if p := peak.Load(); p > 3 {
+			t.Errorf("Expected at most 3 concurrent calls, got %d", p)
+		}
+	})
+
+	t.Run("cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		results := MapConcurrentStream(ctx, make([]int, 100), 2, func(ctx context.Context, v int) (int, error) {
+			return v, nil
+		})
+		<-results
+		cancel()
+		Drain(context.Background(), results)
+	})
+}
+
 // TestLegacyPipeline tests the legacy pipeline functionality
 func TestLegacyPipeline(t *testing.T) {
 	t.Run("basic pipeline", func(t *testing.T) {
@@ -378,3 +818,470 @@ func TestMemoryLeaks(t *testing.T) {
 		time.Sleep(10 * time.Millisecond)
 	})
 }
+
+func TestPoolRunWeighted(t *testing.T) {
+	ctx := context.Background()
+	a, b := make(chan int, 10), make(chan int, 10)
+	for i := range 10 {
+		a <- i
+		b <- i
+	}
+	close(a)
+	close(b)
+	pool := NewPool(2, func(_ context.Context, v int) (int, error) { return v, nil })
+	results := Collect(ctx, pool.RunWeighted(ctx,
+		WeightedSource[int]{Name: "a", Input: a, Weight: 2},
+		WeightedSource[int]{Name: "b", Input: b},
+	), 0)
+	if len(results) != 20 {
+		t.Errorf("Expected 20 results, got %d", len(results))
+	}
+}
+
+func TestPoolLockOSThread(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("init per worker", func(t *testing.T) {
+		var inits atomic.Int32
+		pool := NewPool(3, func(_ context.Context, v int) (int, error) {
+			return v, nil
+		}, WithLockOSThread(func(_ context.Context, worker int) error {
+			inits.Add(1)
+			return nil
+		}))
+		if results := pool.RunSlice(ctx, []int{1, 2, 3, 4}); len(results) != 4 {
+			t.Errorf("Expected 4 results, got %v", results)
+		}
+		if n := inits.Load(); n != 3 {
+			t.Errorf("Expected 3 worker inits, got %d", n)
+		}
+	})
+
+	t.Run("init failure stops the pool", func(t *testing.T) {
+		boom := errors.New("no device")
+		pool := NewPool(2, func(_ context.Context, v int) (int, error) {
+			return v, nil
+		}, WithLockOSThread(func(_ context.Context, worker int) error {
+			return boom
+		}))
+		results, errs := pool.RunE(ctx, make(chan int))
+		for range results {
+		}
+		if err := <-errs; !errors.Is(err, boom) {
+			t.Errorf("Expected the init error, got %v", err)
+		}
+	})
+
+	t.Run("init failure does not outlive a cancelled run", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		pool := NewPool(2, func(_ context.Context, v int) (int, error) {
+			return 0, errors.New("job failed")
+		}, WithErrorPolicy(ErrorRoute), WithLockOSThread(func(_ context.Context, worker int) error {
+			if worker == 0 {
+				return nil
+			}
+			// Fail once worker 0 has filled the unread error channel.
+			time.Sleep(20 * time.Millisecond)
+			return errors.New("no device")
+		}))
+		jobs := make(chan int, 4)
+		for i := range 4 {
+			jobs <- i
+		}
+		results, _ := pool.RunE(ctx, jobs)
+		time.Sleep(40 * time.Millisecond)
+		cancel()
+		done := make(chan struct{})
+		go func() {
+			for range results {
+			}
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("Expected the run to finish once cancelled")
+		}
+	})
+}
+
+func TestPoolSubmit(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("results and errors", func(t *testing.T) {
+		boom := errors.New("boom")
+		pool := NewPool(2, func(_ context.Context, v int) (int, error) {
+			if v < 0 {
+				return 0, boom
+			}
+			return v * 2, nil
+		})
+		if v, err := pool.Submit(ctx, 21).Get(ctx); v != 42 || err != nil {
+			t.Errorf("Expected 42, got %d, %v", v, err)
+		}
+		if _, err := pool.Submit(ctx, -1).Get(ctx); !errors.Is(err, boom) {
+			t.Errorf("Expected boom, got %v", err)
+		}
+	})
+
+	t.Run("bounded by workers", func(t *testing.T) {
+		var active, peak atomic.Int32
+		pool := NewPool(3, func(ctx context.Context, v int) (int, error) {
+			if _, ok := WorkerID(ctx); !ok {
+				t.Error("Expected a worker ID")
+			}
+			n := active.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			active.Add(-1)
+			return v, nil
+		})
+		futures := make([]*Future[int], 20)
+		for i := range futures {
+			futures[i] = pool.Submit(ctx, i)
+		}
+		for i, f := range futures {
+			<-f.Done()
+			if v, err := f.Get(ctx); v != i || err != nil {
+				t.Errorf("Expected %d, got %d, %v", i, v, err)
+			}
+		}
+		if peak.Load() > 3 {
+			t.Errorf("Expected at most 3 concurrent jobs, got %d", peak.Load())
+		}
+	})
+
+	t.Run("cancelled while waiting", func(t *testing.T) {
+		started, release := make(chan struct{}), make(chan struct{})
+		pool := NewPool(1, func(_ context.Context, v int) (int, error) {
+			close(started)
+			<-release
+			return v, nil
+		})
+		first := pool.Submit(ctx, 1)
+		<-started
+		waitCtx, cancel := context.WithCancel(ctx)
+		second := pool.Submit(waitCtx, 2)
+		cancel()
+		if _, err := second.Get(ctx); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+		close(release)
+		if v, err := first.Get(ctx); v != 1 || err != nil {
+			t.Errorf("Expected 1, got %d, %v", v, err)
+		}
+	})
+
+	t.Run("duplicate", func(t *testing.T) {
+		pool := NewPool(1, func(_ context.Context, v int) (int, error) {
+			return v, nil
+		}, WithIdempotency(NewMemoryIdempotencyStore(nil), func(v any) string { return fmt.Sprint(v) }, time.Minute))
+		pool.Submit(ctx, 1).Get(ctx)
+		if _, err := pool.Submit(ctx, 1).Get(ctx); !errors.Is(err, ErrDuplicateJob) {
+			t.Errorf("Expected ErrDuplicateJob, got %v", err)
+		}
+	})
+}
+
+func TestPoolSetWorkers(t *testing.T) {
+	// busy runs jobs that block until released and reports the peak number
+	// running at once.
+	type busy struct {
+		active, peak atomic.Int32
+		started      chan struct{}
+		release      chan struct{}
+	}
+	newBusy := func() *busy {
+		return &busy{started: make(chan struct{}, 100), release: make(chan struct{})}
+	}
+	fn := func(b *busy) func(context.Context, int) (int, error) {
+		return func(_ context.Context, v int) (int, error) {
+			n := b.active.Add(1)
+			for {
+				p := b.peak.Load()
+				if n <= p || b.peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			b.started <- struct{}{}
+			<-b.release
+			b.active.Add(-1)
+			return v, nil
+		}
+	}
+
+	t.Run("grow running pool", func(t *testing.T) {
+		b := newBusy()
+		pool := NewPool(1, fn(b))
+		jobs := make(chan int, 10)
+		for i := range 10 {
+			jobs <- i
+		}
+		close(jobs)
+		results := pool.Run(context.Background(), jobs)
+		<-b.started
+		pool.SetWorkers(4)
+		for range 3 {
+			select {
+			case <-b.started:
+			case <-time.After(time.Second):
+				t.Fatal("Expected new workers to pick up jobs")
+			}
+		}
+		close(b.release)
+		if n := len(Collect(context.Background(), results, 0)); n != 10 {
+			t.Errorf("Expected 10 results, got %d", n)
+		}
+		if pool.Workers() != 4 || b.peak.Load() != 4 {
+			t.Errorf("Expected 4 workers running at once, got %d of %d", b.peak.Load(), pool.Workers())
+		}
+	})
+
+	t.Run("shrink running pool", func(t *testing.T) {
+		b := newBusy()
+		pool := NewPool(4, fn(b))
+		jobs := make(chan int)
+		results := pool.Run(context.Background(), jobs)
+		pool.Resize(1)
+		b.peak.Store(0)
+		go func() {
+			defer close(jobs)
+			for i := range 10 {
+				jobs <- i
+			}
+		}()
+		go func() {
+			for range b.started {
+				b.release <- struct{}{}
+			}
+		}()
+		if n := len(Collect(context.Background(), results, 0)); n != 10 {
+			t.Errorf("Expected 10 results, got %d", n)
+		}
+		close(b.started)
+		if b.peak.Load() != 1 {
+			t.Errorf("Expected 1 job at a time after shrinking, got %d", b.peak.Load())
+		}
+	})
+
+	t.Run("submit", func(t *testing.T) {
+		b := newBusy()
+		pool := NewPool(1, fn(b))
+		pool.SetWorkers(3)
+		futures := make([]*Future[int], 3)
+		for i := range futures {
+			futures[i] = pool.Submit(context.Background(), i)
+		}
+		for range 3 {
+			<-b.started
+		}
+		close(b.release)
+		for _, f := range futures {
+			f.Get(context.Background())
+		}
+		if b.peak.Load() != 3 {
+			t.Errorf("Expected 3 submitted jobs at once, got %d", b.peak.Load())
+		}
+	})
+}
+
+func TestPoolPanicRecovery(t *testing.T) {
+	fn := func(_ context.Context, v int) (int, error) {
+		if v == 3 {
+			panic("boom")
+		}
+		return v, nil
+	}
+	jobs := func() <-chan int {
+		return sliceChan(1, 2, 3, 4, 5)
+	}
+
+	t.Run("worker survives", func(t *testing.T) {
+		var recovered []any
+		var items []any
+		pool := NewPool(1, fn, WithPanicRecovery(func(v any, item any) {
+			recovered = append(recovered, v)
+			items = append(items, item)
+		}))
+		results := Collect(context.Background(), pool.Run(context.Background(), jobs()), 0)
+		if len(results) != 4 {
+			t.Errorf("Expected 4 results, got %v", results)
+		}
+		if len(recovered) != 1 || recovered[0] != "boom" || items[0] != 3 {
+			t.Errorf("Expected handler called with boom and 3, got %v and %v", recovered, items)
+		}
+	})
+
+	t.Run("routed as error", func(t *testing.T) {
+		pool := NewPool(2, fn, WithPanicRecovery(nil), WithErrorPolicy(ErrorRoute))
+		results, errs := pool.RunE(context.Background(), jobs())
+		go Drain(context.Background(), results)
+		var collected []error
+		for err := range errs {
+			collected = append(collected, err)
+		}
+		var panicErr *PanicError
+		var jobErr *JobError[int]
+		if len(collected) != 1 || !errors.As(collected[0], &panicErr) || !errors.As(collected[0], &jobErr) || jobErr.Input != 3 {
+			t.Errorf("Expected a JobError wrapping a PanicError for 3, got %v", collected)
+		}
+	})
+
+	t.Run("submit", func(t *testing.T) {
+		pool := NewPool(1, fn, WithPanicRecovery(nil))
+		var panicErr *PanicError
+		if _, err := pool.Submit(context.Background(), 3).Get(context.Background()); !errors.As(err, &panicErr) {
+			t.Errorf("Expected a PanicError, got %v", err)
+		}
+		if v, err := pool.Submit(context.Background(), 4).Get(context.Background()); v != 4 || err != nil {
+			t.Errorf("Expected 4, got %d, %v", v, err)
+		}
+	})
+}
+
+func TestPoolShutdown(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("finishes in-flight jobs", func(t *testing.T) {
+		started, release := make(chan struct{}, 10), make(chan struct{})
+		pool := NewPool(2, func(_ context.Context, v int) (int, error) {
+			started <- struct{}{}
+			<-release
+			return v, nil
+		})
+		jobs := make(chan int, 10)
+		for i := range 10 {
+			jobs <- i
+		}
+		results := pool.Run(ctx, jobs)
+		<-started
+		<-started
+		shutdown := make(chan error, 1)
+		go func() { shutdown <- pool.Shutdown(ctx) }()
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+		if n := len(Collect(ctx, results, 0)); n != 2 {
+			t.Errorf("Expected the 2 in-flight results, got %d", n)
+		}
+		if err := <-shutdown; err != nil {
+			t.Errorf("Expected clean shutdown, got %v", err)
+		}
+		if len(jobs) != 8 {
+			t.Errorf("Expected 8 jobs left unread, got %d", len(jobs))
+		}
+	})
+
+	t.Run("bounded by ctx", func(t *testing.T) {
+		started := make(chan struct{})
+		pool := NewPool(1, func(ctx context.Context, v int) (int, error) {
+			close(started)
+			<-ctx.Done()
+			return 0, ctx.Err()
+		})
+		results := pool.Run(ctx, sliceChan(1))
+		<-started
+		shutdownCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+		if err := pool.Shutdown(shutdownCtx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected DeadlineExceeded, got %v", err)
+		}
+		Drain(ctx, results)
+	})
+
+	t.Run("rejects new work", func(t *testing.T) {
+		pool := NewPool(1, func(_ context.Context, v int) (int, error) {
+			return v, nil
+		})
+		if err := pool.Shutdown(ctx); err != nil {
+			t.Fatalf("Expected clean shutdown, got %v", err)
+		}
+		if _, err := pool.Submit(ctx, 1).Get(ctx); !errors.Is(err, ErrPoolClosed) {
+			t.Errorf("Expected ErrPoolClosed, got %v", err)
+		}
+		jobs := make(chan int, 1)
+		jobs <- 1
+		if results := Collect(ctx, pool.Run(ctx, jobs), 0); len(results) != 0 || len(jobs) != 1 {
+			t.Errorf("Expected a closed results channel and the job unread, got %v", results)
+		}
+	})
+}
+
+func TestPoolWithOptions(t *testing.T) {
+	ctx := context.Background()
+	identity := func(_ context.Context, v int) (int, error) { return v, nil }
+
+	t.Run("workers", func(t *testing.T) {
+		if n := NewPoolWithOptions(identity, WithWorkers(3)).Workers(); n != 3 {
+			t.Errorf("Expected 3 workers, got %d", n)
+		}
+		if n := NewPoolWithOptions(identity).Workers(); n != DefaultPoolOptions().Workers {
+			t.Errorf("Expected the default worker count, got %d", n)
+		}
+	})
+
+	t.Run("buffer size", func(t *testing.T) {
+		results := NewPoolWithOptions(identity, WithWorkers(2), WithBufferSize(4)).Run(ctx, sliceChan(1, 2, 3, 4))
+		deadline := time.Now().Add(time.Second)
+		for len(results) < 4 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		if n := len(results); n != 4 {
+			t.Errorf("Expected 4 buffered results, got %d", n)
+		}
+		Drain(ctx, results)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		pool := NewPoolWithOptions(func(ctx context.Context, v int) (int, error) {
+			<-ctx.Done()
+			return 0, ctx.Err()
+		}, WithWorkers(1), WithTimeout(10*time.Millisecond), WithErrorPolicy(ErrorRoute))
+		results, errs := pool.RunE(ctx, sliceChan(1))
+		go Drain(ctx, results)
+		if err := <-errs; !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected DeadlineExceeded, got %v", err)
+		}
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		var attempts atomic.Int32
+		pool := NewPoolWithOptions(func(_ context.Context, v int) (int, error) {
+			if attempts.Add(1)%3 != 0 {
+				return 0, errors.New("transient")
+			}
+			return v, nil
+		}, WithWorkers(1), WithRetryConfig(2, time.Millisecond))
+		if results := pool.RunSlice(ctx, []int{1, 2}); len(results) != 2 {
+			t.Errorf("Expected 2 results after retries, got %v", results)
+		}
+		if n := attempts.Load(); n != 6 {
+			t.Errorf("Expected 6 attempts, got %d", n)
+		}
+
+		attempts.Store(0)
+		pool = NewPoolWithOptions(func(_ context.Context, v int) (int, error) {
+			attempts.Add(1)
+			return 0, NewRetryableError(errors.New("permanent"), false)
+		}, WithWorkers(1), WithRetryConfig(2, time.Millisecond))
+		pool.RunSlice(ctx, []int{1})
+		if n := attempts.Load(); n != 1 {
+			t.Errorf("Expected 1 attempt for a permanent error, got %d", n)
+		}
+	})
+
+	t.Run("rate limit", func(t *testing.T) {
+		pool := NewPoolWithOptions(identity, WithWorkers(4), WithRateLimit(5, 50*time.Millisecond, 5))
+		start := time.Now()
+		if results := pool.RunSlice(ctx, make([]int, 10)); len(results) != 10 {
+			t.Errorf("Expected 10 results, got %d", len(results))
+		}
+		if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+			t.Errorf("Expected the second 5 jobs to wait for a refill, took %v", elapsed)
+		}
+	})
+}
@@ -0,0 +1,76 @@
+// Package concurrenttest provides test helpers for code built on channels,
+// wrapping the select-with-timeout patterns such tests otherwise repeat.
+//
+//	out := stage(ctx, input)
+//	concurrenttest.AssertReceives(t, out, 42, time.Second)
+//	cancel()
+//	concurrenttest.AssertClosedWithin(t, out, time.Second)
+package concurrenttest
+
+import (
+	"testing"
+	"time"
+)
+
+// Receive returns the next value from ch. It fails the test immediately if
+// ch is closed or nothing arrives within d.
+func Receive[T any](t testing.TB, ch <-chan T, d time.Duration) T {
+	t.Helper()
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case v, ok := <-ch:
+		if !ok {
+			t.Fatalf("Expected a value, channel was closed")
+		}
+		return v
+	case <-timer.C:
+		t.Fatalf("Expected a value within %v, got none", d)
+	}
+	panic("unreachable")
+}
+
+// AssertReceives fails the test immediately unless the next value from ch
+// arrives within d and equals want.
+func AssertReceives[T comparable](t testing.TB, ch <-chan T, want T, d time.Duration) {
+	t.Helper()
+	if got := Receive(t, ch, d); got != want {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+}
+
+// AssertClosedWithin fails the test immediately unless ch is closed within d.
+// Values still in flight are discarded; their number is returned.
+func AssertClosedWithin[T any](t testing.TB, ch <-chan T, d time.Duration) int {
+	t.Helper()
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	n := 0
+	for {
+		select {
+		case _, ok := <-ch:
+			if !ok {
+				return n
+			}
+			n++
+		case <-timer.C:
+			t.Fatalf("Expected channel to be closed within %v", d)
+		}
+	}
+}
+
+// AssertNoReceive fails the test if ch yields a value or is closed within d.
+func AssertNoReceive[T any](t testing.TB, ch <-chan T, d time.Duration) {
+	t.Helper()
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case v, ok := <-ch:
+		if ok {
+			t.Errorf("Expected no value within %v, got %v", d, v)
+		} else {
+			t.Errorf("Expected no value within %v, channel was closed", d)
+		}
+	case <-timer.C:
+	}
+}
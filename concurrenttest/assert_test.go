@@ -0,0 +1,86 @@
+package concurrenttest
+
+import (
+	"testing"
+	"time"
+)
+
+// recorder captures failures instead of stopping the test.
+type recorder struct {
+	testing.TB
+	failed bool
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(string, ...any) { r.failed = true }
+
+// Fatalf mimics testing.T by stopping the calling goroutine.
+func (r *recorder) Fatalf(string, ...any) {
+	r.failed = true
+	panic(r)
+}
+
+// check runs fn against a recorder and reports whether it failed.
+func check(fn func(t testing.TB)) (failed bool) {
+	r := &recorder{}
+	defer func() {
+		if v := recover(); v != nil && v != r {
+			panic(v)
+		}
+		failed = r.failed
+	}()
+	fn(r)
+	return
+}
+
+func TestAssertReceives(t *testing.T) {
+	ch := make(chan int, 1)
+	ch <- 1
+	if check(func(tb testing.TB) { AssertReceives(tb, ch, 1, time.Second) }) {
+		t.Error("Expected matching value to pass")
+	}
+
+	ch <- 2
+	if !check(func(tb testing.TB) { AssertReceives(tb, ch, 1, time.Second) }) {
+		t.Error("Expected mismatched value to fail")
+	}
+	if !check(func(tb testing.TB) { AssertReceives(tb, ch, 1, 10*time.Millisecond) }) {
+		t.Error("Expected timeout to fail")
+	}
+
+	close(ch)
+	if !check(func(tb testing.TB) { Receive(tb, ch, time.Second) }) {
+		t.Error("Expected closed channel to fail")
+	}
+}
+
+func TestAssertClosedWithin(t *testing.T) {
+	ch := make(chan int, 2)
+	ch <- 1
+	ch <- 2
+	close(ch)
+	var n int
+	if check(func(tb testing.TB) { n = AssertClosedWithin(tb, ch, time.Second) }) {
+		t.Error("Expected closed channel to pass")
+	}
+	if n != 2 {
+		t.Errorf("Expected 2 discarded values, got %d", n)
+	}
+
+	open := make(chan int)
+	if !check(func(tb testing.TB) { AssertClosedWithin(tb, open, 10*time.Millisecond) }) {
+		t.Error("Expected open channel to fail")
+	}
+}
+
+func TestAssertNoReceive(t *testing.T) {
+	ch := make(chan int, 1)
+	if check(func(tb testing.TB) { AssertNoReceive(tb, ch, 10*time.Millisecond) }) {
+		t.Error("Expected quiet channel to pass")
+	}
+	ch <- 1
+	if !check(func(tb testing.TB) { AssertNoReceive(tb, ch, time.Second) }) {
+		t.Error("Expected value to fail")
+	}
+}
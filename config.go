@@ -2,17 +2,41 @@ package concurrent
 
 import (
 	"context"
+	"io"
+	"runtime"
 	"time"
 )
 
 // PoolOptions holds configuration options for worker pools.
 type PoolOptions struct {
-	Workers    int
-	BufferSize int
-	Timeout    time.Duration
-	RetryCount int
-	Backoff    time.Duration
-	RateLimit  *RateLimitOptions
+	Workers     int
+	BufferSize  int
+	Timeout     time.Duration
+	RetryCount  int
+	Backoff     time.Duration
+	RateLimit   *RateLimitOptions
+	Name        string
+	Observer    Observer
+	Tracer      Tracer
+	SpanName    func(item any) string
+	SpanAttrs   func(item any) SpanAttributes
+	Logger      Logger
+	Metrics     *Metrics
+	Registry    *Registry
+	Chaos       *ChaosConfig
+	DedupKey    func(item any) any
+	DedupTTL    time.Duration
+	Idempotency *IdempotencyOptions
+	Quota       *QuotaManager
+	QuotaNames  []string
+	AutoTune    *AutoTuner
+	LockThread  bool
+	WorkerInit  func(ctx context.Context, worker int) error
+	Recover     bool
+	OnPanic     PanicHandler
+	Affinity    func(item any) any
+	Drain       DrainPolicy
+	ErrorPolicy ErrorPolicy
 }
 
 // RateLimitOptions holds configuration for rate limiting.
@@ -41,28 +65,33 @@ func DefaultPoolOptions() PoolOptions {
 // PoolOption is a function that configures a PoolOptions.
 type PoolOption func(*PoolOptions)
 
-// WithWorkers sets the number of workers.
+// WithWorkers sets the number of workers of a pool created with
+// NewPoolWithOptions. NewPool takes it as an argument instead.
 func WithWorkers(workers int) PoolOption {
 	return func(opts *PoolOptions) {
 		opts.Workers = workers
 	}
 }
 
-// WithBufferSize sets the buffer size for channels.
+// WithBufferSize sets the buffer size of a pool's results channel, letting
+// workers run ahead of a slow consumer.
 func WithBufferSize(size int) PoolOption {
 	return func(opts *PoolOptions) {
 		opts.BufferSize = size
 	}
 }
 
-// WithTimeout sets the timeout for operations.
+// WithTimeout bounds each attempt at a pool job: the context passed to fn
+// is canceled after timeout.
 func WithTimeout(timeout time.Duration) PoolOption {
 	return func(opts *PoolOptions) {
 		opts.Timeout = timeout
 	}
 }
 
-// WithRetryConfig sets the retry configuration.
+// WithRetryConfig retries a failed pool job up to count more times, with
+// exponential backoff starting at backoff. Errors marked as not retryable
+// with NewRetryableError fail at once. See Retry.
 func WithRetryConfig(count int, backoff time.Duration) PoolOption {
 	return func(opts *PoolOptions) {
 		opts.RetryCount = count
@@ -70,7 +99,9 @@ func WithRetryConfig(count int, backoff time.Duration) PoolOption {
 	}
 }
 
-// WithRateLimit sets the rate limiting configuration.
+// WithRateLimit limits a pool to starting limit attempts per interval,
+// allowing bursts of up to burst (limit if burst is not positive), shared
+// by all its workers.
 func WithRateLimit(limit int, interval time.Duration, burst int) PoolOption {
 	return func(opts *PoolOptions) {
 		opts.RateLimit = &RateLimitOptions{
@@ -81,58 +112,492 @@ func WithRateLimit(limit int, interval time.Duration, burst int) PoolOption {
 	}
 }
 
-// Metrics holds performance metrics for concurrent operations.
-type Metrics struct {
-	ProcessedCount int64
-	ErrorCount     int64
-	Duration       time.Duration
-	StartTime      time.Time
-	EndTime        time.Time
+// WithName sets the component name reported to observers.
+func WithName(name string) PoolOption {
+	return func(opts *PoolOptions) {
+		opts.Name = name
+	}
+}
+
+// WithObserver sets the observer that receives the pool's instrumentation events.
+func WithObserver(o Observer) PoolOption {
+	return func(opts *PoolOptions) {
+		opts.Observer = o
+	}
+}
+
+// WithTracer starts a span for every job the pool processes. The job's
+// context is the span's context, so calls made by the processing function are
+// linked to it. Spans are named "<name>.job" unless WithSpanName is used.
+func WithTracer(t Tracer) PoolOption {
+	return func(opts *PoolOptions) {
+		opts.Tracer = t
+	}
+}
+
+// WithSpanName derives each job span's name from the job.
+func WithSpanName(fn func(item any) string) PoolOption {
+	return func(opts *PoolOptions) {
+		opts.SpanName = fn
+	}
+}
+
+// WithSpanAttributes derives each job span's attributes from the job.
+func WithSpanAttributes(fn func(item any) SpanAttributes) PoolOption {
+	return func(opts *PoolOptions) {
+		opts.SpanAttrs = fn
+	}
+}
+
+// WithLogger sets the logger for worker lifecycle and dropped job events.
+func WithLogger(l Logger) PoolOption {
+	return func(opts *PoolOptions) {
+		opts.Logger = l
+	}
+}
+
+// WithMetrics records the outcome and latency of every job in m.
+// m.Finish is called when the pool's results channel closes.
+func WithMetrics(m *Metrics) PoolOption {
+	return func(opts *PoolOptions) {
+		opts.Metrics = m
+	}
+}
+
+// WithRegistry registers the pool in reg under its name.
+func WithRegistry(reg *Registry) PoolOption {
+	return func(opts *PoolOptions) {
+		opts.Registry = reg
+	}
+}
+
+// WithChaos injects random latency, errors and panics into the pool's jobs.
+// See ChaosConfig. Injected panics are not recovered.
+func WithChaos(config ChaosConfig) PoolOption {
+	return func(opts *PoolOptions) {
+		opts.Chaos = &config
+	}
+}
+
+// WithDedup coalesces jobs with the same key: a job arriving while another
+// with its key is queued or running is not processed, and its result is a
+// copy of the first job's. Successful results are also reused for jobs
+// arriving up to ttl after the first one finished; a ttl of zero only
+// coalesces jobs in flight. Failed jobs are not reused.
+func WithDedup(keyFn func(item any) any, ttl time.Duration) PoolOption {
+	return func(opts *PoolOptions) {
+		opts.DedupKey = keyFn
+		opts.DedupTTL = ttl
+	}
+}
+
+// WithQuota makes every job acquire the named budgets from q before it
+// runs, holding them until it finishes, so the pool shares global limits
+// with other components. A job that cannot acquire them fails with the
+// context's error.
+func WithQuota(q *QuotaManager, names ...string) PoolOption {
+	return func(opts *PoolOptions) {
+		opts.Quota = q
+		opts.QuotaNames = names
+	}
+}
+
+// WithAutoTune runs at most a's current limit of jobs at once, letting a
+// adjust the pool's effective concurrency up to its worker count. The
+// caller runs a's control loop with AutoTuner.Run.
+func WithAutoTune(a *AutoTuner) PoolOption {
+	return func(opts *PoolOptions) {
+		opts.AutoTune = a
+	}
 }
 
-// NewMetrics creates a new metrics instance.
-func NewMetrics() *Metrics {
-	return &Metrics{
-		StartTime: time.Now(),
+// WithLockOSThread runs each worker on its own OS thread, locked with
+// runtime.LockOSThread for the worker's lifetime, for jobs calling cgo or
+// FFI libraries that demand thread affinity. If init is not nil, each
+// worker calls it on its thread before taking jobs, to set up thread-local
+// library state; an error stops the pool and is reported like a job error
+// under ErrorStop.
+func WithLockOSThread(init func(ctx context.Context, worker int) error) PoolOption {
+	return func(opts *PoolOptions) {
+		opts.LockThread = true
+		opts.WorkerInit = init
+	}
+}
+
+// IdempotencyOptions configures a Pool's use of an IdempotencyStore.
+type IdempotencyOptions struct {
+	Store IdempotencyStore
+	Key   func(item any) string
+	TTL   time.Duration
+}
+
+// WithIdempotency skips jobs whose key is already recorded in store,
+// recording the key of every job the pool runs for ttl. A job that fails
+// has its key deleted so it can be retried; one whose key cannot be
+// checked fails with the store's error.
+func WithIdempotency(store IdempotencyStore, keyFn func(item any) string, ttl time.Duration) PoolOption {
+	return func(opts *PoolOptions) {
+		opts.Idempotency = &IdempotencyOptions{Store: store, Key: keyFn, TTL: ttl}
 	}
 }
 
-// RecordSuccess records a successful operation.
-func (m *Metrics) RecordSuccess() {
-	m.ProcessedCount++
+// PanicHandler is called with the value recovered from a job that
+// panicked and the job's input.
+type PanicHandler func(recovered any, item any)
+
+// WithPanicRecovery recovers a job that panics and fails it with a
+// *PanicError instead, which the pool's ErrorPolicy handles like any other
+// error; the worker carries on with the next job. If handler is not nil, it
+// is called for every recovered panic.
+func WithPanicRecovery(handler PanicHandler) PoolOption {
+	return func(opts *PoolOptions) {
+		opts.Recover = true
+		opts.OnPanic = handler
+	}
 }
 
-// RecordError records a failed operation.
-func (m *Metrics) RecordError() {
-	m.ErrorCount++
+// WithAffinity routes every job to a worker chosen by hashing its key, so
+// jobs with the same key always run on the same worker, in the order they
+// arrived. A busy worker holds up jobs for other keys behind it.
+func WithAffinity(keyFn func(item any) any) PoolOption {
+	return func(opts *PoolOptions) {
+		opts.Affinity = keyFn
+	}
 }
 
-// Finish marks the end of the operation and calculates duration.
-func (m *Metrics) Finish() {
-	m.EndTime = time.Now()
-	m.Duration = m.EndTime.Sub(m.StartTime)
+// DrainPolicy decides which accepted jobs a Pool still processes after its
+// context is canceled.
+type DrainPolicy int
+
+const (
+	// DrainNone abandons jobs as soon as the context is canceled.
+	DrainNone DrainPolicy = iota
+	// DrainInFlight finishes the jobs workers are running and delivers their
+	// results.
+	DrainInFlight
+	// DrainBuffered also processes every job already buffered in the jobs
+	// channel.
+	DrainBuffered
+)
+
+// WithDrainOnCancel sets what the pool finishes after its context is
+// canceled. Draining jobs run with a context that is not canceled, and
+// their results are delivered even though the context is done, so the
+// caller must keep consuming results until the channel is closed.
+func WithDrainOnCancel(policy DrainPolicy) PoolOption {
+	return func(opts *PoolOptions) {
+		opts.Drain = policy
+	}
 }
 
-// SuccessRate returns the success rate as a percentage.
-func (m *Metrics) SuccessRate() float64 {
-	total := m.ProcessedCount + m.ErrorCount
-	if total == 0 {
-		return 0
+// ErrorPolicy decides what a Pool does with a job that fails.
+type ErrorPolicy int
+
+const (
+	// ErrorDrop drops the failed job's result and carries on.
+	ErrorDrop ErrorPolicy = iota
+	// ErrorStop cancels the run on the first failure, errgroup-style, with
+	// the error as the cancellation cause.
+	ErrorStop
+	// ErrorRoute sends every error to the error channel returned by
+	// Pool.RunE. Run drops them.
+	ErrorRoute
+)
+
+// WithErrorPolicy sets how the pool handles failed jobs.
+func WithErrorPolicy(policy ErrorPolicy) PoolOption {
+	return func(opts *PoolOptions) {
+		opts.ErrorPolicy = policy
 	}
-	return float64(m.ProcessedCount) / float64(total) * 100
 }
 
-// Throughput returns the operations per second.
-func (m *Metrics) Throughput() float64 {
-	if m.Duration == 0 {
-		return 0
+// PipelineOptions holds configuration options for pipelines.
+type PipelineOptions struct {
+	Name         string
+	Observer     Observer
+	Logger       Logger
+	Metrics      *Metrics
+	Registry     *Registry
+	TrackLatency bool
+	LatencyKey   func(item any) any
+	AckMode      bool
+}
+
+// PipelineOption is a function that configures a PipelineOptions.
+type PipelineOption func(*PipelineOptions)
+
+// WithPipelineName sets the pipeline name reported to observers.
+// Stages are reported as "<name>/stage-<index>".
+func WithPipelineName(name string) PipelineOption {
+	return func(opts *PipelineOptions) {
+		opts.Name = name
 	}
-	return float64(m.ProcessedCount) / m.Duration.Seconds()
 }
 
-// ErrorRate returns the error rate as a percentage.
-func (m *Metrics) ErrorRate() float64 {
-	return 100 - m.SuccessRate()
+// WithPipelineObserver sets the observer that receives an EventEmitted for
+// every item leaving each stage.
+func WithPipelineObserver(o Observer) PipelineOption {
+	return func(opts *PipelineOptions) {
+		opts.Observer = o
+	}
+}
+
+// WithPipelineLogger sets the logger for pipeline lifecycle events.
+func WithPipelineLogger(l Logger) PipelineOption {
+	return func(opts *PipelineOptions) {
+		opts.Logger = l
+	}
+}
+
+// WithPipelineMetrics counts every item leaving the pipeline as a success in m.
+// m.Finish is called when the pipeline's output channel closes.
+func WithPipelineMetrics(m *Metrics) PipelineOption {
+	return func(opts *PipelineOptions) {
+		opts.Metrics = m
+	}
+}
+
+// WithPipelineRegistry registers the pipeline in reg under its name.
+// Registered pipelines count the items emitted by each stage.
+func WithPipelineRegistry(reg *Registry) PipelineOption {
+	return func(opts *PipelineOptions) {
+		opts.Registry = reg
+	}
+}
+
+// StageOptions holds configuration options for Pipeline.AddStage.
+type StageOptions struct {
+	Parallelism   int
+	PreserveOrder bool
+}
+
+// StageOption is a function that configures a StageOptions.
+type StageOption func(*StageOptions)
+
+// WithParallelism runs n copies of the stage, all reading from the same
+// input, and merges their outputs.
+func WithParallelism(n int) StageOption {
+	return func(opts *StageOptions) {
+		opts.Parallelism = n
+	}
+}
+
+// WithPreserveOrder makes a parallel stage emit items in input order. The
+// stage must emit exactly one item for every item it receives; a stage that
+// drops or adds items will stall or reorder the output.
+func WithPreserveOrder() StageOption {
+	return func(opts *StageOptions) {
+		opts.PreserveOrder = true
+	}
+}
+
+// FanOptions holds configuration options for FanOutFanIn, RoundRobin and
+// StickyRoundRobin.
+type FanOptions struct {
+	Name         string
+	WorkerBuffer int
+	Observer     Observer
+	Registry     *Registry
+}
+
+// FanOption is a function that configures a FanOptions.
+type FanOption func(*FanOptions)
+
+// WithWorkerBuffer sets the buffer size of each worker's input channel, so a
+// slow item on one worker doesn't hold up the distributor.
+func WithWorkerBuffer(size int) FanOption {
+	return func(opts *FanOptions) {
+		opts.WorkerBuffer = size
+	}
+}
+
+// WithFanName sets the name reported to observers and the registry.
+// Workers are reported as "<name>/worker-<index>".
+func WithFanName(name string) FanOption {
+	return func(opts *FanOptions) {
+		opts.Name = name
+	}
+}
+
+// WithFanObserver sets the observer that receives an EventQueueDepth for a
+// worker every time an item is queued for it.
+func WithFanObserver(o Observer) FanOption {
+	return func(opts *FanOptions) {
+		opts.Observer = o
+	}
+}
+
+// WithFanRegistry registers the fan-out in reg under its name, reporting
+// each worker's queue depth.
+func WithFanRegistry(reg *Registry) FanOption {
+	return func(opts *FanOptions) {
+		opts.Registry = reg
+	}
+}
+
+// WithEndToEndLatency records the time from each item entering the pipeline
+// to it leaving as a latency in the pipeline's Metrics, creating one if
+// WithPipelineMetrics was not given. Items are matched up in order, which is
+// exact when every stage emits one item per input in input order; use
+// WithLatencyKey for pipelines that drop, add or reorder items.
+func WithEndToEndLatency() PipelineOption {
+	return func(opts *PipelineOptions) {
+		opts.TrackLatency = true
+	}
+}
+
+// WithLatencyKey enables end-to-end latency tracking, matching items leaving
+// the pipeline to the item that entered with the same key. The key must be
+// comparable and survive every stage. Items that never leave the pipeline
+// keep their entry time until it closes.
+func WithLatencyKey(key func(item any) any) PipelineOption {
+	return func(opts *PipelineOptions) {
+		opts.TrackLatency = true
+		opts.LatencyKey = key
+	}
+}
+
+// WithAckMode makes the pipeline responsible for settling the Deliveries
+// that pass through it: any delivery that enters but never leaves the
+// pipeline, because a stage failed, filtered or lost it, is nacked when the
+// run ends, with ErrDropped or the pipeline's cancellation cause. Deliveries
+// that leave are settled downstream, typically by ToSink once the sink
+// succeeds. Stages that drop deliveries on purpose should ack them first.
+func WithAckMode() PipelineOption {
+	return func(opts *PipelineOptions) {
+		opts.AckMode = true
+	}
+}
+
+// LimiterOptions holds configuration options for rate limiters.
+type LimiterOptions struct {
+	Name     string
+	Observer Observer
+	Registry *Registry
+	Clock    Clock
+	Parent   *RateLimiter
+	// BurstWarmup is the time a BurstRateLimit takes to grow its available
+	// burst from limit to the full burst after an idle period.
+	BurstWarmup time.Duration
+}
+
+// LimiterOption is a function that configures a LimiterOptions.
+type LimiterOption func(*LimiterOptions)
+
+// WithLimiterName sets the rate limiter name reported to observers.
+func WithLimiterName(name string) LimiterOption {
+	return func(opts *LimiterOptions) {
+		opts.Name = name
+	}
+}
+
+// WithLimiterObserver sets the observer that receives the limiter's throttling events.
+func WithLimiterObserver(o Observer) LimiterOption {
+	return func(opts *LimiterOptions) {
+		opts.Observer = o
+	}
+}
+
+// WithLimiterRegistry registers the rate limiter in reg under its name.
+func WithLimiterRegistry(reg *Registry) LimiterOption {
+	return func(opts *LimiterOptions) {
+		opts.Registry = reg
+	}
+}
+
+// WithLimiterClock sets the clock used to measure refill intervals.
+func WithLimiterClock(c Clock) LimiterOption {
+	return func(opts *LimiterOptions) {
+		opts.Clock = c
+	}
+}
+
+// WithBurstWarmup makes a BurstRateLimit restart cold after it has been idle
+// for d: its available burst drops to one interval's worth of tokens and grows
+// back to the full burst over d, so a burst doesn't hammer a cold downstream.
+// Other limiters ignore it.
+func WithBurstWarmup(d time.Duration) LimiterOption {
+	return func(opts *LimiterOptions) {
+		opts.BurstWarmup = d
+	}
+}
+
+// WithLimiterParent makes every operation also draw its tokens from parent,
+// so several limiters can share an overall limit. Tokens are taken from the
+// limiter and its parent together or not at all.
+func WithLimiterParent(parent *RateLimiter) LimiterOption {
+	return func(opts *LimiterOptions) {
+		opts.Parent = parent
+	}
+}
+
+// BreakerOptions holds configuration options for circuit breakers.
+type BreakerOptions struct {
+	Name      string
+	Observer  Observer
+	Logger    Logger
+	Registry  *Registry
+	Clock     Clock
+	MaxProbes int
+	IsFailure func(error) bool
+}
+
+// BreakerOption is a function that configures a BreakerOptions.
+type BreakerOption func(*BreakerOptions)
+
+// WithBreakerName sets the circuit breaker name reported to observers.
+func WithBreakerName(name string) BreakerOption {
+	return func(opts *BreakerOptions) {
+		opts.Name = name
+	}
+}
+
+// WithBreakerObserver sets the observer that receives the breaker's
+// rejection and state change events.
+func WithBreakerObserver(o Observer) BreakerOption {
+	return func(opts *BreakerOptions) {
+		opts.Observer = o
+	}
+}
+
+// WithBreakerLogger sets the logger for circuit breaker state transitions.
+func WithBreakerLogger(l Logger) BreakerOption {
+	return func(opts *BreakerOptions) {
+		opts.Logger = l
+	}
+}
+
+// WithBreakerRegistry registers the circuit breaker in reg under its name.
+func WithBreakerRegistry(reg *Registry) BreakerOption {
+	return func(opts *BreakerOptions) {
+		opts.Registry = reg
+	}
+}
+
+// WithMaxProbes sets how many calls a half-open breaker lets through at once
+// to test the dependency; further calls are rejected with ErrCircuitOpen
+// until a probe finishes. The default is one.
+func WithMaxProbes(n int) BreakerOption {
+	return func(opts *BreakerOptions) {
+		opts.MaxProbes = n
+	}
+}
+
+// WithIsFailure sets which errors count toward tripping the breaker. Errors
+// for which isFailure returns false, such as validation failures, are still
+// returned to the caller but count as a successful call. By default every
+// non-nil error is a failure.
+func WithIsFailure(isFailure func(error) bool) BreakerOption {
+	return func(opts *BreakerOptions) {
+		opts.IsFailure = isFailure
+	}
+}
+
+// WithBreakerClock sets the clock used to time the reset timeout.
+func WithBreakerClock(c Clock) BreakerOption {
+	return func(opts *BreakerOptions) {
+		opts.Clock = c
+	}
 }
 
 // ContextOptions holds options for context handling.
@@ -203,3 +668,396 @@ func WithBlockOnFull(block bool) BackpressureOption {
 		opts.BlockOnFull = block
 	}
 }
+
+// LongLinePolicy decides what LinesSource does with a line longer than
+// LinesOptions.MaxLineLength.
+type LongLinePolicy int
+
+const (
+	// LongLineError stops the source and reports ErrLineTooLong.
+	LongLineError LongLinePolicy = iota
+	// LongLineTruncate emits the first MaxLineLength bytes of the line.
+	LongLineTruncate
+	// LongLineSkip discards the line.
+	LongLineSkip
+)
+
+// LinesOptions holds configuration options for LinesSource.
+type LinesOptions struct {
+	MaxLineLength int
+	LongLines     LongLinePolicy
+	OnLongLine    func(line int)
+}
+
+// DefaultLinesOptions returns the LinesSource defaults: lines up to 1 MiB,
+// and an error for anything longer.
+func DefaultLinesOptions() LinesOptions {
+	return LinesOptions{
+		MaxLineLength: 1 << 20,
+		LongLines:     LongLineError,
+	}
+}
+
+// LinesOption is a function that configures LinesOptions.
+type LinesOption func(*LinesOptions)
+
+// WithMaxLineLength sets the longest line, in bytes, LinesSource accepts.
+func WithMaxLineLength(n int) LinesOption {
+	return func(opts *LinesOptions) {
+		opts.MaxLineLength = n
+	}
+}
+
+// WithLongLines sets how LinesSource handles lines over the maximum length.
+func WithLongLines(policy LongLinePolicy) LinesOption {
+	return func(opts *LinesOptions) {
+		opts.LongLines = policy
+	}
+}
+
+// WithOnLongLine sets a callback receiving the 1-based number of every line
+// that exceeds the maximum length, whatever the policy.
+func WithOnLongLine(fn func(line int)) LinesOption {
+	return func(opts *LinesOptions) {
+		opts.OnLongLine = fn
+	}
+}
+
+// WatchOptions holds configuration options for WatchDir.
+type WatchOptions struct {
+	Interval time.Duration
+	Debounce time.Duration
+	Filter   func(path string) bool
+	Watcher  FileWatcher
+}
+
+// DefaultWatchOptions returns the WatchDir defaults: poll every second and
+// coalesce writes to the same file within 100ms.
+func DefaultWatchOptions() WatchOptions {
+	return WatchOptions{
+		Interval: time.Second,
+		Debounce: 100 * time.Millisecond,
+	}
+}
+
+// WatchOption is a function that configures WatchOptions.
+type WatchOption func(*WatchOptions)
+
+// WithPollInterval sets how often the default polling watcher scans the directory.
+func WithPollInterval(d time.Duration) WatchOption {
+	return func(opts *WatchOptions) {
+		opts.Interval = d
+	}
+}
+
+// WithDebounce sets how long a file must stay unchanged before its event is
+// emitted. Zero disables debouncing.
+func WithDebounce(d time.Duration) WatchOption {
+	return func(opts *WatchOptions) {
+		opts.Debounce = d
+	}
+}
+
+// WithWatchFilter restricts events to paths for which filter returns true.
+func WithWatchFilter(filter func(path string) bool) WatchOption {
+	return func(opts *WatchOptions) {
+		opts.Filter = filter
+	}
+}
+
+// WithFileWatcher replaces the polling watcher, e.g. with an fsnotify-backed one.
+func WithFileWatcher(w FileWatcher) WatchOption {
+	return func(opts *WatchOptions) {
+		opts.Watcher = w
+	}
+}
+
+// MemoizeOptions holds configuration options for Memoize.
+type MemoizeOptions struct {
+	// NegativeTTL caches errors for this long, so a failing key isn't
+	// retried on every call. Zero disables negative caching.
+	NegativeTTL time.Duration
+}
+
+// MemoizeOption is a function that configures MemoizeOptions.
+type MemoizeOption func(*MemoizeOptions)
+
+// WithNegativeTTL caches errors for d, usually shorter than the TTL for
+// successful results. Context cancellation errors are never cached.
+func WithNegativeTTL(d time.Duration) MemoizeOption {
+	return func(opts *MemoizeOptions) {
+		opts.NegativeTTL = d
+	}
+}
+
+// MonitorOptions holds configuration options for Monitor.
+type MonitorOptions struct {
+	Size      func(item any) int
+	Timestamp func(item any) time.Time
+	Clock     Clock
+}
+
+// MonitorOption is a function that configures MonitorOptions.
+type MonitorOption func(*MonitorOptions)
+
+// WithMonitorSize makes Monitor report bytes per second, using size to
+// measure each item.
+func WithMonitorSize(size func(item any) int) MonitorOption {
+	return func(opts *MonitorOptions) {
+		opts.Size = size
+	}
+}
+
+// WithMonitorTimestamp makes Monitor report lag: how long ago the most
+// recent item was produced, as given by timestamp, such as the time it was
+// read from the pipeline source.
+func WithMonitorTimestamp(timestamp func(item any) time.Time) MonitorOption {
+	return func(opts *MonitorOptions) {
+		opts.Timestamp = timestamp
+	}
+}
+
+// WithMonitorClock sets the clock used for report intervals and lag.
+func WithMonitorClock(c Clock) MonitorOption {
+	return func(opts *MonitorOptions) {
+		opts.Clock = c
+	}
+}
+
+// DLQOptions holds configuration options for a DLQ.
+type DLQOptions struct {
+	// Clock stamps the failure times of entries. A Redriver of the queue
+	// uses it too unless given its own.
+	Clock Clock
+}
+
+// DLQOption is a function that configures DLQOptions.
+type DLQOption func(*DLQOptions)
+
+// WithDLQClock sets the clock used to stamp the failure times of entries.
+func WithDLQClock(c Clock) DLQOption {
+	return func(opts *DLQOptions) {
+		opts.Clock = c
+	}
+}
+
+// RedriveOptions holds configuration options for a Redriver.
+type RedriveOptions struct {
+	// Interval is how often Run redrives the queue.
+	Interval time.Duration
+	// MaxAttempts is the attempt budget of an item, counting its original
+	// failure. Items that reach it are parked instead of redriven.
+	MaxAttempts int
+	// Backoff spaces out the redrives of an item, starting from its last
+	// failure. Its MaxRetries is ignored in favor of MaxAttempts.
+	Backoff RetryConfig
+	Clock   Clock
+}
+
+// DefaultRedriveOptions returns the Redriver defaults: redrive every minute,
+// park items after 5 attempts, and back off from 1s up to 5m between
+// attempts.
+func DefaultRedriveOptions() RedriveOptions {
+	return RedriveOptions{
+		Interval:    time.Minute,
+		MaxAttempts: 5,
+		Backoff: RetryConfig{
+			BaseDelay:  time.Second,
+			MaxDelay:   5 * time.Minute,
+			Multiplier: 2,
+			Jitter:     true,
+		},
+	}
+}
+
+// RedriveOption is a function that configures RedriveOptions.
+type RedriveOption func(*RedriveOptions)
+
+// WithRedriveInterval sets how often Redriver.Run redrives the queue.
+func WithRedriveInterval(d time.Duration) RedriveOption {
+	return func(opts *RedriveOptions) {
+		opts.Interval = d
+	}
+}
+
+// WithRedriveMaxAttempts sets the attempt budget after which items are parked.
+func WithRedriveMaxAttempts(n int) RedriveOption {
+	return func(opts *RedriveOptions) {
+		opts.MaxAttempts = n
+	}
+}
+
+// WithRedriveBackoff sets the backoff between redrives of the same item.
+func WithRedriveBackoff(config RetryConfig) RedriveOption {
+	return func(opts *RedriveOptions) {
+		opts.Backoff = config
+	}
+}
+
+// WithRedriveClock sets the clock used for the redrive schedule and backoff.
+// It defaults to the clock of the queue, which stamps the failures the
+// backoff counts from, so the two should normally be the same.
+func WithRedriveClock(c Clock) RedriveOption {
+	return func(opts *RedriveOptions) {
+		opts.Clock = c
+	}
+}
+
+// TeePolicy decides what Tee does when one of its outputs is not ready
+// for the next item.
+type TeePolicy int
+
+const (
+	// TeeBlock waits for the output, holding up the input and every other
+	// output until it receives the item.
+	TeeBlock TeePolicy = iota
+	// TeeDrop discards items the output is not ready to receive.
+	TeeDrop
+	// TeeBuffer queues up to TeeOutput.Buffer items for the output before
+	// falling back to TeeBlock.
+	TeeBuffer
+)
+
+// MergeStrategy decides the order in which MergeWith reads its inputs.
+type MergeStrategy int
+
+const (
+	// MergeFair forwards items from all inputs as they arrive.
+	MergeFair MergeStrategy = iota
+	// MergeSequential drains each input to completion before reading the
+	// next, preserving input order.
+	MergeSequential
+	// MergePriority always takes a ready item from the earliest input,
+	// reading later inputs only while earlier ones have nothing to offer.
+	MergePriority
+)
+
+// AgingPolicy configures MergeAging, which merges inputs by priority like
+// MergePriority but lets items that have waited long enough overtake
+// higher-priority ones, so bulk work still completes under sustained
+// high-priority load.
+type AgingPolicy struct {
+	// Step is how long an item must wait to gain one level of priority,
+	// that is, to rank with the items of the input before its own. Zero
+	// disables gradual aging.
+	Step time.Duration
+	// MaxWait bounds how long an item waits once the merge has taken it
+	// from its input: items that have waited MaxWait are forwarded before
+	// any others, oldest first. Zero means no bound.
+	MaxWait time.Duration
+	Clock   Clock
+}
+
+// AutoTuneOptions holds configuration options for an AutoTuner.
+type AutoTuneOptions struct {
+	Min int
+	Max int
+	// TargetUtilization is the fraction of the limit, from 0 to 1, that
+	// should be busy on average. Below it the limit shrinks; at or above
+	// it the limit grows for as long as that raises throughput.
+	TargetUtilization float64
+	// LatencySLO, if set, shrinks the limit while the average job takes
+	// longer, since past the point of contention more concurrency only
+	// slows every job down.
+	LatencySLO time.Duration
+	// Interval is how often the limit is adjusted.
+	Interval time.Duration
+	Clock    Clock
+}
+
+// DefaultAutoTuneOptions returns the AutoTuner defaults: between 1 and
+// runtime.GOMAXPROCS(0)*4 workers, targeting 80% utilization, adjusted every
+// second.
+func DefaultAutoTuneOptions() AutoTuneOptions {
+	return AutoTuneOptions{
+		Min:               1,
+		Max:               runtime.GOMAXPROCS(0) * 4,
+		TargetUtilization: 0.8,
+		Interval:          time.Second,
+	}
+}
+
+// AutoTuneOption is a function that configures AutoTuneOptions.
+type AutoTuneOption func(*AutoTuneOptions)
+
+// WithAutoTuneBounds sets the smallest and largest limit the tuner may set.
+func WithAutoTuneBounds(minimum, maximum int) AutoTuneOption {
+	return func(opts *AutoTuneOptions) {
+		opts.Min = minimum
+		opts.Max = maximum
+	}
+}
+
+// WithTargetUtilization sets the fraction of the limit that should be busy.
+func WithTargetUtilization(u float64) AutoTuneOption {
+	return func(opts *AutoTuneOptions) {
+		opts.TargetUtilization = u
+	}
+}
+
+// WithLatencySLO sets the average job latency the tuner keeps below.
+func WithLatencySLO(d time.Duration) AutoTuneOption {
+	return func(opts *AutoTuneOptions) {
+		opts.LatencySLO = d
+	}
+}
+
+// WithAutoTuneInterval sets how often the tuner adjusts the limit.
+func WithAutoTuneInterval(d time.Duration) AutoTuneOption {
+	return func(opts *AutoTuneOptions) {
+		opts.Interval = d
+	}
+}
+
+// WithAutoTuneClock sets the clock the tuner measures time with.
+func WithAutoTuneClock(c Clock) AutoTuneOption {
+	return func(opts *AutoTuneOptions) {
+		opts.Clock = c
+	}
+}
+
+// TimeOptions holds configuration options for the time-driven helpers Join,
+// DedupWithin, Tick, RecvBatch and Replay.
+type TimeOptions struct {
+	Clock Clock
+}
+
+// TimeOption is a function that configures a TimeOptions.
+type TimeOption func(*TimeOptions)
+
+// WithClock sets the clock used for windows, tickers and timeouts.
+func WithClock(c Clock) TimeOption {
+	return func(opts *TimeOptions) {
+		opts.Clock = c
+	}
+}
+
+// RecorderOptions holds configuration options for a FileRecorder.
+type RecorderOptions struct {
+	// MaxBytes caps how much is written to one writer. Zero means no limit.
+	MaxBytes int64
+	// Rotate, if set, is called with the full writer once MaxBytes is
+	// reached and returns the writer to continue on. It may close or
+	// rename the full one.
+	Rotate func(full io.Writer) (io.Writer, error)
+}
+
+// RecorderOption is a function that configures a RecorderOptions.
+type RecorderOption func(*RecorderOptions)
+
+// WithMaxRecordingBytes caps a FileRecorder at n bytes per writer. Without
+// WithRecordingRotation, items past the cap are dropped with
+// ErrRecordingFull.
+func WithMaxRecordingBytes(n int64) RecorderOption {
+	return func(opts *RecorderOptions) {
+		opts.MaxBytes = n
+	}
+}
+
+// WithRecordingRotation makes a FileRecorder continue on the writer returned
+// by rotate whenever the current one reaches WithMaxRecordingBytes.
+func WithRecordingRotation(rotate func(full io.Writer) (io.Writer, error)) RecorderOption {
+	return func(opts *RecorderOptions) {
+		opts.Rotate = rotate
+	}
+}
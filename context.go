@@ -0,0 +1,45 @@
+package concurrent
+
+import (
+	"context"
+	"time"
+)
+
+// MergeContexts returns a context that is cancelled as soon as either a or b is.
+// Values and the deadline come from a; context.Cause reports the cause of
+// whichever parent was cancelled first. Call the returned cancel function to
+// release resources once the merged context is no longer needed.
+func MergeContexts(a, b context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(a)
+	stop := context.AfterFunc(b, func() {
+		cancel(context.Cause(b))
+	})
+	return ctx, func() {
+		stop()
+		cancel(context.Canceled)
+	}
+}
+
+// DetachValues returns a context that carries the values of ctx but is never
+// cancelled and has no deadline. Use it for background work, such as a final
+// flush, that must outlive the request that triggered it.
+func DetachValues(ctx context.Context) context.Context {
+	return context.WithoutCancel(ctx)
+}
+
+// WithDeadlineCause returns a context that is cancelled at deadline with the
+// given cause, or earlier through the returned function with a cause of the
+// caller's choosing.
+func WithDeadlineCause(parent context.Context, deadline time.Time, cause error) (context.Context, context.CancelCauseFunc) {
+	ctx, cancel := context.WithCancelCause(parent)
+	ctx, stop := context.WithDeadlineCause(ctx, deadline, cause)
+	return ctx, func(err error) {
+		cancel(err)
+		stop()
+	}
+}
+
+// WithTimeoutCause is like WithDeadlineCause with a deadline of time.Now().Add(timeout).
+func WithTimeoutCause(parent context.Context, timeout time.Duration, cause error) (context.Context, context.CancelCauseFunc) {
+	return WithDeadlineCause(parent, time.Now().Add(timeout), cause)
+}
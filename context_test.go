@@ -0,0 +1,76 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/logimos/concurrent/concurrenttest"
+)
+
+type ctxKey struct{}
+
+func TestMergeContexts(t *testing.T) {
+	t.Run("cancelled by either parent", func(t *testing.T) {
+		errShutdown := errors.New("shutdown")
+		a := context.WithValue(context.Background(), ctxKey{}, "a")
+		b, cancelB := context.WithCancelCause(context.Background())
+
+		ctx, cancel := MergeContexts(a, b)
+		defer cancel()
+
+		if ctx.Value(ctxKey{}) != "a" {
+			t.Error("Expected merged context to carry values of a")
+		}
+
+		cancelB(errShutdown)
+		concurrenttest.AssertClosedWithin(t, ctx.Done(), time.Second)
+		if !errors.Is(context.Cause(ctx), errShutdown) {
+			t.Errorf("Expected shutdown cause, got %v", context.Cause(ctx))
+		}
+	})
+
+	t.Run("cancel func", func(t *testing.T) {
+		ctx, cancel := MergeContexts(context.Background(), context.Background())
+		cancel()
+		if !errors.Is(ctx.Err(), context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", ctx.Err())
+		}
+	})
+}
+
+func TestDetachValues(t *testing.T) {
+	parent, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, 1))
+	ctx := DetachValues(parent)
+	cancel()
+
+	if ctx.Err() != nil {
+		t.Errorf("Expected detached context to stay alive, got %v", ctx.Err())
+	}
+	if ctx.Value(ctxKey{}) != 1 {
+		t.Error("Expected detached context to keep values")
+	}
+}
+
+func TestWithDeadlineCause(t *testing.T) {
+	t.Run("deadline cause", func(t *testing.T) {
+		errSlow := errors.New("too slow")
+		ctx, cancel := WithTimeoutCause(context.Background(), 10*time.Millisecond, errSlow)
+		defer cancel(nil)
+
+		<-ctx.Done()
+		if !errors.Is(context.Cause(ctx), errSlow) {
+			t.Errorf("Expected deadline cause, got %v", context.Cause(ctx))
+		}
+	})
+
+	t.Run("manual cause", func(t *testing.T) {
+		errAbort := errors.New("aborted")
+		ctx, cancel := WithDeadlineCause(context.Background(), time.Now().Add(time.Hour), errors.New("unused"))
+		cancel(errAbort)
+		if !errors.Is(context.Cause(ctx), errAbort) {
+			t.Errorf("Expected manual cause, got %v", context.Cause(ctx))
+		}
+	})
+}
@@ -0,0 +1,102 @@
+package concurrent
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// DebugHandler returns an http.Handler that renders the live state of every
+// component in reg, in the spirit of expvar and net/http/pprof. It serves an
+// HTML page by default and JSON when the request asks for it with
+// ?format=json or an Accept header of application/json. A nil reg uses
+// DefaultRegistry.
+//
+//	http.Handle("/debug/concurrent", concurrent.DebugHandler(nil))
+func DebugHandler(reg *Registry) http.Handler {
+	if reg == nil {
+		reg = DefaultRegistry
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		snapshot := reg.Snapshot()
+		if r.URL.Query().Get("format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json") {
+			w.Header().Set("Content-Type", "application/json")
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			_ = enc.Encode(snapshot)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = debugPage.Execute(w, debugRows(snapshot))
+	})
+}
+
+type debugRow struct {
+	Name  string
+	Kind  string
+	Stats []debugStat
+}
+
+type debugStat struct {
+	Key   string
+	Value string
+}
+
+func debugRows(snapshot map[string]Report) []debugRow {
+	rows := make([]debugRow, 0, len(snapshot))
+	for name, report := range snapshot {
+		row := debugRow{Name: name, Kind: report.Kind}
+		for key, value := range report.Stats {
+			row.Stats = append(row.Stats, debugStat{Key: key, Value: formatStat(value)})
+		}
+		sort.Slice(row.Stats, func(i, j int) bool { return row.Stats[i].Key < row.Stats[j].Key })
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Name < rows[j].Name })
+	return rows
+}
+
+func formatStat(v any) string {
+	switch v := v.(type) {
+	case float64:
+		return fmt.Sprintf("%.2f", v)
+	case []float64:
+		parts := make([]string, len(v))
+		for i, f := range v {
+			parts[i] = fmt.Sprintf("%.2f", f)
+		}
+		return "[" + strings.Join(parts, " ") + "]"
+	case MetricsSnapshot:
+		return fmt.Sprintf("processed=%d errors=%d p50=%v p95=%v p99=%v",
+			v.ProcessedCount, v.ErrorCount, v.P50, v.P95, v.P99)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+var debugPage = template.Must(template.New("debug").Parse(`<!DOCTYPE html>
+<html>
+<head><title>concurrent</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #ccc; padding: 2px 8px; text-align: left; }
+</style>
+</head>
+<body>
+<h1>concurrent components</h1>
+{{range .}}
+<h2>{{.Name}} <small>({{.Kind}})</small></h2>
+<table>
+{{range .Stats}}<tr><th>{{.Key}}</th><td>{{.Value}}</td></tr>
+{{end}}</table>
+{{else}}
+<p>No components registered.</p>
+{{end}}
+</body>
+</html>
+`))
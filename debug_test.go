@@ -0,0 +1,54 @@
+package concurrent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDebugHandler(t *testing.T) {
+	reg := NewRegistry()
+	NewCircuitBreaker(3, time.Second, WithBreakerName("payments"), WithBreakerRegistry(reg))
+	NewRateLimiter(5, time.Second, WithLimiterName("api"), WithLimiterRegistry(reg))
+
+	ctx := context.Background()
+	p := NewPipeline[int](ctx, WithPipelineName("ingest"), WithPipelineRegistry(reg)).
+		AddStage(Map(func(v int) int { return v }))
+	Drain(ctx, p.Run(sliceChan(1, 2, 3)))
+
+	handler := DebugHandler(reg)
+
+	t.Run("json", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/concurrent?format=json", nil))
+
+		if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Expected JSON content type, got %q", ct)
+		}
+		var got map[string]Report
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(got) != 3 {
+			t.Errorf("Expected 3 components, got %d", len(got))
+		}
+		if got["payments"].Stats["state"] != "closed" {
+			t.Errorf("Expected breaker state closed, got %v", got["payments"].Stats["state"])
+		}
+	})
+
+	t.Run("html", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/concurrent", nil))
+
+		body := rec.Body.String()
+		for _, want := range []string{"payments", "api", "ingest", "in_flight"} {
+			if !strings.Contains(body, want) {
+				t.Errorf("Expected page to mention %q", want)
+			}
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package concurrent
+
+import (
+	"context"
+	"time"
+)
+
+// DedupWithin creates a stage that drops items whose key was already seen in
+// the last ttl. The seen-set is compacted every ttl, so its size is bounded by
+// the number of distinct keys arriving within roughly two ttl periods.
+func DedupWithin[T any, K comparable](keyFn func(T) K, ttl time.Duration, opts ...TimeOption) Stage[T, T] {
+	if ttl <= 0 {
+		ttl = time.Second
+	}
+	clock := timeClock(opts)
+	return func(ctx context.Context, input <-chan T) <-chan T {
+		output := make(chan T)
+		go func() {
+			defer close(output)
+			seen := make(map[K]time.Time)
+			ticker := clock.NewTicker(ttl)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case now := <-ticker.C():
+					for k, at := range seen {
+						if now.Sub(at) >= ttl {
+							delete(seen, k)
+						}
+					}
+				case item, ok := <-input:
+					if !ok {
+						return
+					}
+					k := keyFn(item)
+					now := clock.Now()
+					if at, dup := seen[k]; dup && now.Sub(at) < ttl {
+						continue
+					}
+					seen[k] = now
+					select {
+					case <-ctx.Done():
+						return
+					case output <- item:
+					}
+				}
+			}
+		}()
+		return output
+	}
+}
@@ -0,0 +1,67 @@
+package concurrent
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDedupWithin(t *testing.T) {
+	t.Run("drops duplicates", func(t *testing.T) {
+		ctx := context.Background()
+		input := sliceChan("a", "b", "a", "c", "b")
+
+		got := Collect(ctx, DedupWithin(func(s string) string { return s }, time.Minute)(ctx, input), 0)
+		expected := []string{"a", "b", "c"}
+		if len(got) != len(expected) {
+			t.Fatalf("Expected %v, got %v", expected, got)
+		}
+		for i, v := range got {
+			if v != expected[i] {
+				t.Errorf("Expected %q at index %d, got %q", expected[i], i, v)
+			}
+		}
+	})
+
+	t.Run("allows repeats after ttl", func(t *testing.T) {
+		ctx := context.Background()
+		input := make(chan int)
+		output := DedupWithin(func(v int) int { return v }, 20*time.Millisecond)(ctx, input)
+
+		go func() {
+			input <- 1
+			input <- 1
+			time.Sleep(40 * time.Millisecond)
+			input <- 1
+			close(input)
+		}()
+
+		if got := Collect(ctx, output, 0); len(got) != 2 {
+			t.Errorf("Expected 2 items, got %v", got)
+		}
+	})
+
+	t.Run("uses the configured clock", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		clock := NewFakeClock(time.Now())
+		input := make(chan int)
+		output := DedupWithin(func(v int) int { return v }, time.Minute, WithClock(clock))(ctx, input)
+
+		input <- 1
+		if v := <-output; v != 1 {
+			t.Fatalf("Expected 1, got %d", v)
+		}
+		input <- 1
+		input <- 2
+		if v := <-output; v != 2 {
+			t.Fatalf("Expected the duplicate to be dropped, got %d", v)
+		}
+
+		clock.Advance(time.Minute)
+		input <- 1
+		if v := <-output; v != 1 {
+			t.Errorf("Expected 1 again after the ttl, got %d", v)
+		}
+	})
+}
@@ -0,0 +1,287 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrDropped is the error deliveries are nacked with when a pipeline in ack
+// mode drops them.
+var ErrDropped = errors.New("concurrent: delivery dropped by pipeline")
+
+// Delivery is an item received from a Source together with the means to
+// settle it. An item is settled exactly once: Ack confirms it was fully
+// processed, Nack asks the source to redeliver it. Later calls are no-ops.
+type Delivery[T any] struct {
+	Item T
+
+	ack     func() error
+	nack    func(error) error
+	settled *atomic.Bool
+}
+
+// NewDelivery wraps item with its acknowledgement callbacks. Either
+// callback may be nil.
+func NewDelivery[T any](item T, ack func() error, nack func(error) error) Delivery[T] {
+	return Delivery[T]{Item: item, ack: ack, nack: nack, settled: new(atomic.Bool)}
+}
+
+// Ack acknowledges the item.
+func (d Delivery[T]) Ack() error {
+	if d.settled == nil || !d.settled.CompareAndSwap(false, true) || d.ack == nil {
+		return nil
+	}
+	return d.ack()
+}
+
+// Nack rejects the item because of err, asking for redelivery.
+func (d Delivery[T]) Nack(err error) error {
+	if d.settled == nil || !d.settled.CompareAndSwap(false, true) || d.nack == nil {
+		return nil
+	}
+	return d.nack(err)
+}
+
+// token identifies the original message, shared by every delivery
+// forwarded from it.
+func (d Delivery[T]) token() *atomic.Bool {
+	return d.settled
+}
+
+// Forward returns a delivery of item that settles d's original message,
+// for stages that transform the payload.
+func Forward[T any, R any](d Delivery[T], item R) Delivery[R] {
+	return Delivery[R]{Item: item, ack: d.ack, nack: d.nack, settled: d.settled}
+}
+
+// Source produces deliveries, e.g. from a message queue. Receive blocks until
+// an item is available and returns io.EOF once the source is exhausted.
+// Adapters for Kafka, NATS, SQS and the like implement it outside this package.
+type Source[T any] interface {
+	Receive(ctx context.Context) (Delivery[T], error)
+}
+
+// Sink consumes the items at the end of a pipeline.
+type Sink[T any] interface {
+	Write(ctx context.Context, item T) error
+}
+
+// SinkFunc adapts an ordinary function to the Sink interface.
+type SinkFunc[T any] func(context.Context, T) error
+
+// Write calls f(ctx, item).
+func (f SinkFunc[T]) Write(ctx context.Context, item T) error {
+	return f(ctx, item)
+}
+
+// FromSource turns src into a pipeline source. The error channel receives
+// the first Receive error other than io.EOF after the delivery channel is
+// closed; it is buffered, so it need not be read.
+func FromSource[T any](ctx context.Context, src Source[T]) (<-chan Delivery[T], <-chan error) {
+	output := make(chan Delivery[T])
+	errc := make(chan error, 1)
+	go func() {
+		defer close(errc)
+		err := func() error {
+			defer close(output)
+			for {
+				d, err := src.Receive(ctx)
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+				select {
+				case <-ctx.Done():
+					_ = d.Nack(ctx.Err())
+					return ctx.Err()
+				case output <- d:
+				}
+			}
+		}()
+		if err != nil {
+			errc <- err
+		}
+	}()
+	return output, errc
+}
+
+// AckStage applies fn to each delivery's item, forwarding the result with the
+// original acknowledgement. If fn fails, the delivery is nacked with the
+// error and dropped, so the source can redeliver it.
+func AckStage[T any, R any](fn func(context.Context, T) (R, error)) Stage[Delivery[T], Delivery[R]] {
+	return func(ctx context.Context, input <-chan Delivery[T]) <-chan Delivery[R] {
+		output := make(chan Delivery[R])
+		go func() {
+			defer close(output)
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case d, ok := <-input:
+					if !ok {
+						return
+					}
+					r, err := fn(ctx, d.Item)
+					if err != nil {
+						_ = d.Nack(err)
+						continue
+					}
+					select {
+					case <-ctx.Done():
+						_ = d.Nack(ctx.Err())
+						return
+					case output <- Forward(d, r):
+					}
+				}
+			}
+		}()
+		return output
+	}
+}
+
+// ToSink writes every delivery's item to sink, acking it only once the write
+// succeeds and nacking it otherwise, which gives at-least-once delivery end
+// to end. It returns when input closes or ctx is cancelled, with any errors
+// from settling deliveries joined together.
+func ToSink[T any](ctx context.Context, sink Sink[T], input <-chan Delivery[T]) error {
+	var errs []error
+	for {
+		select {
+		case <-ctx.Done():
+			return errors.Join(append(errs, ctx.Err())...)
+		case d, ok := <-input:
+			if !ok {
+				return errors.Join(errs...)
+			}
+			var err error
+			if werr := sink.Write(ctx, d.Item); werr != nil {
+				err = d.Nack(werr)
+			} else {
+				err = d.Ack()
+			}
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+}
+
+// ackable is implemented by every Delivery, whatever its item type.
+type ackable interface {
+	Nack(error) error
+	token() *atomic.Bool
+}
+
+// ackTracker holds the deliveries inside a pipeline in ack mode.
+type ackTracker struct {
+	mu      sync.Mutex
+	pending map[*atomic.Bool]ackable
+	done    bool
+}
+
+// enter starts tracking item if it is a delivery. Deliveries entering after
+// the run ended are nacked at once.
+func (a *ackTracker) enter(item any, cause error) {
+	d, ok := item.(ackable)
+	if !ok || d.token() == nil {
+		return
+	}
+	a.mu.Lock()
+	if a.done {
+		a.mu.Unlock()
+		_ = d.Nack(cause)
+		return
+	}
+	a.pending[d.token()] = d
+	a.mu.Unlock()
+}
+
+// exit stops tracking item, which has left the pipeline.
+func (a *ackTracker) exit(item any) {
+	if d, ok := item.(ackable); ok {
+		a.mu.Lock()
+		delete(a.pending, d.token())
+		a.mu.Unlock()
+	}
+}
+
+// nackAll ends the run, nacking every delivery still in the pipeline.
+func (a *ackTracker) nackAll(cause error) {
+	a.mu.Lock()
+	a.done = true
+	pending := a.pending
+	a.pending = nil
+	a.mu.Unlock()
+	for _, d := range pending {
+		_ = d.Nack(cause)
+	}
+}
+
+// trackDeliveries forwards items from input, tracking each delivery as it
+// enters the pipeline.
+func trackDeliveries[T any](ctx context.Context, a *ackTracker, input <-chan T) <-chan T {
+	output := make(chan T)
+	go func() {
+		defer close(output)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case item, ok := <-input:
+				if !ok {
+					return
+				}
+				a.enter(item, context.Cause(ctx))
+				select {
+				case <-ctx.Done():
+					// The item is tracked, so settleDropped nacks it.
+					return
+				case output <- item:
+				}
+			}
+		}
+	}()
+	return output
+}
+
+// settleDropped forwards items from input, releasing each delivery that
+// leaves the pipeline, and nacks the deliveries left inside once input
+// closes or ctx is cancelled.
+func settleDropped[T any](ctx context.Context, a *ackTracker, input <-chan T) <-chan T {
+	output := make(chan T)
+	go func() {
+		defer close(output)
+		cause := ErrDropped
+		defer func() { a.nackAll(cause) }()
+		for {
+			select {
+			case <-ctx.Done():
+				cause = context.Cause(ctx)
+				return
+			case item, ok := <-input:
+				if !ok {
+					if ctx.Err() != nil {
+						cause = context.Cause(ctx)
+					}
+					return
+				}
+				a.exit(item)
+				select {
+				case <-ctx.Done():
+					cause = context.Cause(ctx)
+					if d, ok := any(item).(ackable); ok {
+						_ = d.Nack(cause)
+					}
+					return
+				case output <- item:
+				}
+			}
+		}
+	}()
+	return output
+}
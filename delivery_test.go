@@ -0,0 +1,156 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+)
+
+// sliceSource is an in-memory Source recording how each item was settled.
+type sliceSource struct {
+	mu      sync.Mutex
+	items   []int
+	acked   []int
+	nacked  []int
+	nextIdx int
+}
+
+func (s *sliceSource) Receive(ctx context.Context) (Delivery[int], error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.nextIdx == len(s.items) {
+		return Delivery[int]{}, io.EOF
+	}
+	item := s.items[s.nextIdx]
+	s.nextIdx++
+	return NewDelivery(item, func() error {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.acked = append(s.acked, item)
+		return nil
+	}, func(error) error {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.nacked = append(s.nacked, item)
+		return nil
+	}), nil
+}
+
+func TestDelivery(t *testing.T) {
+	t.Run("settles once", func(t *testing.T) {
+		acks := 0
+		d := NewDelivery(1, func() error { acks++; return nil }, nil)
+		fwd := Forward(d, "one")
+		_ = fwd.Ack()
+		_ = d.Ack()
+		_ = d.Nack(errors.New("late"))
+		if acks != 1 {
+			t.Errorf("Expected exactly one ack, got %d", acks)
+		}
+	})
+
+	t.Run("at least once pipeline", func(t *testing.T) {
+		ctx := context.Background()
+		src := &sliceSource{items: []int{1, 2, 3, 4}}
+
+		deliveries, errc := FromSource[int](ctx, src)
+		doubled := AckStage(func(_ context.Context, v int) (int, error) {
+			if v == 2 {
+				return 0, errors.New("transform failed")
+			}
+			return v * 2, nil
+		})(ctx, deliveries)
+
+		var written []int
+		sink := SinkFunc[int](func(_ context.Context, v int) error {
+			if v == 8 {
+				return errors.New("sink unavailable")
+			}
+			written = append(written, v)
+			return nil
+		})
+		if err := ToSink(ctx, sink, doubled); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := <-errc; err != nil {
+			t.Fatalf("Unexpected source error: %v", err)
+		}
+
+		if len(written) != 2 || written[0] != 2 || written[1] != 6 {
+			t.Errorf("Expected [2 6] written, got %v", written)
+		}
+		if len(src.acked) != 2 || src.acked[0] != 1 || src.acked[1] != 3 {
+			t.Errorf("Expected items 1 and 3 acked, got %v", src.acked)
+		}
+		if len(src.nacked) != 2 || src.nacked[0] != 2 || src.nacked[1] != 4 {
+			t.Errorf("Expected items 2 and 4 nacked, got %v", src.nacked)
+		}
+	})
+}
+
+func TestAckMode(t *testing.T) {
+	settle := func(items []int) (<-chan Delivery[int], *sync.Map) {
+		outcomes := new(sync.Map)
+		ch := make(chan Delivery[int], len(items))
+		for _, v := range items {
+			ch <- NewDelivery(v, func() error {
+				outcomes.Store(v, nil)
+				return nil
+			}, func(err error) error {
+				outcomes.Store(v, err)
+				return nil
+			})
+		}
+		close(ch)
+		return ch, outcomes
+	}
+
+	t.Run("nacks dropped deliveries", func(t *testing.T) {
+		ctx := context.Background()
+		input, outcomes := settle([]int{1, 2, 3, 4})
+		p := NewPipeline[Delivery[int]](ctx, WithAckMode())
+		p.AddStage(Filter(func(d Delivery[int]) bool { return d.Item%2 == 0 }))
+
+		sink := SinkFunc[int](func(context.Context, int) error { return nil })
+		if err := ToSink(ctx, sink, p.Run(input)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for v, want := range map[int]error{1: ErrDropped, 2: nil, 3: ErrDropped, 4: nil} {
+			got, ok := outcomes.Load(v)
+			if !ok {
+				t.Errorf("Expected item %d to be settled", v)
+				continue
+			}
+			if err, _ := got.(error); !errors.Is(err, want) {
+				t.Errorf("Expected item %d settled with %v, got %v", v, want, got)
+			}
+		}
+	})
+
+	t.Run("nacks in-flight deliveries on close", func(t *testing.T) {
+		input, outcomes := settle([]int{1})
+		p := NewPipeline[Delivery[int]](context.Background(), WithAckMode())
+		received := make(chan struct{})
+		p.AddStage(func(ctx context.Context, input <-chan Delivery[int]) <-chan Delivery[int] {
+			output := make(chan Delivery[int])
+			go func() {
+				defer close(output)
+				<-input
+				close(received)
+				<-ctx.Done()
+			}()
+			return output
+		})
+
+		output := p.Run(input)
+		<-received
+		p.Close()
+		for range output {
+		}
+		if got, _ := outcomes.Load(1); !errors.Is(got.(error), ErrPipelineClosed) {
+			t.Errorf("Expected item 1 nacked with ErrPipelineClosed, got %v", got)
+		}
+	})
+}
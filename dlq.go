@@ -0,0 +1,274 @@
+package concurrent
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DeadLetter is a failed item recorded by a DLQ.
+type DeadLetter[T any] struct {
+	Item        T
+	Err         error
+	Attempts    int
+	FirstFailed time.Time
+	LastFailed  time.Time
+}
+
+// DLQ is a bounded, concurrency-safe store of items that failed processing.
+// When the queue is full the oldest entry is evicted to make room.
+type DLQ[T any] struct {
+	mu       sync.Mutex
+	capacity int
+	entries  []DeadLetter[T]
+	dropped  int64
+	clock    Clock
+}
+
+// NewDLQ creates a dead letter queue holding at most capacity entries.
+func NewDLQ[T any](capacity int, opts ...DLQOption) *DLQ[T] {
+	var options DLQOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &DLQ[T]{capacity: capacity, clock: clockOrReal(options.Clock)}
+}
+
+// Add records a failed item after the given number of attempts.
+func (d *DLQ[T]) Add(item T, err error, attempts int) {
+	now := d.clock.Now()
+	d.push(DeadLetter[T]{
+		Item:        item,
+		Err:         err,
+		Attempts:    attempts,
+		FirstFailed: now,
+		LastFailed:  now,
+	})
+}
+
+func (d *DLQ[T]) push(dl DeadLetter[T]) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.entries) >= d.capacity {
+		d.entries[0] = DeadLetter[T]{}
+		d.entries = d.entries[1:]
+		d.dropped++
+	}
+	d.entries = append(d.entries, dl)
+}
+
+// Len returns the number of entries currently held.
+func (d *DLQ[T]) Len() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.entries)
+}
+
+// Dropped returns the number of entries evicted because the queue was full.
+func (d *DLQ[T]) Dropped() int64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.dropped
+}
+
+// Entries returns a snapshot of the current entries, oldest first.
+func (d *DLQ[T]) Entries() []DeadLetter[T] {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]DeadLetter[T](nil), d.entries...)
+}
+
+// Range calls fn for each entry, oldest first, until fn returns false.
+// It iterates over a snapshot, so fn may safely call other DLQ methods.
+func (d *DLQ[T]) Range(fn func(DeadLetter[T]) bool) {
+	for _, dl := range d.Entries() {
+		if !fn(dl) {
+			return
+		}
+	}
+}
+
+// Drain removes and returns all entries, oldest first.
+func (d *DLQ[T]) Drain() []DeadLetter[T] {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	entries := d.entries
+	d.entries = nil
+	return entries
+}
+
+// Redrive drains the queue and reprocesses every entry with fn.
+// Entries that fail again are put back with their attempt count incremented.
+// It returns the number of entries that succeeded, stopping early if ctx is cancelled;
+// unprocessed entries are returned to the queue in that case.
+func (d *DLQ[T]) Redrive(ctx context.Context, fn func(context.Context, T) error) (int, error) {
+	entries := d.Drain()
+	succeeded := 0
+	for i, dl := range entries {
+		if err := ctx.Err(); err != nil {
+			for _, rest := range entries[i:] {
+				d.push(rest)
+			}
+			return succeeded, err
+		}
+		if err := fn(ctx, dl.Item); err != nil {
+			dl.Err = err
+			dl.Attempts++
+			dl.LastFailed = d.clock.Now()
+			d.push(dl)
+			continue
+		}
+		succeeded++
+	}
+	return succeeded, nil
+}
+
+// WithDLQ wraps a processing function so that failed items are recorded in dlq.
+// The error is still returned, so the wrapped function can be used with Pool,
+// FanOut and friends without changing their behavior.
+func WithDLQ[T any, R any](dlq *DLQ[T], fn func(context.Context, T) (R, error)) func(context.Context, T) (R, error) {
+	return func(ctx context.Context, item T) (R, error) {
+		r, err := fn(ctx, item)
+		if err != nil {
+			dlq.Add(item, err, 1)
+		}
+		return r, err
+	}
+}
+
+// RetryWithDLQ wraps fn with retry logic and records items that still fail
+// after all retries in dlq, along with the number of attempts made.
+func RetryWithDLQ[T any](dlq *DLQ[T], fn RetryableFunc[T], config RetryConfig) RetryableFunc[T] {
+	return func(ctx context.Context, item T) error {
+		attempts := 0
+		counted := func(ctx context.Context, item T) error {
+			attempts++
+			return fn(ctx, item)
+		}
+		err := Retry(ctx, item, counted, config)
+		if err != nil {
+			dlq.Add(item, err, attempts)
+		}
+		return err
+	}
+}
+
+// RedriveResult counts what a Redriver did with the entries of a queue.
+type RedriveResult struct {
+	Succeeded int // reprocessed successfully and removed
+	Failed    int // failed again and returned to the queue
+	Deferred  int // still backing off and returned untouched
+	Parked    int // out of attempts and moved to the parking queue
+}
+
+// Redriver reprocesses the entries of a dead letter queue with a target
+// function, such as one feeding a pipeline or pool, on a schedule or on
+// demand. Each item is retried with backoff until its attempt budget runs
+// out, after which it is parked in a separate queue for manual inspection.
+type Redriver[T any] struct {
+	dlq     *DLQ[T]
+	parked  *DLQ[T]
+	target  func(context.Context, T) error
+	options RedriveOptions
+	clock   Clock
+	mu      sync.Mutex // serializes redrives
+}
+
+// NewRedriver creates a redriver feeding the entries of dlq to target.
+// The parking queue has the same capacity as dlq. Unless configured with
+// WithRedriveClock, the redriver uses the clock of dlq.
+func NewRedriver[T any](dlq *DLQ[T], target func(context.Context, T) error, opts ...RedriveOption) *Redriver[T] {
+	options := DefaultRedriveOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.Interval <= 0 {
+		options.Interval = time.Minute
+	}
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = 1
+	}
+	clock := options.Clock
+	if clock == nil {
+		clock = dlq.clock
+	}
+	return &Redriver[T]{
+		dlq:     dlq,
+		parked:  NewDLQ[T](dlq.capacity, WithDLQClock(clock)),
+		target:  target,
+		options: options,
+		clock:   clock,
+	}
+}
+
+// Parked returns the queue of items that ran out of attempts.
+func (r *Redriver[T]) Parked() *DLQ[T] {
+	return r.parked
+}
+
+// Redrive makes one pass over the queue: entries that are out of attempts
+// are parked, entries whose backoff has elapsed are passed to the target,
+// and the rest are left for a later pass. If ctx is cancelled, the
+// unprocessed entries are returned to the queue along with ctx's error.
+func (r *Redriver[T]) Redrive(ctx context.Context) (RedriveResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var result RedriveResult
+	entries := r.dlq.Drain()
+	for i, dl := range entries {
+		if err := ctx.Err(); err != nil {
+			for _, rest := range entries[i:] {
+				r.dlq.push(rest)
+			}
+			return result, err
+		}
+		if dl.Attempts >= r.options.MaxAttempts {
+			r.parked.push(dl)
+			result.Parked++
+			continue
+		}
+		now := r.clock.Now()
+		if now.Before(dl.LastFailed.Add(backoffDelay(dl.Attempts-1, r.options.Backoff))) {
+			r.dlq.push(dl)
+			result.Deferred++
+			continue
+		}
+		err := r.target(ctx, dl.Item)
+		if err == nil {
+			result.Succeeded++
+			continue
+		}
+		dl.Err = err
+		dl.Attempts++
+		dl.LastFailed = r.clock.Now()
+		if dl.Attempts >= r.options.MaxAttempts {
+			r.parked.push(dl)
+			result.Parked++
+		} else {
+			r.dlq.push(dl)
+			result.Failed++
+		}
+	}
+	return result, nil
+}
+
+// Run redrives the queue every interval until ctx is cancelled, and returns
+// ctx's error.
+func (r *Redriver[T]) Run(ctx context.Context) error {
+	ticker := r.clock.NewTicker(r.options.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C():
+			if _, err := r.Redrive(ctx); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDLQ(t *testing.T) {
+	t.Run("bounded store", func(t *testing.T) {
+		dlq := NewDLQ[int](2)
+		errFail := errors.New("fail")
+
+		dlq.Add(1, errFail, 1)
+		dlq.Add(2, errFail, 1)
+		dlq.Add(3, errFail, 2)
+
+		if dlq.Len() != 2 {
+			t.Errorf("Expected 2 entries, got %d", dlq.Len())
+		}
+		if dlq.Dropped() != 1 {
+			t.Errorf("Expected 1 dropped entry, got %d", dlq.Dropped())
+		}
+
+		entries := dlq.Entries()
+		if entries[0].Item != 2 || entries[1].Item != 3 {
+			t.Errorf("Expected items [2 3], got [%d %d]", entries[0].Item, entries[1].Item)
+		}
+		if entries[1].Attempts != 2 || !errors.Is(entries[1].Err, errFail) {
+			t.Errorf("Unexpected entry: %+v", entries[1])
+		}
+		if entries[0].FirstFailed.IsZero() {
+			t.Error("Expected FirstFailed to be set")
+		}
+	})
+
+	t.Run("range stops early", func(t *testing.T) {
+		dlq := NewDLQ[int](10)
+		for i := 0; i < 5; i++ {
+			dlq.Add(i, errors.New("fail"), 1)
+		}
+
+		seen := 0
+		dlq.Range(func(DeadLetter[int]) bool {
+			seen++
+			return seen < 3
+		})
+		if seen != 3 {
+			t.Errorf("Expected to visit 3 entries, visited %d", seen)
+		}
+	})
+
+	t.Run("redrive", func(t *testing.T) {
+		dlq := NewDLQ[int](10)
+		for i := 0; i < 4; i++ {
+			dlq.Add(i, errors.New("fail"), 1)
+		}
+
+		n, err := dlq.Redrive(context.Background(), func(_ context.Context, v int) error {
+			if v%2 == 0 {
+				return errors.New("still failing")
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if n != 2 {
+			t.Errorf("Expected 2 successes, got %d", n)
+		}
+
+		entries := dlq.Entries()
+		if len(entries) != 2 {
+			t.Fatalf("Expected 2 remaining entries, got %d", len(entries))
+		}
+		for _, e := range entries {
+			if e.Attempts != 2 {
+				t.Errorf("Expected attempts to be incremented to 2, got %d", e.Attempts)
+			}
+		}
+	})
+
+	t.Run("redrive cancelled", func(t *testing.T) {
+		dlq := NewDLQ[int](10)
+		dlq.Add(1, errors.New("fail"), 1)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := dlq.Redrive(ctx, func(context.Context, int) error { return nil })
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+		if dlq.Len() != 1 {
+			t.Errorf("Expected entry to be returned to the queue, got %d entries", dlq.Len())
+		}
+	})
+
+	t.Run("with pool", func(t *testing.T) {
+		dlq := NewDLQ[int](10)
+		pool := NewPool[int, int](2, WithDLQ(dlq, func(_ context.Context, v int) (int, error) {
+			if v%2 == 0 {
+				return 0, errors.New("even")
+			}
+			return v, nil
+		}))
+
+		jobs := make(chan int)
+		go func() {
+			for i := 0; i < 6; i++ {
+				jobs <- i
+			}
+			close(jobs)
+		}()
+
+		count := 0
+		for range pool.Run(context.Background(), jobs) {
+			count++
+		}
+		if count != 3 {
+			t.Errorf("Expected 3 results, got %d", count)
+		}
+		if dlq.Len() != 3 {
+			t.Errorf("Expected 3 dead letters, got %d", dlq.Len())
+		}
+	})
+
+	t.Run("retry records attempts", func(t *testing.T) {
+		dlq := NewDLQ[string](10)
+		config := RetryConfig{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond, Multiplier: 1}
+
+		fn := RetryWithDLQ(dlq, func(context.Context, string) error {
+			return errors.New("boom")
+		}, config)
+
+		if err := fn(context.Background(), "item"); err == nil {
+			t.Fatal("Expected error")
+		}
+		entries := dlq.Entries()
+		if len(entries) != 1 || entries[0].Attempts != 3 {
+			t.Errorf("Expected one entry with 3 attempts, got %+v", entries)
+		}
+	})
+}
+
+func TestRedriver(t *testing.T) {
+	ctx := context.Background()
+	backoff := RetryConfig{BaseDelay: time.Second, MaxDelay: time.Minute, Multiplier: 2}
+
+	t.Run("redrives and parks", func(t *testing.T) {
+		clock := NewFakeClock(time.Now())
+		dlq := NewDLQ[int](10, WithDLQClock(clock))
+		dlq.Add(1, errors.New("boom"), 1)
+		dlq.Add(2, errors.New("boom"), 1)
+
+		r := NewRedriver(dlq, func(_ context.Context, v int) error {
+			if v == 2 {
+				return errors.New("still broken")
+			}
+			return nil
+		}, WithRedriveMaxAttempts(3), WithRedriveBackoff(backoff))
+
+		result, err := r.Redrive(ctx)
+		if err != nil || result.Deferred != 2 {
+			t.Errorf("Expected 2 deferred entries during backoff, got %+v, %v", result, err)
+		}
+
+		clock.Advance(time.Second)
+		result, _ = r.Redrive(ctx)
+		if result.Succeeded != 1 || result.Failed != 1 || dlq.Len() != 1 {
+			t.Errorf("Expected 1 success and 1 failure, got %+v", result)
+		}
+
+		// The second failure doubles the backoff.
+		clock.Advance(time.Second)
+		if result, _ = r.Redrive(ctx); result.Deferred != 1 {
+			t.Errorf("Expected the entry to back off, got %+v", result)
+		}
+		clock.Advance(time.Second)
+		result, _ = r.Redrive(ctx)
+		if result.Parked != 1 || dlq.Len() != 0 {
+			t.Errorf("Expected the entry to be parked, got %+v", result)
+		}
+		parked := r.Parked().Entries()
+		if len(parked) != 1 || parked[0].Item != 2 || parked[0].Attempts != 3 {
+			t.Errorf("Expected item 2 parked after 3 attempts, got %+v", parked)
+		}
+	})
+
+	t.Run("runs on schedule", func(t *testing.T) {
+		clock := NewFakeClock(time.Now())
+		dlq := NewDLQ[int](10, WithDLQClock(clock))
+		dlq.Add(1, errors.New("boom"), 1)
+		done := make(chan int, 1)
+		r := NewRedriver(dlq, func(_ context.Context, v int) error {
+			done <- v
+			return nil
+		}, WithRedriveInterval(time.Minute), WithRedriveClock(clock))
+
+		ctx, cancel := context.WithCancel(ctx)
+		errc := make(chan error, 1)
+		go func() { errc <- r.Run(ctx) }()
+		clock.BlockUntil(1)
+		clock.Advance(time.Minute)
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("Expected a scheduled redrive")
+		}
+		cancel()
+		if err := <-errc; !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	})
+
+	t.Run("cancellation keeps entries", func(t *testing.T) {
+		clock := NewFakeClock(time.Now())
+		dlq := NewDLQ[int](10, WithDLQClock(clock))
+		for i := range 3 {
+			dlq.Add(i, errors.New("boom"), 1)
+		}
+		clock.Advance(2 * time.Second) // past the jittered default backoff
+		ctx, cancel := context.WithCancel(ctx)
+		r := NewRedriver(dlq, func(context.Context, int) error {
+			cancel()
+			return nil
+		})
+		result, err := r.Redrive(ctx)
+		if !errors.Is(err, context.Canceled) || result.Succeeded != 1 || dlq.Len() != 2 {
+			t.Errorf("Expected 1 success and 2 entries kept, got %+v, %v, %d", result, err, dlq.Len())
+		}
+	})
+}
@@ -0,0 +1,128 @@
+package concurrent
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// Watermarked is an item annotated with its event time and the stream's
+// watermark at the moment it was observed. The watermark is the point in
+// event time before which no more items are expected.
+type Watermarked[T any] struct {
+	Item      T
+	EventTime time.Time
+	Watermark time.Time
+}
+
+// EventWindow is a tumbling event-time window emitted by EventTimeWindow.
+type EventWindow[T any] struct {
+	Start time.Time
+	End   time.Time
+	Items []T
+}
+
+// Watermark creates a stage that tags each item with its event time and a
+// watermark trailing the largest event time seen so far by maxOutOfOrder.
+// The watermark never moves backwards.
+func Watermark[T any](eventTime func(T) time.Time, maxOutOfOrder time.Duration) Stage[T, Watermarked[T]] {
+	return func(ctx context.Context, input <-chan T) <-chan Watermarked[T] {
+		output := make(chan Watermarked[T])
+		go func() {
+			defer close(output)
+			var maxSeen time.Time
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case item, ok := <-input:
+					if !ok {
+						return
+					}
+					ts := eventTime(item)
+					if ts.After(maxSeen) {
+						maxSeen = ts
+					}
+					w := Watermarked[T]{Item: item, EventTime: ts, Watermark: maxSeen.Add(-maxOutOfOrder)}
+					select {
+					case <-ctx.Done():
+						return
+					case output <- w:
+					}
+				}
+			}
+		}()
+		return output
+	}
+}
+
+// EventTimeWindow creates a stage that groups watermarked items into tumbling
+// windows of the given size based on event time rather than arrival time.
+// A window is emitted once the watermark passes its end plus allowedLateness;
+// items arriving for a window that has already been emitted are dropped.
+// Remaining windows are emitted in order when the input closes.
+func EventTimeWindow[T any](size, allowedLateness time.Duration) Stage[Watermarked[T], EventWindow[T]] {
+	if size <= 0 {
+		size = time.Second
+	}
+	return func(ctx context.Context, input <-chan Watermarked[T]) <-chan EventWindow[T] {
+		output := make(chan EventWindow[T])
+		go func() {
+			defer close(output)
+			windows := make(map[time.Time]*EventWindow[T])
+			var closedUpTo time.Time // end of the last emitted window
+
+			// emitReady sends windows whose end+lateness is at or before limit,
+			// oldest first, or every window if all is set.
+			emitReady := func(limit time.Time, all bool) bool {
+				starts := make([]time.Time, 0, len(windows))
+				for start, w := range windows {
+					if all || !w.End.Add(allowedLateness).After(limit) {
+						starts = append(starts, start)
+					}
+				}
+				sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+				for _, start := range starts {
+					w := windows[start]
+					delete(windows, start)
+					if w.End.After(closedUpTo) {
+						closedUpTo = w.End
+					}
+					select {
+					case <-ctx.Done():
+						return false
+					case output <- *w:
+					}
+				}
+				return true
+			}
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case item, ok := <-input:
+					if !ok {
+						emitReady(time.Time{}, true)
+						return
+					}
+					start := item.EventTime.Truncate(size)
+					if !closedUpTo.IsZero() && !start.Add(size).After(closedUpTo) {
+						// Too late: its window has already been emitted.
+						continue
+					}
+					w, exists := windows[start]
+					if !exists {
+						w = &EventWindow[T]{Start: start, End: start.Add(size)}
+						windows[start] = w
+					}
+					w.Items = append(w.Items, item.Item)
+					if !emitReady(item.Watermark, false) {
+						return
+					}
+				}
+			}
+		}()
+		return output
+	}
+}
@@ -0,0 +1,64 @@
+package concurrent
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type event struct {
+	At    time.Time
+	Value int
+}
+
+func TestWatermark(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	input := sliceChan(
+		event{At: base.Add(10 * time.Second)},
+		event{At: base.Add(5 * time.Second)},
+		event{At: base.Add(12 * time.Second)},
+	)
+
+	got := Collect(ctx, Watermark(func(e event) time.Time { return e.At }, 2*time.Second)(ctx, input), 0)
+	expected := []time.Duration{8 * time.Second, 8 * time.Second, 10 * time.Second}
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %d items, got %d", len(expected), len(got))
+	}
+	for i, w := range got {
+		if !w.Watermark.Equal(base.Add(expected[i])) {
+			t.Errorf("Item %d: expected watermark %v, got %v", i, base.Add(expected[i]), w.Watermark)
+		}
+	}
+}
+
+func TestEventTimeWindow(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(sec int) event { return event{At: base.Add(time.Duration(sec) * time.Second), Value: sec} }
+
+	// Out-of-order arrivals: 3 belongs to the first window even though it
+	// arrives after 11; 1 arrives after the first window has been emitted.
+	input := sliceChan(at(2), at(11), at(3), at(22), at(1), at(14))
+
+	watermarked := Watermark(func(e event) time.Time { return e.At }, 5*time.Second)(ctx, input)
+	windows := Collect(ctx, EventTimeWindow[event](10*time.Second, 0)(ctx, watermarked), 0)
+
+	if len(windows) != 3 {
+		t.Fatalf("Expected 3 windows, got %d", len(windows))
+	}
+	if len(windows[0].Items) != 2 {
+		t.Errorf("Expected first window to hold 2 items, got %v", windows[0].Items)
+	}
+	if len(windows[1].Items) != 2 {
+		t.Errorf("Expected second window to hold 2 items, got %v", windows[1].Items)
+	}
+	if !windows[2].Start.Equal(base.Add(20 * time.Second)) {
+		t.Errorf("Expected last window to start at 20s, got %v", windows[2].Start)
+	}
+	for i := 1; i < len(windows); i++ {
+		if !windows[i].Start.After(windows[i-1].Start) {
+			t.Error("Windows not emitted in order")
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package concurrent
+
+import (
+	"expvar"
+	"sync"
+)
+
+// ExpvarPublisher publishes component counters and live state through expvar,
+// so they show up on any existing /debug/vars endpoint.
+// It is an Observer: attach it to components to count their events, and use
+// the Publish methods to expose state that is read on demand.
+//
+// Variables are published as a single expvar.Map named after the prefix,
+// holding one map per component.
+type ExpvarPublisher struct {
+	root  *expvar.Map
+	mu    sync.Mutex
+	comps map[string]*expvar.Map
+}
+
+// NewExpvarPublisher creates a publisher under prefix. Publishers created with
+// the same prefix share the underlying expvar.Map.
+func NewExpvarPublisher(prefix string) *ExpvarPublisher {
+	root, ok := expvar.Get(prefix).(*expvar.Map)
+	if !ok {
+		root = expvar.NewMap(prefix)
+	}
+	return &ExpvarPublisher{root: root, comps: make(map[string]*expvar.Map)}
+}
+
+func (p *ExpvarPublisher) component(name string) *expvar.Map {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if m, ok := p.comps[name]; ok {
+		return m
+	}
+	m, ok := p.root.Get(name).(*expvar.Map)
+	if !ok {
+		m = new(expvar.Map).Init()
+		p.root.Set(name, m)
+	}
+	p.comps[name] = m
+	return m
+}
+
+// Observe implements Observer.
+func (p *ExpvarPublisher) Observe(e Event) {
+	m := p.component(e.Component)
+	switch e.Kind {
+	case EventStarted:
+		m.Add("in_flight", 1)
+	case EventCompleted:
+		m.Add("in_flight", -1)
+		if e.Err != nil {
+			m.Add("errors", 1)
+		} else {
+			m.Add("processed", 1)
+		}
+	case EventEmitted:
+		m.Add("processed", 1)
+	case EventThrottled:
+		m.Add("throttled", 1)
+	case EventRejected:
+		m.Add("rejected", 1)
+	case EventStateChanged:
+		// "state" belongs to PublishBreaker, which reads it live.
+		s := new(expvar.String)
+		s.Set(e.State.String())
+		m.Set("last_state", s)
+	case EventQueueDepth:
+		v := new(expvar.Int)
+		v.Set(int64(e.Depth))
+		m.Set("queue_depth", v)
+	}
+}
+
+// PublishFunc publishes the value returned by fn under component.key.
+// fn is called every time the variables are read.
+func (p *ExpvarPublisher) PublishFunc(component, key string, fn func() any) {
+	p.component(component).Set(key, expvar.Func(fn))
+}
+
+// PublishPool publishes the worker count of a pool.
+func (p *ExpvarPublisher) PublishPool(name string, pool interface{ Workers() int }) {
+	p.PublishFunc(name, "workers", func() any { return pool.Workers() })
+}
+
+// PublishLimiter publishes the available tokens of a rate limiter.
+func (p *ExpvarPublisher) PublishLimiter(name string, limiter interface{ Tokens() int }) {
+	p.PublishFunc(name, "tokens", func() any { return limiter.Tokens() })
+}
+
+// PublishBreaker publishes the current state of a circuit breaker.
+func (p *ExpvarPublisher) PublishBreaker(name string, cb *CircuitBreaker) {
+	p.PublishFunc(name, "state", func() any { return cb.State().String() })
+}
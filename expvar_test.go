@@ -0,0 +1,65 @@
+package concurrent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// expvarRuns gives each run of TestExpvarPublisher its own prefix, since
+// expvar variables are process-global and cannot be removed.
+var expvarRuns atomic.Int64
+
+func TestExpvarPublisher(t *testing.T) {
+	prefix := fmt.Sprintf("%s_%d", t.Name(), expvarRuns.Add(1))
+	pub := NewExpvarPublisher(prefix)
+
+	pool := NewPool[int, int](3, func(_ context.Context, v int) (int, error) {
+		if v == 0 {
+			return 0, errors.New("zero")
+		}
+		return v, nil
+	}, WithName("pool"), WithObserver(pub))
+	pub.PublishPool("pool", pool)
+
+	limiter := NewRateLimiter(5, time.Hour)
+	pub.PublishLimiter("limiter", limiter)
+	limiter.Allow()
+
+	cb := NewCircuitBreaker(1, time.Hour, WithBreakerName("breaker"), WithBreakerObserver(pub))
+	pub.PublishBreaker("breaker", cb)
+	_ = cb.Execute(context.Background(), func() error { return errors.New("fail") })
+
+	Drain(context.Background(), pool.Run(context.Background(), sliceChan(0, 1, 2)))
+
+	var vars map[string]map[string]any
+	if err := json.Unmarshal([]byte(expvar.Get(prefix).String()), &vars); err != nil {
+		t.Fatalf("Invalid expvar JSON: %v", err)
+	}
+
+	checks := []struct {
+		component, key string
+		want           any
+	}{
+		{"pool", "workers", float64(3)},
+		{"pool", "processed", float64(2)},
+		{"pool", "errors", float64(1)},
+		{"pool", "in_flight", float64(0)},
+		{"limiter", "tokens", float64(4)},
+		{"breaker", "state", "open"},
+		{"breaker", "last_state", "open"},
+	}
+	for _, c := range checks {
+		if got := vars[c.component][c.key]; got != c.want {
+			t.Errorf("Expected %s.%s = %v, got %v", c.component, c.key, c.want, got)
+		}
+	}
+
+	// A second publisher with the same prefix must not panic.
+	NewExpvarPublisher(prefix)
+}
@@ -2,6 +2,8 @@ package concurrent
 
 import (
 	"context"
+	"fmt"
+	"reflect"
 	"sync"
 )
 
@@ -12,145 +14,122 @@ func FanOut[T any, R any](ctx context.Context, input <-chan T, workers int, fn f
 		workers = 1
 	}
 
-	output := make(chan R)
-	var wg sync.WaitGroup
-
-	// Start workers
-	for i := 0; i < workers; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for {
-				select {
-				case <-ctx.Done():
-					return
-				case item, ok := <-input:
-					if !ok {
-						return
-					}
-					result, err := fn(ctx, item)
-					if err != nil {
-						// Log error or handle as needed
-						continue
-					}
-					select {
-					case <-ctx.Done():
-						return
-					case output <- result:
-					}
-				}
-			}
-		}()
+	sources := make([]<-chan T, workers)
+	for i := range sources {
+		sources[i] = input
 	}
+	return runWorkers(ctx, sources, fn)
+}
 
-	// Close output when all workers are done
-	go func() {
-		wg.Wait()
-		close(output)
-	}()
-
-	return output
+// FanOutR is like FanOut but emits a Result for every item, so failures
+// reach the output channel instead of being dropped.
+func FanOutR[T any, R any](ctx context.Context, input <-chan T, workers int, fn func(context.Context, T) (R, error)) <-chan Result[R] {
+	return FanOut(ctx, input, workers, WrapResults(fn))
 }
 
 // FanIn merges multiple input channels into a single output channel.
 // The output channel is closed when all input channels are closed.
+//
+// Deprecated: Use Merge, or MergeWith to choose a draining strategy.
 func FanIn[T any](ctx context.Context, inputs ...<-chan T) <-chan T {
-	output := make(chan T)
-	var wg sync.WaitGroup
-
-	// Start a goroutine for each input channel
-	for _, input := range inputs {
-		wg.Add(1)
-		go func(ch <-chan T) {
-			defer wg.Done()
-			for {
-				select {
-				case <-ctx.Done():
-					return
-				case item, ok := <-ch:
-					if !ok {
-						return
-					}
-					select {
-					case <-ctx.Done():
-						return
-					case output <- item:
-					}
-				}
-			}
-		}(input)
-	}
-
-	// Close output when all input channels are done
-	go func() {
-		wg.Wait()
-		close(output)
-	}()
-
-	return output
+	return Merge(ctx, inputs...)
 }
 
 // FanOutFanIn combines fan-out and fan-in patterns for parallel processing.
 // It distributes work to multiple workers and then merges the results.
-func FanOutFanIn[T any, R any](ctx context.Context, input <-chan T, workers int, fn func(context.Context, T) (R, error)) <-chan R {
-	// Create intermediate channels for each worker
-	workerChannels := make([]<-chan R, workers)
-
-	// Distribute work to workers
-	for i := 0; i < workers; i++ {
-		workerInput := make(chan T)
-		workerOutput := FanOut(ctx, workerInput, 1, fn)
-		workerChannels[i] = workerOutput
-
-		// Start distributor goroutine for this worker
-		go func(ch chan T) {
-			defer close(ch)
-			for {
-				select {
-				case <-ctx.Done():
+// Without WithWorkerBuffer, workers take items straight from input, as with
+// FanOut, and there are no queues to report to an observer or registry;
+// with it, a single dispatcher hands each item to any worker with room in
+// its queue.
+func FanOutFanIn[T any, R any](ctx context.Context, input <-chan T, workers int, fn func(context.Context, T) (R, error), opts ...FanOption) <-chan R {
+	options := fanOptions(opts)
+	if options.WorkerBuffer <= 0 {
+		return FanOut(ctx, input, workers, fn)
+	}
+	if workers <= 0 {
+		workers = 1
+	}
+	queues := newWorkerQueues[T](workers, options)
+
+	go func() {
+		defer queues.close()
+		// Case layout: done, then one send per worker queue.
+		cases := make([]reflect.SelectCase, 1+workers)
+		cases[0] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())}
+		for i, ch := range queues.chans {
+			cases[1+i] = reflect.SelectCase{Dir: reflect.SelectSend, Chan: reflect.ValueOf(ch)}
+		}
+		next := 0
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case item, ok := <-input:
+				if !ok {
+					return
+				}
+				if i, ok := queues.offer(item, &next); ok {
+					queues.sent(i)
+					continue
+				}
+				// Every queue is full: wait for whichever frees up first.
+				value := reflect.ValueOf(&item).Elem()
+				for i := range queues.chans {
+					cases[1+i].Send = value
+				}
+				chosen, _, _ := reflect.Select(cases)
+				if chosen == 0 {
 					return
-				case item, ok := <-input:
-					if !ok {
-						return
-					}
-					select {
-					case <-ctx.Done():
-						return
-					case ch <- item:
-					}
 				}
+				queues.sent(chosen - 1)
 			}
-		}(workerInput)
-	}
-
-	// Merge all worker outputs
-	return FanIn(ctx, workerChannels...)
+		}
+	}()
+	return runWorkers(ctx, queues.sources(), fn)
 }
 
 // RoundRobin distributes work in round-robin fashion to multiple workers.
-func RoundRobin[T any, R any](ctx context.Context, input <-chan T, workers int, fn func(context.Context, T) (R, error)) <-chan R {
+func RoundRobin[T any, R any](ctx context.Context, input <-chan T, workers int, fn func(context.Context, T) (R, error), opts ...FanOption) <-chan R {
 	if workers <= 0 {
 		workers = 1
 	}
+	next := 0
+	return distribute(ctx, input, workers, fn, func(T) int {
+		i := next
+		next = (next + 1) % workers
+		return i
+	}, fanOptions(opts))
+}
 
-	workerChannels := make([]chan T, workers)
-	workerOutputs := make([]<-chan R, workers)
-
-	// Create worker channels and start workers
-	for i := 0; i < workers; i++ {
-		workerChannels[i] = make(chan T)
-		workerOutputs[i] = FanOut(ctx, workerChannels[i], 1, fn)
+// StickyRoundRobin is like RoundRobin but sends every item with the same key
+// to the same worker, so per-key state kept by fn stays on one goroutine.
+// Keys are assigned to workers round-robin the first time they are seen.
+// The assignment table grows with the number of distinct keys.
+func StickyRoundRobin[T any, R any, K comparable](ctx context.Context, input <-chan T, workers int, keyFn func(T) K, fn func(context.Context, T) (R, error), opts ...FanOption) <-chan R {
+	if workers <= 0 {
+		workers = 1
 	}
+	assigned := make(map[K]int)
+	next := 0
+	return distribute(ctx, input, workers, fn, func(item T) int {
+		key := keyFn(item)
+		i, ok := assigned[key]
+		if !ok {
+			i = next
+			assigned[key] = i
+			next = (next + 1) % workers
+		}
+		return i
+	}, fanOptions(opts))
+}
 
-	// Start distributor
+// distribute runs workers goroutines, each with its own queue, and sends
+// each item to the worker chosen by pick, which is only called from the
+// distributor goroutine.
+func distribute[T any, R any](ctx context.Context, input <-chan T, workers int, fn func(context.Context, T) (R, error), pick func(T) int, options FanOptions) <-chan R {
+	queues := newWorkerQueues[T](workers, options)
 	go func() {
-		defer func() {
-			for _, ch := range workerChannels {
-				close(ch)
-			}
-		}()
-
-		workerIndex := 0
+		defer queues.close()
 		for {
 			select {
 			case <-ctx.Done():
@@ -159,16 +138,135 @@ func RoundRobin[T any, R any](ctx context.Context, input <-chan T, workers int,
 				if !ok {
 					return
 				}
-				select {
-				case <-ctx.Done():
+				if !queues.send(ctx, pick(item), item) {
 					return
-				case workerChannels[workerIndex] <- item:
-					workerIndex = (workerIndex + 1) % workers
 				}
 			}
 		}
 	}()
+	return runWorkers(ctx, queues.sources(), fn)
+}
+
+// runWorkers runs one worker per source, applying fn to its items and
+// sending the results to a shared output, which is closed once every
+// worker has stopped. Items whose fn fails are dropped.
+func runWorkers[T any, R any](ctx context.Context, sources []<-chan T, fn func(context.Context, T) (R, error)) <-chan R {
+	output := make(chan R)
+	var wg sync.WaitGroup
+	wg.Add(len(sources))
+	for _, source := range sources {
+		go func() {
+			defer wg.Done()
+			for {
+				item, ok := Recv(ctx, source)
+				if !ok {
+					return
+				}
+				result, err := fn(ctx, item)
+				if err != nil {
+					continue
+				}
+				if !Send(ctx, output, result) {
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(output)
+	}()
+	return output
+}
+
+func fanOptions(opts []FanOption) FanOptions {
+	var options FanOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// workerQueues holds the per-worker input channels of a fan-out and reports
+// their depth.
+type workerQueues[T any] struct {
+	chans []chan T
+	obs   []observer
+}
+
+func newWorkerQueues[T any](workers int, options FanOptions) *workerQueues[T] {
+	q := &workerQueues[T]{
+		chans: make([]chan T, workers),
+		obs:   make([]observer, workers),
+	}
+	for i := range q.chans {
+		q.chans[i] = make(chan T, max(options.WorkerBuffer, 0))
+		q.obs[i] = observer{name: fmt.Sprintf("%s/worker-%d", options.Name, i), o: options.Observer}
+	}
+	if options.Registry != nil {
+		options.Registry.Register(options.Name, q)
+	}
+	return q
+}
+
+// send queues item for worker i, reporting false if ctx was cancelled first.
+func (q *workerQueues[T]) send(ctx context.Context, i int, item T) bool {
+	if !Send(ctx, q.chans[i], item) {
+		return false
+	}
+	q.sent(i)
+	return true
+}
+
+// sent reports the depth of worker i's queue after an item was queued.
+func (q *workerQueues[T]) sent(i int) {
+	q.obs[i].emit(Event{Kind: EventQueueDepth, Depth: len(q.chans[i])})
+}
+
+// offer queues item for the first worker from *next with room, without
+// blocking, and advances *next past it.
+func (q *workerQueues[T]) offer(item T, next *int) (int, bool) {
+	for k := range q.chans {
+		i := (*next + k) % len(q.chans)
+		select {
+		case q.chans[i] <- item:
+			*next = (i + 1) % len(q.chans)
+			return i, true
+		default:
+		}
+	}
+	return 0, false
+}
+
+// sources returns the worker queues for reading.
+func (q *workerQueues[T]) sources() []<-chan T {
+	sources := make([]<-chan T, len(q.chans))
+	for i, ch := range q.chans {
+		sources[i] = ch
+	}
+	return sources
+}
+
+// close closes every worker queue.
+func (q *workerQueues[T]) close() {
+	for _, ch := range q.chans {
+		close(ch)
+	}
+}
+
+// depths returns the number of items waiting in each worker's channel.
+func (q *workerQueues[T]) depths() []int {
+	depths := make([]int, len(q.chans))
+	for i, ch := range q.chans {
+		depths[i] = len(ch)
+	}
+	return depths
+}
 
-	// Merge all worker outputs using pipeline Merge
-	return FanIn(ctx, workerOutputs...)
+// Report implements Reporter.
+func (q *workerQueues[T]) Report() Report {
+	return Report{Kind: "fan_out", Stats: map[string]any{
+		"workers":     len(q.chans),
+		"queue_depth": q.depths(),
+	}}
 }
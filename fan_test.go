@@ -3,6 +3,10 @@ package concurrent
 import (
 	"context"
 	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -126,6 +130,33 @@ func TestFanOut(t *testing.T) {
 	})
 }
 
+func TestFanOutR(t *testing.T) {
+	ctx := context.Background()
+	input := make(chan int)
+
+	output := FanOutR(ctx, input, 3, func(_ context.Context, v int) (int, error) {
+		if v < 0 {
+			return 0, errors.New("negative")
+		}
+		return v * 2, nil
+	})
+
+	go func() {
+		for _, v := range []int{1, -1, 2, -2, 3} {
+			input <- v
+		}
+		close(input)
+	}()
+
+	values, err := CollectResults(ctx, output)
+	if len(values) != 3 {
+		t.Errorf("Expected 3 values, got %d", len(values))
+	}
+	if err == nil {
+		t.Error("Expected errors for negative inputs")
+	}
+}
+
 func TestFanIn(t *testing.T) {
 	t.Run("basic functionality", func(t *testing.T) {
 		ctx := context.Background()
@@ -221,6 +252,26 @@ func TestFanOutFanIn(t *testing.T) {
 			t.Errorf("Expected 6 results, got %d", len(results))
 		}
 	})
+
+	t.Run("buffered workers", func(t *testing.T) {
+		ctx := context.Background()
+		var active, peak atomic.Int32
+		items := make([]int, 30)
+		output := FanOutFanIn(ctx, emitSlice(ctx, items), 3, func(_ context.Context, v int) (int, error) {
+			n := active.Add(1)
+			for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+			}
+			time.Sleep(time.Millisecond)
+			active.Add(-1)
+			return v, nil
+		}, WithWorkerBuffer(2))
+		if got := Collect(ctx, output, 0); len(got) != 30 {
+			t.Errorf("Expected 30 results, got %d", len(got))
+		}
+		if p := peak.Load(); p < 2 {
+			t.Errorf("Expected workers to run in parallel, got peak %d", p)
+		}
+	})
 }
 
 func TestRoundRobin(t *testing.T) {
@@ -274,6 +325,112 @@ func TestRoundRobin(t *testing.T) {
 	})
 }
 
+func TestStickyRoundRobin(t *testing.T) {
+	t.Run("same key never runs concurrently", func(t *testing.T) {
+		ctx := context.Background()
+		var mu sync.Mutex
+		active := make(map[int]int)
+		overlap := false
+
+		items := make([]int, 40)
+		for i := range items {
+			items[i] = i
+		}
+		output := StickyRoundRobin(ctx, emitSlice(ctx, items), 4, func(v int) int { return v % 5 }, func(_ context.Context, v int) (int, error) {
+			key := v % 5
+			mu.Lock()
+			active[key]++
+			overlap = overlap || active[key] > 1
+			mu.Unlock()
+			time.Sleep(time.Millisecond)
+			mu.Lock()
+			active[key]--
+			mu.Unlock()
+			return v, nil
+		})
+
+		last := make(map[int]int)
+		count := 0
+		for v := range output {
+			count++
+			if prev, ok := last[v%5]; ok && prev > v {
+				t.Errorf("Expected key %d in order, got %d after %d", v%5, v, prev)
+			}
+			last[v%5] = v
+		}
+		if count != len(items) {
+			t.Errorf("Expected %d results, got %d", len(items), count)
+		}
+		if overlap {
+			t.Error("Expected items with the same key to run on one worker")
+		}
+	})
+}
+
+func TestFanWorkerBuffer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reg := NewRegistry()
+	sink := NewTraceSink()
+	gate := make(chan struct{})
+	input := make(chan int)
+
+	output := RoundRobin(ctx, input, 1, func(_ context.Context, v int) (int, error) {
+		<-gate
+		return v, nil
+	}, WithWorkerBuffer(3), WithFanName("rr"), WithFanRegistry(reg), WithFanObserver(sink))
+
+	// One item in fn plus three buffered: none of the sends should block.
+	for i := 1; i <= 4; i++ {
+		select {
+		case input <- i:
+		case <-time.After(time.Second):
+			t.Fatalf("Expected send %d not to block with a buffered worker", i)
+		}
+	}
+	// The last send may still be on its way into the worker queue.
+	deadline := time.Now().Add(time.Second)
+	var depths []int
+	for time.Now().Before(deadline) {
+		depths = reg.Snapshot()["rr"].Stats["queue_depth"].([]int)
+		if depths[0] == 3 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if len(depths) != 1 || depths[0] != 3 {
+		t.Errorf("Expected queue depth [3], got %v", depths)
+	}
+
+	close(gate)
+	close(input)
+	if got := Collect(ctx, output, 0); len(got) != 4 {
+		t.Errorf("Expected 4 results, got %v", got)
+	}
+	if n := sink.Count(TraceStep{Component: "rr/worker-0", Kind: EventQueueDepth}); n != 4 {
+		t.Errorf("Expected 4 queue depth events, got %d", n)
+	}
+}
+
+func TestFanWithoutWorkerBuffer(t *testing.T) {
+	ctx := context.Background()
+	reg := NewRegistry()
+	sink := NewTraceSink()
+
+	output := FanOutFanIn(ctx, sliceChan(1, 2, 3), 2, func(_ context.Context, v int) (int, error) {
+		return v, nil
+	}, WithFanName("fan"), WithFanRegistry(reg), WithFanObserver(sink))
+	if got := Collect(ctx, output, 0); len(got) != 3 {
+		t.Errorf("Expected 3 results, got %v", got)
+	}
+	if _, ok := reg.Snapshot()["fan"]; ok {
+		t.Error("Expected no queues to be registered without a worker buffer")
+	}
+	if n := sink.Count(TraceStep{Component: "fan/worker-0", Kind: EventQueueDepth}); n != 0 {
+		t.Errorf("Expected no queue depth events, got %d", n)
+	}
+}
+
 func BenchmarkFanOut(b *testing.B) {
 	ctx := context.Background()
 
@@ -320,3 +477,28 @@ func BenchmarkFanIn(b *testing.B) {
 		}
 	}
 }
+
+func BenchmarkFanOutFanIn(b *testing.B) {
+	ctx := context.Background()
+	for _, buffer := range []int{0, 4} {
+		b.Run(fmt.Sprintf("buffer=%d", buffer), func(b *testing.B) {
+			var goroutines int
+			for i := 0; i < b.N; i++ {
+				input := make(chan int, 100)
+				before := runtime.NumGoroutine()
+				output := FanOutFanIn(ctx, input, 4, func(_ context.Context, v int) (int, error) {
+					return v * 2, nil
+				}, WithWorkerBuffer(buffer))
+				goroutines = runtime.NumGoroutine() - before
+				for j := 0; j < 100; j++ {
+					input <- j
+				}
+				close(input)
+				for range output {
+					// Consume results
+				}
+			}
+			b.ReportMetric(float64(goroutines), "goroutines")
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package concurrent
+
+import (
+	"context"
+	"sync"
+)
+
+// Push is one step of a compiled Flow. Push passes it an item and reports
+// false once the chain should stop, for example because its context was
+// cancelled; Flush passes on anything the step has buffered at the end of
+// the input.
+type Push[T any] struct {
+	Push  func(T) bool
+	Flush func() bool
+}
+
+// Flow is a chain of element operations compiled into direct function
+// calls, so items pass from one step to the next without channels or
+// goroutines. Given the step that follows it, a Flow returns the step that
+// feeds it. Build flows with MapFlow, FilterFlow, BatchFlow and
+// ComposeFlow, then run them with Stage or ForEach.
+type Flow[T, R any] func(next Push[R]) Push[T]
+
+// MapFlow creates a flow applying fn to each item.
+func MapFlow[T, R any](fn func(T) R) Flow[T, R] {
+	return func(next Push[R]) Push[T] {
+		return Push[T]{
+			Push:  func(item T) bool { return next.Push(fn(item)) },
+			Flush: next.Flush,
+		}
+	}
+}
+
+// FilterFlow creates a flow keeping the items predicate accepts.
+func FilterFlow[T any](predicate func(T) bool) Flow[T, T] {
+	return func(next Push[T]) Push[T] {
+		return Push[T]{
+			Push: func(item T) bool {
+				return !predicate(item) || next.Push(item)
+			},
+			Flush: next.Flush,
+		}
+	}
+}
+
+// BatchFlow creates a flow grouping items into slices of size, passing on
+// a final partial batch at the end of the input. When a flow runs on
+// several workers, each batches the items it receives.
+func BatchFlow[T any](size int) Flow[T, []T] {
+	if size <= 0 {
+		size = 1
+	}
+	return func(next Push[[]T]) Push[T] {
+		batch := make([]T, 0, size)
+		return Push[T]{
+			Push: func(item T) bool {
+				batch = append(batch, item)
+				if len(batch) < size {
+					return true
+				}
+				full := batch
+				batch = make([]T, 0, size)
+				return next.Push(full)
+			},
+			Flush: func() bool {
+				if len(batch) > 0 {
+					full := batch
+					batch = nil
+					if !next.Push(full) {
+						return false
+					}
+				}
+				return next.Flush()
+			},
+		}
+	}
+}
+
+// ComposeFlow chains first and second into a single flow.
+func ComposeFlow[A, B, C any](first Flow[A, B], second Flow[B, C]) Flow[A, C] {
+	return func(next Push[C]) Push[A] {
+		return first(second(next))
+	}
+}
+
+// ForEach runs the flow on items from input across workers goroutines,
+// each with its own compiled chain, calling fn with every item the flow
+// produces. fn must be safe for concurrent use when workers > 1. It returns
+// when input is drained, with nil, or ctx is cancelled, with ctx's error.
+func (f Flow[T, R]) ForEach(ctx context.Context, input <-chan T, workers int, fn func(R)) error {
+	if workers <= 0 {
+		workers = 1
+	}
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for range workers {
+		go func() {
+			defer wg.Done()
+			chain := f(Push[R]{
+				Push: func(r R) bool {
+					fn(r)
+					return ctx.Err() == nil
+				},
+				Flush: func() bool { return true },
+			})
+			runFlow(ctx, chain, input)
+		}()
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+// Stage returns a stage running the flow across workers goroutines, the
+// only channels being the stage's input and output. Item order is not
+// preserved when workers > 1.
+func (f Flow[T, R]) Stage(workers int) Stage[T, R] {
+	if workers <= 0 {
+		workers = 1
+	}
+	return func(ctx context.Context, input <-chan T) <-chan R {
+		output := make(chan R)
+		var wg sync.WaitGroup
+		wg.Add(workers)
+		for range workers {
+			go func() {
+				defer wg.Done()
+				done := ctx.Done()
+				chain := f(Push[R]{
+					Push: func(r R) bool {
+						select {
+						case <-done:
+							return false
+						case output <- r:
+							return true
+						}
+					},
+					Flush: func() bool { return true },
+				})
+				runFlow(ctx, chain, input)
+			}()
+		}
+		go func() {
+			wg.Wait()
+			close(output)
+		}()
+		return output
+	}
+}
+
+// runFlow pushes items from input through chain until input is drained,
+// then flushes it.
+func runFlow[T any](ctx context.Context, chain Push[T], input <-chan T) {
+	done := ctx.Done()
+	for {
+		select {
+		case <-done:
+			return
+		case item, ok := <-input:
+			if !ok {
+				chain.Flush()
+				return
+			}
+			if !chain.Push(item) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package concurrent
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestFlow(t *testing.T) {
+	ctx := context.Background()
+	flow := ComposeFlow(
+		ComposeFlow(
+			MapFlow(func(v int) int { return v * 2 }),
+			FilterFlow(func(v int) bool { return v%3 != 0 }),
+		),
+		BatchFlow[int](2),
+	)
+
+	t.Run("stage", func(t *testing.T) {
+		results := Collect(ctx, flow.Stage(1)(ctx, emitSlice(ctx, []int{1, 2, 3, 4, 5})), 0)
+		if len(results) != 2 || len(results[0]) != 2 || results[0][1] != 4 || len(results[1]) != 2 || results[1][1] != 10 {
+			t.Errorf("Expected [[2 4] [8 10]], got %v", results)
+		}
+	})
+
+	t.Run("for each with workers", func(t *testing.T) {
+		items := make([]int, 1000)
+		for i := range items {
+			items[i] = i
+		}
+		var mu sync.Mutex
+		count := 0
+		err := flow.ForEach(ctx, emitSlice(ctx, items), 4, func(batch []int) {
+			mu.Lock()
+			count += len(batch)
+			mu.Unlock()
+		})
+		// Of 0, 2, ..., 1998, the multiples of 3 (every third) are dropped.
+		if err != nil || count != 666 {
+			t.Errorf("Expected 666 items, got %d, %v", count, err)
+		}
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		input := make(chan int)
+		go func() {
+			for i := 0; ; i++ {
+				select {
+				case input <- i:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+		calls := 0
+		err := MapFlow(func(v int) int { return v }).ForEach(ctx, input, 1, func(int) {
+			if calls++; calls == 10 {
+				cancel()
+			}
+		})
+		if err != context.Canceled || calls != 10 {
+			t.Errorf("Expected to stop after 10 calls, got %d, %v", calls, err)
+		}
+	})
+}
+
+func BenchmarkFlow(b *testing.B) {
+	ctx := context.Background()
+	flow := ComposeFlow(
+		MapFlow(func(v int) int { return v * 2 }),
+		FilterFlow(func(v int) bool { return v > 5 }),
+	)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		input := make(chan int, 100)
+		for j := 0; j < 100; j++ {
+			input <- j
+		}
+		close(input)
+		_ = flow.ForEach(ctx, input, 1, func(int) {})
+	}
+}
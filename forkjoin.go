@@ -0,0 +1,94 @@
+package concurrent
+
+import (
+	"context"
+	"sync"
+)
+
+// ForkJoinPool bounds the goroutines used by divide-and-conquer work. Tasks
+// forked while every slot is busy run inline on the forking goroutine
+// instead of waiting, so tasks that fork and join subtasks never deadlock
+// however deep the recursion goes.
+type ForkJoinPool struct {
+	sem *Semaphore
+}
+
+// NewForkJoinPool creates a pool running at most parallelism forked tasks
+// on their own goroutines at once.
+func NewForkJoinPool(parallelism int) *ForkJoinPool {
+	return &ForkJoinPool{sem: NewSemaphore(parallelism)}
+}
+
+// Fork starts fn on a new goroutine if the pool has a free slot, or runs it
+// inline before returning if not. Join the result with Future.Get.
+func Fork[T any](ctx context.Context, p *ForkJoinPool, fn func(context.Context) (T, error)) *Future[T] {
+	f := newFuture[T](ctx)
+	if !p.sem.TryAcquire() {
+		f.complete(protectValue(ctx, fn))
+		return f
+	}
+	go func() {
+		defer p.sem.Release()
+		f.complete(protectValue(ctx, fn))
+	}()
+	return f
+}
+
+// ForkJoin solves task by recursive decomposition on p. split returns the
+// subtasks of a task, or none if it is small enough to solve directly with
+// solve; the results of a task's subtasks are combined with join. The first
+// error from solve cancels the context passed to the remaining calls and is
+// returned; panics in solve are returned as *PanicError.
+func ForkJoin[T, R any](ctx context.Context, p *ForkJoinPool, task T, split func(T) []T, solve func(context.Context, T) (R, error), join func([]R) R) (R, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var once sync.Once
+	var first error
+
+	var run func(ctx context.Context, task T) (R, error)
+	run = func(ctx context.Context, task T) (R, error) {
+		subtasks := split(task)
+		if len(subtasks) == 0 {
+			r, err := protectValue(ctx, func(ctx context.Context) (R, error) {
+				return solve(ctx, task)
+			})
+			if err != nil {
+				once.Do(func() {
+					first = err
+					cancel()
+				})
+			}
+			return r, err
+		}
+		futures := make([]*Future[R], len(subtasks)-1)
+		for i, sub := range subtasks[:len(subtasks)-1] {
+			futures[i] = Fork(ctx, p, func(ctx context.Context) (R, error) {
+				return run(ctx, sub)
+			})
+		}
+		// The forking goroutine takes the last subtask itself.
+		last, err := run(ctx, subtasks[len(subtasks)-1])
+		results := make([]R, len(subtasks))
+		results[len(results)-1] = last
+		for i, f := range futures {
+			r, ferr := f.Get(context.Background())
+			if ferr != nil && err == nil {
+				err = ferr
+			}
+			results[i] = r
+		}
+		if err != nil {
+			var zero R
+			return zero, err
+		}
+		return join(results), nil
+	}
+	r, err := run(ctx, task)
+	if err != nil {
+		// Report the error that caused the cancellation, not one of the
+		// cancellation errors that followed.
+		once.Do(func() { first = err })
+		return r, first
+	}
+	return r, nil
+}
@@ -0,0 +1,97 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestForkJoin(t *testing.T) {
+	type span struct{ lo, hi int }
+	split := func(s span) []span {
+		if s.hi-s.lo <= 4 {
+			return nil
+		}
+		mid := (s.lo + s.hi) / 2
+		return []span{{s.lo, mid}, {mid, s.hi}}
+	}
+	sum := func(rs []int) int {
+		total := 0
+		for _, r := range rs {
+			total += r
+		}
+		return total
+	}
+
+	t.Run("recursive sum", func(t *testing.T) {
+		for _, parallelism := range []int{1, 4} {
+			p := NewForkJoinPool(parallelism)
+			got, err := ForkJoin(context.Background(), p, span{0, 1000}, split,
+				func(_ context.Context, s span) (int, error) {
+					total := 0
+					for i := s.lo; i < s.hi; i++ {
+						total += i
+					}
+					return total, nil
+				}, sum)
+			if err != nil || got != 499500 {
+				t.Errorf("Expected 499500 with parallelism %d, got %d, %v", parallelism, got, err)
+			}
+		}
+	})
+
+	t.Run("bounded goroutines", func(t *testing.T) {
+		p := NewForkJoinPool(2)
+		var running, peak atomic.Int32
+		_, err := ForkJoin(context.Background(), p, span{0, 256}, split,
+			func(_ context.Context, s span) (int, error) {
+				n := running.Add(1)
+				for q := peak.Load(); n > q && !peak.CompareAndSwap(q, n); q = peak.Load() {
+				}
+				running.Add(-1)
+				return 0, nil
+			}, sum)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		// Two forked goroutines plus the caller's.
+		if q := peak.Load(); q > 3 {
+			t.Errorf("Expected at most 3 concurrent leaves, got %d", q)
+		}
+	})
+
+	t.Run("first error", func(t *testing.T) {
+		boom := errors.New("boom")
+		p := NewForkJoinPool(4)
+		_, err := ForkJoin(context.Background(), p, span{0, 64}, split,
+			func(ctx context.Context, s span) (int, error) {
+				if s.lo == 32 {
+					return 0, boom
+				}
+				return 0, nil
+			}, sum)
+		if err != boom {
+			t.Errorf("Expected boom, got %v", err)
+		}
+	})
+
+	t.Run("fork runs inline when saturated", func(t *testing.T) {
+		p := NewForkJoinPool(1)
+		release := make(chan struct{})
+		busy := Fork(context.Background(), p, func(context.Context) (int, error) {
+			<-release
+			return 1, nil
+		})
+		inline := Fork(context.Background(), p, func(context.Context) (int, error) { return 2, nil })
+		select {
+		case <-inline.Done():
+		default:
+			t.Error("Expected the second fork to run inline")
+		}
+		close(release)
+		if v, _ := busy.Get(context.Background()); v != 1 {
+			t.Errorf("Expected 1, got %d", v)
+		}
+	})
+}
@@ -0,0 +1,196 @@
+package concurrent
+
+import (
+	"context"
+	"sync"
+)
+
+// Executor runs functions asynchronously. Future continuations are handed to
+// an Executor instead of running inline on the goroutine that completed the
+// future, so their parallelism stays under the caller's control.
+type Executor interface {
+	Go(fn func())
+}
+
+// Group is an Executor that runs each function on its own goroutine, with at
+// most limit of them running at once. A limit of zero or less means no limit.
+type Group struct {
+	sem *Semaphore
+	wg  sync.WaitGroup
+}
+
+// NewGroup creates a group running at most limit functions at once.
+func NewGroup(limit int) *Group {
+	g := &Group{}
+	if limit > 0 {
+		g.sem = NewSemaphore(limit)
+	}
+	return g
+}
+
+// Go runs fn on a new goroutine once the group's limit allows it. It never
+// blocks the caller.
+func (g *Group) Go(fn func()) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		if g.sem != nil {
+			g.sem.Acquire(context.Background())
+			defer g.sem.Release()
+		}
+		fn()
+	}()
+}
+
+// SetLimit changes how many functions may run at once. It has no effect on
+// a group created without a limit.
+func (g *Group) SetLimit(n int) {
+	if g.sem != nil {
+		g.sem.SetCapacity(n)
+	}
+}
+
+// Wait blocks until every function started with Go has returned.
+func (g *Group) Wait() {
+	g.wg.Wait()
+}
+
+// Future is the eventual result of an asynchronous computation.
+type Future[T any] struct {
+	ctx       context.Context
+	done      chan struct{}
+	value     T
+	err       error
+	mu        sync.Mutex
+	callbacks []func()
+}
+
+// Async runs fn on exec and returns a future for its result. A nil exec runs
+// fn on a new goroutine. Panics are recovered as *PanicError.
+func Async[T any](ctx context.Context, exec Executor, fn func(context.Context) (T, error)) *Future[T] {
+	f := newFuture[T](ctx)
+	execute(exec, func() {
+		f.complete(protectValue(ctx, fn))
+	})
+	return f
+}
+
+// Resolved returns a future that has already completed with v and err.
+func Resolved[T any](ctx context.Context, v T, err error) *Future[T] {
+	f := newFuture[T](ctx)
+	f.complete(v, err)
+	return f
+}
+
+func newFuture[T any](ctx context.Context) *Future[T] {
+	return &Future[T]{ctx: ctx, done: make(chan struct{})}
+}
+
+// Done returns a channel that is closed when the future completes.
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.done
+}
+
+// Get waits for the future to complete or ctx to be cancelled.
+func (f *Future[T]) Get(ctx context.Context) (T, error) {
+	select {
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	case <-f.done:
+		return f.value, f.err
+	}
+}
+
+// Catch returns a future that recovers from f's error by running fn on exec.
+// If f succeeds, its value is passed through and fn is not called.
+func (f *Future[T]) Catch(exec Executor, fn func(context.Context, error) (T, error)) *Future[T] {
+	next := newFuture[T](f.ctx)
+	f.onDone(exec, func() {
+		if f.err == nil {
+			next.complete(f.value, nil)
+			return
+		}
+		next.complete(protectValue(f.ctx, func(ctx context.Context) (T, error) {
+			return fn(ctx, f.err)
+		}))
+	})
+	return next
+}
+
+// Finally returns a future that runs fn on exec once f completes, then
+// completes with f's result. A panic in fn replaces the result.
+func (f *Future[T]) Finally(exec Executor, fn func()) *Future[T] {
+	next := newFuture[T](f.ctx)
+	f.onDone(exec, func() {
+		if err := protect(f.ctx, func(context.Context) error { fn(); return nil }); err != nil {
+			var zero T
+			next.complete(zero, err)
+			return
+		}
+		next.complete(f.value, f.err)
+	})
+	return next
+}
+
+// Then returns a future for fn applied to f's value, run on exec once f
+// completes. If f fails, its error is passed through and fn is not called.
+// Then is a function rather than a method because it changes the type.
+func Then[T, R any](f *Future[T], exec Executor, fn func(context.Context, T) (R, error)) *Future[R] {
+	next := newFuture[R](f.ctx)
+	f.onDone(exec, func() {
+		if f.err != nil {
+			var zero R
+			next.complete(zero, f.err)
+			return
+		}
+		next.complete(protectValue(f.ctx, func(ctx context.Context) (R, error) {
+			return fn(ctx, f.value)
+		}))
+	})
+	return next
+}
+
+// complete records the result and schedules the continuations.
+func (f *Future[T]) complete(v T, err error) {
+	f.mu.Lock()
+	f.value, f.err = v, err
+	close(f.done)
+	callbacks := f.callbacks
+	f.callbacks = nil
+	f.mu.Unlock()
+	for _, cb := range callbacks {
+		cb()
+	}
+}
+
+// onDone schedules fn on exec once f has completed.
+func (f *Future[T]) onDone(exec Executor, fn func()) {
+	f.mu.Lock()
+	select {
+	case <-f.done:
+		f.mu.Unlock()
+		execute(exec, fn)
+	default:
+		f.callbacks = append(f.callbacks, func() { execute(exec, fn) })
+		f.mu.Unlock()
+	}
+}
+
+// execute runs fn on exec, or on a new goroutine if exec is nil.
+func execute(exec Executor, fn func()) {
+	if exec == nil {
+		go fn()
+		return
+	}
+	exec.Go(fn)
+}
+
+// protectValue calls fn, converting a panic into a *PanicError.
+func protectValue[T any](ctx context.Context, fn func(context.Context) (T, error)) (v T, err error) {
+	err = protect(ctx, func(ctx context.Context) (err error) {
+		v, err = fn(ctx)
+		return err
+	})
+	return v, err
+}
@@ -0,0 +1,118 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingExecutor records how many functions it was handed.
+type countingExecutor struct {
+	n atomic.Int32
+}
+
+func (e *countingExecutor) Go(fn func()) {
+	e.n.Add(1)
+	go fn()
+}
+
+func TestFuture(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("then", func(t *testing.T) {
+		exec := &countingExecutor{}
+		f := Async(ctx, nil, func(context.Context) (int, error) { return 21, nil })
+		s := Then(Then(f, exec, func(_ context.Context, v int) (int, error) {
+			return v * 2, nil
+		}), exec, func(_ context.Context, v int) (string, error) {
+			return strconv.Itoa(v), nil
+		})
+
+		v, err := s.Get(ctx)
+		if err != nil || v != "42" {
+			t.Fatalf("Expected 42, got %q, %v", v, err)
+		}
+		if n := exec.n.Load(); n != 2 {
+			t.Errorf("Expected 2 continuations on the executor, got %d", n)
+		}
+	})
+
+	t.Run("errors skip then", func(t *testing.T) {
+		boom := errors.New("boom")
+		called := false
+		f := Then(Resolved(ctx, 0, boom), nil, func(_ context.Context, v int) (int, error) {
+			called = true
+			return v, nil
+		})
+		if _, err := f.Get(ctx); err != boom {
+			t.Errorf("Expected boom, got %v", err)
+		}
+		if called {
+			t.Error("Expected the continuation to be skipped")
+		}
+	})
+
+	t.Run("catch and finally", func(t *testing.T) {
+		var finally atomic.Bool
+		f := Async(ctx, nil, func(context.Context) (int, error) { panic("boom") }).
+			Catch(nil, func(_ context.Context, err error) (int, error) {
+				var pe *PanicError
+				if !errors.As(err, &pe) {
+					t.Errorf("Expected a PanicError, got %v", err)
+				}
+				return -1, nil
+			}).
+			Finally(nil, func() { finally.Store(true) })
+
+		v, err := f.Get(ctx)
+		if err != nil || v != -1 {
+			t.Errorf("Expected -1, got %d, %v", v, err)
+		}
+		if !finally.Load() {
+			t.Error("Expected Finally to run")
+		}
+	})
+
+	t.Run("get cancelled", func(t *testing.T) {
+		block := make(chan struct{})
+		defer close(block)
+		f := Async(ctx, nil, func(context.Context) (int, error) {
+			<-block
+			return 0, nil
+		})
+		cctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		if _, err := f.Get(cctx); err != context.DeadlineExceeded {
+			t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+		}
+	})
+
+	t.Run("group limits continuations", func(t *testing.T) {
+		g := NewGroup(2)
+		var running, peak atomic.Int32
+		root := Resolved(ctx, 0, nil)
+		futures := make([]*Future[int], 10)
+		for i := range futures {
+			futures[i] = Then(root, g, func(_ context.Context, v int) (int, error) {
+				n := running.Add(1)
+				for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+				}
+				time.Sleep(time.Millisecond)
+				running.Add(-1)
+				return v + i, nil
+			})
+		}
+		g.Wait()
+		for i, f := range futures {
+			if v, _ := f.Get(ctx); v != i {
+				t.Errorf("Expected %d, got %d", i, v)
+			}
+		}
+		if p := peak.Load(); p > 2 {
+			t.Errorf("Expected at most 2 concurrent continuations, got %d", p)
+		}
+	})
+}
@@ -0,0 +1,86 @@
+package concurrent
+
+import (
+	"context"
+	"time"
+)
+
+// Repeat emits values in order, over and over, until ctx is cancelled.
+// With no values the returned channel is closed immediately.
+func Repeat[T any](ctx context.Context, values ...T) <-chan T {
+	output := make(chan T)
+	go func() {
+		defer close(output)
+		if len(values) == 0 {
+			return
+		}
+		for {
+			for _, v := range values {
+				select {
+				case <-ctx.Done():
+					return
+				case output <- v:
+				}
+			}
+		}
+	}()
+	return output
+}
+
+// RepeatFunc emits the result of calling fn until ctx is cancelled.
+func RepeatFunc[T any](ctx context.Context, fn func() T) <-chan T {
+	output := make(chan T)
+	go func() {
+		defer close(output)
+		for {
+			v := fn()
+			select {
+			case <-ctx.Done():
+				return
+			case output <- v:
+			}
+		}
+	}()
+	return output
+}
+
+// Range emits the integers in [start, end) and then closes the channel.
+func Range(ctx context.Context, start, end int) <-chan int {
+	output := make(chan int)
+	go func() {
+		defer close(output)
+		for i := start; i < end; i++ {
+			select {
+			case <-ctx.Done():
+				return
+			case output <- i:
+			}
+		}
+	}()
+	return output
+}
+
+// Tick emits the current time every d until ctx is cancelled.
+// Like time.Ticker, ticks are dropped if the consumer falls behind.
+func Tick(ctx context.Context, d time.Duration, opts ...TimeOption) <-chan time.Time {
+	clock := timeClock(opts)
+	output := make(chan time.Time)
+	go func() {
+		defer close(output)
+		ticker := clock.NewTicker(d)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case t := <-ticker.C():
+				select {
+				case <-ctx.Done():
+					return
+				case output <- t:
+				}
+			}
+		}
+	}()
+	return output
+}
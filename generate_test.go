@@ -0,0 +1,61 @@
+package concurrent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/logimos/concurrent/concurrenttest"
+)
+
+func TestRepeat(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	got := Collect(ctx, Repeat(ctx, "a", "b"), 5)
+	expected := []string{"a", "b", "a", "b", "a"}
+	for i, v := range got {
+		if v != expected[i] {
+			t.Errorf("Expected %q at index %d, got %q", expected[i], i, v)
+		}
+	}
+
+	if got := Collect(ctx, Repeat[int](ctx), 0); len(got) != 0 {
+		t.Errorf("Expected no values, got %v", got)
+	}
+}
+
+func TestRepeatFunc(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	n := 0
+	got := Collect(ctx, RepeatFunc(ctx, func() int { n++; return n }), 3)
+	if len(got) != 3 || got[2] != 3 {
+		t.Errorf("Expected [1 2 3], got %v", got)
+	}
+}
+
+func TestRange(t *testing.T) {
+	ctx := context.Background()
+
+	got := Collect(ctx, Range(ctx, 2, 5), 0)
+	if len(got) != 3 || got[0] != 2 || got[2] != 4 {
+		t.Errorf("Expected [2 3 4], got %v", got)
+	}
+	if got := Collect(ctx, Range(ctx, 5, 5), 0); len(got) != 0 {
+		t.Errorf("Expected empty range, got %v", got)
+	}
+}
+
+func TestTick(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ticks := Tick(ctx, 5*time.Millisecond)
+	if got := Collect(ctx, ticks, 2); len(got) != 2 {
+		t.Errorf("Expected 2 ticks, got %d", len(got))
+	}
+
+	cancel()
+	concurrenttest.AssertClosedWithin(t, ticks, time.Second)
+}
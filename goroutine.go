@@ -0,0 +1,111 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrGoroutineBudget is returned when a goroutine budget with
+// BudgetFailFast is exhausted.
+var ErrGoroutineBudget = errors.New("concurrent: goroutine budget exhausted")
+
+// BudgetPolicy decides what a GoroutineBudget does with spawn requests once
+// it is exhausted.
+type BudgetPolicy int
+
+const (
+	// BudgetWait blocks spawn requests until a goroutine exits or the
+	// context is cancelled.
+	BudgetWait BudgetPolicy = iota
+	// BudgetFailFast rejects spawn requests with ErrGoroutineBudget.
+	BudgetFailFast
+)
+
+// GoroutineBudget caps the goroutines started through it and counts them
+// per component, guarding services against accidentally unbounded pool
+// creation. Install one package-wide with SetGoroutineBudget or for the
+// components of a registry with Registry.SetGoroutineBudget; pools then
+// start their workers through it.
+type GoroutineBudget struct {
+	sem    *Semaphore
+	policy BudgetPolicy
+
+	mu      sync.Mutex
+	running map[string]int
+}
+
+// NewGoroutineBudget creates a budget of limit goroutines.
+func NewGoroutineBudget(limit int, policy BudgetPolicy) *GoroutineBudget {
+	return &GoroutineBudget{sem: NewSemaphore(limit), policy: policy, running: make(map[string]int)}
+}
+
+// Go runs fn on a new goroutine counted against the budget and component,
+// once the budget allows it.
+func (b *GoroutineBudget) Go(ctx context.Context, component string, fn func()) error {
+	if b.policy == BudgetFailFast {
+		if !b.sem.TryAcquire() {
+			return ErrGoroutineBudget
+		}
+	} else if err := b.sem.Acquire(ctx); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	b.running[component]++
+	b.mu.Unlock()
+	go func() {
+		defer b.sem.Release()
+		defer func() {
+			b.mu.Lock()
+			if b.running[component]--; b.running[component] == 0 {
+				delete(b.running, component)
+			}
+			b.mu.Unlock()
+		}()
+		fn()
+	}()
+	return nil
+}
+
+// Count returns the number of goroutines running under the budget.
+func (b *GoroutineBudget) Count() int {
+	return b.sem.InUse()
+}
+
+// Counts returns the number of goroutines running under the budget per
+// component.
+func (b *GoroutineBudget) Counts() map[string]int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return maps.Clone(b.running)
+}
+
+// Report implements Reporter.
+func (b *GoroutineBudget) Report() Report {
+	return Report{Kind: "goroutine_budget", Stats: map[string]any{
+		"limit":      b.sem.Capacity(),
+		"running":    b.Count(),
+		"components": b.Counts(),
+	}}
+}
+
+var globalBudget atomic.Pointer[GoroutineBudget]
+
+// SetGoroutineBudget installs b as the package-wide goroutine budget, used
+// by components whose registry has none. Nil removes it.
+func SetGoroutineBudget(b *GoroutineBudget) {
+	globalBudget.Store(b)
+}
+
+// budgetFor returns the goroutine budget of components registered in reg,
+// or nil if there is none.
+func budgetFor(reg *Registry) *GoroutineBudget {
+	if reg != nil {
+		if b := reg.GoroutineBudget(); b != nil {
+			return b
+		}
+	}
+	return globalBudget.Load()
+}
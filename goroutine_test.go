@@ -0,0 +1,66 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGoroutineBudget(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("counts per component", func(t *testing.T) {
+		b := NewGoroutineBudget(3, BudgetFailFast)
+		block := make(chan struct{})
+		_ = b.Go(ctx, "a", func() { <-block })
+		_ = b.Go(ctx, "a", func() { <-block })
+		_ = b.Go(ctx, "b", func() { <-block })
+		if err := b.Go(ctx, "c", func() {}); !errors.Is(err, ErrGoroutineBudget) {
+			t.Errorf("Expected ErrGoroutineBudget, got %v", err)
+		}
+		if c := b.Counts(); b.Count() != 3 || c["a"] != 2 || c["b"] != 1 {
+			t.Errorf("Expected 2 goroutines for a and 1 for b, got %v", c)
+		}
+		close(block)
+		deadline := time.Now().Add(time.Second)
+		for b.Count() > 0 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		if n := len(b.Counts()); b.Count() != 0 || n != 0 {
+			t.Errorf("Expected no goroutines left, got %d in %d components", b.Count(), n)
+		}
+	})
+
+	t.Run("wait", func(t *testing.T) {
+		b := NewGoroutineBudget(1, BudgetWait)
+		block := make(chan struct{})
+		_ = b.Go(ctx, "a", func() { <-block })
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		if err := b.Go(ctx, "a", func() {}); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected the spawn to wait until the deadline, got %v", err)
+		}
+		close(block)
+	})
+
+	t.Run("registry pools", func(t *testing.T) {
+		reg := NewRegistry()
+		reg.SetGoroutineBudget(NewGoroutineBudget(2, BudgetFailFast))
+		fn := func(_ context.Context, v int) (int, error) { return v, nil }
+
+		small := NewPool(2, fn, WithRegistry(reg))
+		if got := small.RunSlice(ctx, []int{1, 2, 3}); len(got) != 3 {
+			t.Errorf("Expected 3 results, got %v", got)
+		}
+
+		large := NewPool(3, fn, WithRegistry(reg))
+		jobs := make(chan int)
+		results, errs := large.RunE(ctx, jobs)
+		for range results {
+		}
+		if err := <-errs; !errors.Is(err, ErrGoroutineBudget) {
+			t.Errorf("Expected ErrGoroutineBudget, got %v", err)
+		}
+	})
+}
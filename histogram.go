@@ -0,0 +1,121 @@
+package concurrent
+
+import (
+	"context"
+	"time"
+)
+
+// Histogram is a streaming HDR-style histogram of non-negative values, such
+// as latencies in nanoseconds or sizes in bytes. Quantiles are accurate to
+// within about 3%, in constant memory. All methods are safe for concurrent
+// use.
+type Histogram struct {
+	h histogram
+}
+
+// HistogramSnapshot summarizes the values recorded into a Histogram. Start
+// and End are set by Percentiles to the bounds of the window.
+type HistogramSnapshot struct {
+	Start time.Time
+	End   time.Time
+	Count int64
+	Min   int64
+	Max   int64
+	Mean  int64
+	P50   int64
+	P90   int64
+	P95   int64
+	P99   int64
+}
+
+// NewHistogram creates an empty histogram.
+func NewHistogram() *Histogram {
+	return &Histogram{}
+}
+
+// Record adds v to the histogram. Negative values are recorded as zero.
+func (h *Histogram) Record(v int64) {
+	h.h.record(time.Duration(v))
+}
+
+// Quantile returns the value at quantile q (0 < q <= 1).
+func (h *Histogram) Quantile(q float64) int64 {
+	return int64(h.h.quantile(q))
+}
+
+// Count returns the number of values recorded.
+func (h *Histogram) Count() int64 {
+	return h.h.count.Load()
+}
+
+// Snapshot returns a summary of the values recorded so far.
+func (h *Histogram) Snapshot() HistogramSnapshot {
+	s := HistogramSnapshot{Count: h.h.count.Load()}
+	if s.Count == 0 {
+		return s
+	}
+	s.Min = int64(h.h.minValue())
+	s.Max = h.h.max.Load()
+	s.Mean = h.h.sum.Load() / s.Count
+	s.P50 = h.Quantile(0.50)
+	s.P90 = h.Quantile(0.90)
+	s.P95 = h.Quantile(0.95)
+	s.P99 = h.Quantile(0.99)
+	return s
+}
+
+// Percentiles creates a stage that records value(item) for every item into
+// a histogram and emits its snapshot at the end of each tumbling window of
+// the given size, measured on clock (nil means RealClock). Windows without
+// items are skipped; the last, partial window is emitted when the input
+// closes.
+func Percentiles[T any](window time.Duration, value func(T) int64, clock Clock) Stage[T, HistogramSnapshot] {
+	if window <= 0 {
+		window = time.Second
+	}
+	clock = clockOrReal(clock)
+	return func(ctx context.Context, input <-chan T) <-chan HistogramSnapshot {
+		output := make(chan HistogramSnapshot)
+		go func() {
+			defer close(output)
+			ticker := clock.NewTicker(window)
+			defer ticker.Stop()
+
+			h := NewHistogram()
+			start := clock.Now()
+			emit := func(end time.Time) bool {
+				if h.Count() == 0 {
+					start = end
+					return true
+				}
+				s := h.Snapshot()
+				s.Start, s.End = start, end
+				h, start = NewHistogram(), end
+				select {
+				case <-ctx.Done():
+					return false
+				case output <- s:
+					return true
+				}
+			}
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case now := <-ticker.C():
+					if !emit(now) {
+						return
+					}
+				case item, ok := <-input:
+					if !ok {
+						emit(clock.Now())
+						return
+					}
+					h.Record(value(item))
+				}
+			}
+		}()
+		return output
+	}
+}
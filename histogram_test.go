@@ -0,0 +1,55 @@
+package concurrent
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestHistogram(t *testing.T) {
+	h := NewHistogram()
+	for i := int64(1); i <= 1000; i++ {
+		h.Record(i)
+	}
+	s := h.Snapshot()
+	if s.Count != 1000 || s.Min != 1 || s.Max != 1000 || s.Mean != 500 {
+		t.Errorf("Expected count 1000, min 1, max 1000, mean 500, got %+v", s)
+	}
+	within := func(got, want int64) bool {
+		return float64(got) >= float64(want)*0.97 && float64(got) <= float64(want)*1.03
+	}
+	if !within(s.P50, 500) || !within(s.P90, 900) || !within(s.P99, 990) {
+		t.Errorf("Expected p50 ~500, p90 ~900, p99 ~990, got %d, %d, %d", s.P50, s.P90, s.P99)
+	}
+	if empty := NewHistogram().Snapshot(); empty.Count != 0 || empty.P99 != 0 {
+		t.Errorf("Expected an empty snapshot, got %+v", empty)
+	}
+}
+
+func TestPercentiles(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	start := clock.Now()
+	ctx := context.Background()
+	input := make(chan int)
+	output := Percentiles(time.Second, func(v int) int64 { return int64(v) }, clock)(ctx, input)
+	clock.BlockUntil(1)
+
+	for _, v := range []int{10, 20, 30} {
+		input <- v
+	}
+	clock.Advance(time.Second)
+	s := <-output
+	if s.Count != 3 || s.Min != 10 || s.Max != 30 {
+		t.Errorf("Expected 3 values from 10 to 30, got %+v", s)
+	}
+	if !s.Start.Equal(start) || !s.End.Equal(start.Add(time.Second)) {
+		t.Errorf("Expected the first window, got %v to %v", s.Start, s.End)
+	}
+
+	// Empty windows, including the final one, are skipped.
+	clock.Advance(time.Second)
+	close(input)
+	if _, ok := <-output; ok {
+		t.Error("Expected no snapshot for empty windows")
+	}
+}
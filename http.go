@@ -0,0 +1,111 @@
+package concurrent
+
+import (
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// HTTPLimiter is the limiter interface used by RateLimitHandler.
+// RateLimiter and BurstRateLimit implement it.
+type HTTPLimiter interface {
+	Refill()
+	Allow() bool
+	RetryAfter() time.Duration
+}
+
+// RateLimitHandler sheds load beyond l's rate, answering excess requests with
+// 429 Too Many Requests and a Retry-After header.
+func RateLimitHandler(l HTTPLimiter, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l.Refill()
+		if !l.Allow() {
+			reject(w, http.StatusTooManyRequests, l.RetryAfter())
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// KeyedRateLimitHandler applies a separate rate limit to each key returned by
+// key, e.g. ClientIP or HeaderKey("X-API-Key"), answering excess requests
+// with 429 Too Many Requests and a Retry-After header.
+func KeyedRateLimitHandler(l *KeyedRateLimiter, key func(*http.Request) string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		k := key(r)
+		if !l.Allow(k) {
+			reject(w, http.StatusTooManyRequests, l.RetryAfter(k))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// CircuitBreakerHandler runs next through cb. Responses with a 5xx status
+// count as failures; while the breaker is open, requests are answered with
+// 503 Service Unavailable and a Retry-After header without reaching next.
+func CircuitBreakerHandler(cb *CircuitBreaker, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := cb.Execute(r.Context(), func() error {
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(sw, r)
+			if sw.status >= 500 {
+				return errServerError
+			}
+			return nil
+		})
+		if errors.Is(err, ErrCircuitOpen) {
+			reject(w, http.StatusServiceUnavailable, cb.RetryAfter())
+		}
+	})
+}
+
+// errServerError marks a 5xx response as a circuit breaker failure.
+var errServerError = errors.New("concurrent: server error response")
+
+// statusWriter records the status code written through it.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// reject writes status with a Retry-After header of at least one second.
+func reject(w http.ResponseWriter, status int, retryAfter time.Duration) {
+	seconds := max(int(math.Ceil(retryAfter.Seconds())), 1)
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	http.Error(w, http.StatusText(status), status)
+}
+
+// ClientIP returns the host part of r.RemoteAddr, for keying rate limits by
+// client. Behind a proxy, use HeaderKey with the header the proxy sets.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// HeaderKey returns a key function reading the named request header,
+// falling back to ClientIP when the header is absent.
+func HeaderKey(name string) func(*http.Request) string {
+	return func(r *http.Request) string {
+		if v := r.Header.Get(name); v != "" {
+			return v
+		}
+		return ClientIP(r)
+	}
+}
@@ -0,0 +1,90 @@
+package concurrent
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimitHandler(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	t.Run("global", func(t *testing.T) {
+		h := RateLimitHandler(NewRateLimiter(1, time.Minute), ok)
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("Expected first request to pass, got %d", rec.Code)
+		}
+
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+		if rec.Code != http.StatusTooManyRequests {
+			t.Errorf("Expected 429, got %d", rec.Code)
+		}
+		if ra := rec.Header().Get("Retry-After"); ra != "60" {
+			t.Errorf("Expected Retry-After of 60, got %q", ra)
+		}
+	})
+
+	t.Run("keyed", func(t *testing.T) {
+		h := KeyedRateLimitHandler(NewKeyedRateLimiter(1, time.Minute), HeaderKey("X-API-Key"), ok)
+		request := func(key, addr string) int {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = addr
+			if key != "" {
+				req.Header.Set("X-API-Key", key)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			return rec.Code
+		}
+
+		if code := request("a", "10.0.0.1:1"); code != http.StatusOK {
+			t.Errorf("Expected key a to pass, got %d", code)
+		}
+		if code := request("b", "10.0.0.1:1"); code != http.StatusOK {
+			t.Errorf("Expected key b to pass, got %d", code)
+		}
+		if code := request("a", "10.0.0.2:1"); code != http.StatusTooManyRequests {
+			t.Errorf("Expected key a to be limited, got %d", code)
+		}
+		if code := request("", "10.0.0.3:1"); code != http.StatusOK {
+			t.Errorf("Expected fallback to client IP, got %d", code)
+		}
+	})
+}
+
+func TestCircuitBreakerHandler(t *testing.T) {
+	failing := true
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	})
+	clock := NewFakeClock(time.Now())
+	h := CircuitBreakerHandler(NewCircuitBreaker(2, 30*time.Second, WithBreakerClock(clock)), next)
+	serve := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+		return rec
+	}
+
+	serve()
+	serve()
+	rec := serve()
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Expected 503 once the breaker opens, got %d", rec.Code)
+	}
+	if ra := rec.Header().Get("Retry-After"); ra != "30" {
+		t.Errorf("Expected Retry-After of 30, got %q", ra)
+	}
+
+	failing = false
+	clock.Advance(30 * time.Second)
+	if rec := serve(); rec.Code != http.StatusOK {
+		t.Errorf("Expected probe to pass after reset timeout, got %d", rec.Code)
+	}
+}
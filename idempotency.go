@@ -0,0 +1,108 @@
+package concurrent
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// IdempotencyStore records which idempotency keys have been processed, for
+// effectively-once processing of at-least-once input. Backed by a durable
+// store such as Redis or a database table, it also survives restarts.
+type IdempotencyStore interface {
+	// CheckAndSet atomically records key for ttl and reports whether it was
+	// newly recorded. False means key is already recorded and unexpired, so
+	// its item has been, or is being, processed.
+	CheckAndSet(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	// Delete forgets key, so an item whose processing failed can be retried.
+	Delete(ctx context.Context, key string) error
+}
+
+// MemoryIdempotencyStore is an in-process IdempotencyStore. Expired keys are
+// swept out as new keys are recorded.
+type MemoryIdempotencyStore struct {
+	clock Clock
+
+	mu        sync.Mutex
+	expires   map[string]time.Time
+	nextSweep time.Time
+}
+
+// NewMemoryIdempotencyStore creates an empty store timing its ttls with
+// clock (nil means RealClock).
+func NewMemoryIdempotencyStore(clock Clock) *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{clock: clockOrReal(clock), expires: make(map[string]time.Time)}
+}
+
+// CheckAndSet implements IdempotencyStore.
+func (s *MemoryIdempotencyStore) CheckAndSet(_ context.Context, key string, ttl time.Duration) (bool, error) {
+	now := s.clock.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !now.Before(s.nextSweep) {
+		for k, at := range s.expires {
+			if !now.Before(at) {
+				delete(s.expires, k)
+			}
+		}
+		s.nextSweep = now.Add(ttl)
+	}
+	if at, ok := s.expires[key]; ok && now.Before(at) {
+		return false, nil
+	}
+	s.expires[key] = now.Add(ttl)
+	return true, nil
+}
+
+// Delete implements IdempotencyStore.
+func (s *MemoryIdempotencyStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	delete(s.expires, key)
+	s.mu.Unlock()
+	return nil
+}
+
+// Len returns the number of keys recorded, including expired keys not yet
+// swept.
+func (s *MemoryIdempotencyStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.expires)
+}
+
+// SkipProcessed creates a stage that drops items whose idempotency key is
+// already recorded in store, recording the key of every item it lets
+// through for ttl. Items whose key cannot be checked are reported with
+// SendError and dropped, so in ack mode they are nacked for redelivery.
+func SkipProcessed[T any](store IdempotencyStore, key func(T) string, ttl time.Duration) Stage[T, T] {
+	return func(ctx context.Context, input <-chan T) <-chan T {
+		output := make(chan T)
+		go func() {
+			defer close(output)
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case item, ok := <-input:
+					if !ok {
+						return
+					}
+					fresh, err := store.CheckAndSet(ctx, key(item), ttl)
+					if err != nil {
+						SendError(ctx, err)
+						continue
+					}
+					if !fresh {
+						continue
+					}
+					select {
+					case <-ctx.Done():
+						return
+					case output <- item:
+					}
+				}
+			}
+		}()
+		return output
+	}
+}
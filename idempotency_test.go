@@ -0,0 +1,76 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMemoryIdempotencyStore(t *testing.T) {
+	ctx := context.Background()
+	clock := NewFakeClock(time.Now())
+	store := NewMemoryIdempotencyStore(clock)
+
+	if fresh, _ := store.CheckAndSet(ctx, "a", time.Minute); !fresh {
+		t.Error("Expected a new key to be set")
+	}
+	if fresh, _ := store.CheckAndSet(ctx, "a", time.Minute); fresh {
+		t.Error("Expected a recorded key to be rejected")
+	}
+	_ = store.Delete(ctx, "a")
+	if fresh, _ := store.CheckAndSet(ctx, "a", time.Minute); !fresh {
+		t.Error("Expected a deleted key to be set again")
+	}
+
+	clock.Advance(time.Minute)
+	if fresh, _ := store.CheckAndSet(ctx, "b", time.Minute); !fresh {
+		t.Error("Expected a new key to be set")
+	}
+	if n := store.Len(); n != 1 {
+		t.Errorf("Expected the expired key to be swept, got %d keys", n)
+	}
+	if fresh, _ := store.CheckAndSet(ctx, "a", time.Minute); !fresh {
+		t.Error("Expected an expired key to be set again")
+	}
+}
+
+func TestIdempotency(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("stage", func(t *testing.T) {
+		store := NewMemoryIdempotencyStore(nil)
+		stage := SkipProcessed(store, func(v int) string { return fmt.Sprint(v % 3) }, time.Minute)
+		results := Collect(ctx, stage(ctx, emitSlice(ctx, []int{0, 1, 2, 3, 4, 5})), 0)
+		if len(results) != 3 || results[0] != 0 || results[2] != 2 {
+			t.Errorf("Expected [0 1 2], got %v", results)
+		}
+		// The keys survive into a later run, as they would across restarts.
+		if results = Collect(ctx, stage(ctx, emitSlice(ctx, []int{6, 7})), 0); len(results) != 0 {
+			t.Errorf("Expected already processed items skipped, got %v", results)
+		}
+	})
+
+	t.Run("pool", func(t *testing.T) {
+		store := NewMemoryIdempotencyStore(nil)
+		var calls atomic.Int32
+		pool := NewPool(2, func(_ context.Context, v int) (int, error) {
+			if calls.Add(1) == 1 {
+				return 0, errors.New("transient")
+			}
+			return v, nil
+		}, WithIdempotency(store, func(item any) string { return fmt.Sprint(item) }, time.Minute))
+
+		// The first job fails and releases its key, so its retry runs.
+		results := pool.RunSlice(ctx, []int{1})
+		results = append(results, pool.RunSlice(ctx, []int{1, 1})...)
+		if len(results) != 1 || results[0] != 1 {
+			t.Errorf("Expected [1], got %v", results)
+		}
+		if n := calls.Load(); n != 2 {
+			t.Errorf("Expected 2 calls, got %d", n)
+		}
+	})
+}
@@ -0,0 +1,143 @@
+package concurrent
+
+import (
+	"bufio"
+	"context"
+	"io"
+)
+
+// SplitSource turns r into a pipeline source emitting one token per split,
+// e.g. bufio.ScanLines, bufio.ScanWords or ChunkSplit(n). Tokens are copied,
+// so they remain valid after later reads. Tokens larger than
+// bufio.MaxScanTokenSize fail with bufio.ErrTooLong; use LinesSource for
+// input with very long lines.
+//
+// The error channel receives the read error, if any, after the token channel
+// is closed, and is then closed itself; it is buffered, so it need not be read.
+// Cancelling ctx stops the source but does not interrupt a blocked Read.
+func SplitSource(ctx context.Context, r io.Reader, split bufio.SplitFunc) (<-chan []byte, <-chan error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Split(split)
+	return scanSource(ctx, scanner)
+}
+
+// scanSource emits every token from scanner.
+func scanSource(ctx context.Context, scanner *bufio.Scanner) (<-chan []byte, <-chan error) {
+	output := make(chan []byte)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(errc)
+		err := func() error {
+			defer close(output)
+			for scanner.Scan() {
+				token := append([]byte(nil), scanner.Bytes()...)
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case output <- token:
+				}
+			}
+			return scanner.Err()
+		}()
+		if err != nil {
+			errc <- err
+		}
+	}()
+	return output, errc
+}
+
+// ChunkSplit returns a bufio.SplitFunc that splits input into chunks of size
+// bytes; the last chunk may be shorter. Sizes above bufio.MaxScanTokenSize
+// should use ChunkSource instead.
+func ChunkSplit(size int) bufio.SplitFunc {
+	if size <= 0 {
+		size = 4096
+	}
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		if len(data) >= size {
+			return size, data[:size], nil
+		}
+		if atEOF && len(data) > 0 {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	}
+}
+
+// ChunkSource turns r into a pipeline source emitting fixed-size chunks of
+// any size; the last chunk may be shorter. Errors are reported as for SplitSource.
+func ChunkSource(ctx context.Context, r io.Reader, size int) (<-chan []byte, <-chan error) {
+	if size <= 0 {
+		size = 4096
+	}
+	output := make(chan []byte)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(errc)
+		err := func() error {
+			defer close(output)
+			for {
+				chunk := make([]byte, size)
+				n, err := io.ReadFull(r, chunk)
+				if n > 0 {
+					select {
+					case <-ctx.Done():
+						return ctx.Err()
+					case output <- chunk[:n]:
+					}
+				}
+				if err == io.EOF || err == io.ErrUnexpectedEOF {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+			}
+		}()
+		if err != nil {
+			errc <- err
+		}
+	}()
+	return output, errc
+}
+
+// WriteSink writes every item from input to w, serialized by encode, through
+// a buffer of bufSize bytes (4096 if bufSize <= 0). The buffer is flushed when
+// input closes, so callers only need to close input to make all items
+// durable. It returns the first encode, write or flush error, or ctx.Err() if
+// ctx is cancelled first; items buffered before a cancellation are still flushed.
+// On error it stops reading input.
+func WriteSink[T any](ctx context.Context, w io.Writer, input <-chan T, encode func(T) ([]byte, error), bufSize int) error {
+	if bufSize <= 0 {
+		bufSize = 4096
+	}
+	bw := bufio.NewWriterSize(w, bufSize)
+	for {
+		select {
+		case <-ctx.Done():
+			if err := bw.Flush(); err != nil {
+				return err
+			}
+			return ctx.Err()
+		case item, ok := <-input:
+			if !ok {
+				return bw.Flush()
+			}
+			data, err := encode(item)
+			if err != nil {
+				return err
+			}
+			if _, err := bw.Write(data); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// WriteLines writes each string from input to w followed by a newline.
+// It is WriteSink with a line encoder.
+func WriteLines(ctx context.Context, w io.Writer, input <-chan string) error {
+	return WriteSink(ctx, w, input, func(s string) ([]byte, error) {
+		return append([]byte(s), '\n'), nil
+	}, 0)
+}
@@ -0,0 +1,96 @@
+package concurrent
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+type failingReader struct{ err error }
+
+func (r failingReader) Read([]byte) (int, error) { return 0, r.err }
+
+func TestSplitSource(t *testing.T) {
+	t.Run("lines", func(t *testing.T) {
+		ctx := context.Background()
+		out, errc := SplitSource(ctx, strings.NewReader("a\nbb\nccc"), bufio.ScanLines)
+		got := Collect(ctx, out, 0)
+		if len(got) != 3 || string(got[0]) != "a" || string(got[2]) != "ccc" {
+			t.Errorf("Expected [a bb ccc], got %q", got)
+		}
+		if err := <-errc; err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("chunks", func(t *testing.T) {
+		ctx := context.Background()
+		out, _ := SplitSource(ctx, strings.NewReader("abcdefg"), ChunkSplit(3))
+		got := Collect(ctx, out, 0)
+		if len(got) != 3 || string(got[0]) != "abc" || string(got[2]) != "g" {
+			t.Errorf("Expected [abc def g], got %q", got)
+		}
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		boom := errors.New("boom")
+		out, errc := SplitSource(context.Background(), failingReader{boom}, bufio.ScanLines)
+		Drain(context.Background(), out)
+		if err := <-errc; !errors.Is(err, boom) {
+			t.Errorf("Expected read error, got %v", err)
+		}
+	})
+}
+
+func TestChunkSource(t *testing.T) {
+	ctx := context.Background()
+	data := bytes.Repeat([]byte("x"), 100_000)
+	out, errc := ChunkSource(ctx, bytes.NewReader(data), 70_000)
+	got := Collect(ctx, out, 0)
+	if len(got) != 2 || len(got[0]) != 70_000 || len(got[1]) != 30_000 {
+		t.Errorf("Expected chunks of 70000 and 30000 bytes, got %d chunks", len(got))
+	}
+	if err := <-errc; err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+type errWriter struct{ err error }
+
+func (w errWriter) Write([]byte) (int, error) { return 0, w.err }
+
+func TestWriteSink(t *testing.T) {
+	t.Run("flush on close", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := WriteLines(context.Background(), &buf, sliceChan("a", "b")); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if buf.String() != "a\nb\n" {
+			t.Errorf("Expected %q, got %q", "a\nb\n", buf.String())
+		}
+	})
+
+	t.Run("write error", func(t *testing.T) {
+		boom := errors.New("boom")
+		err := WriteLines(context.Background(), errWriter{boom}, sliceChan("a"))
+		if !errors.Is(err, boom) {
+			t.Errorf("Expected write error, got %v", err)
+		}
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		ctx := context.Background()
+		pr, pw := io.Pipe()
+		go func() {
+			pw.CloseWithError(WriteLines(ctx, pw, sliceChan("x", "y", "z")))
+		}()
+		out, _ := SplitSource(ctx, pr, bufio.ScanLines)
+		if got := Collect(ctx, out, 0); len(got) != 3 {
+			t.Errorf("Expected 3 lines, got %q", got)
+		}
+	})
+}
@@ -0,0 +1,100 @@
+package concurrent
+
+import (
+	"context"
+	"iter"
+	"sync"
+)
+
+// FromSeq emits the values of seq on the returned channel, so an iterator
+// can feed a pipeline or pool. Iteration stops early if ctx is cancelled;
+// the channel is closed when seq is exhausted or stopped.
+func FromSeq[T any](ctx context.Context, seq iter.Seq[T]) <-chan T {
+	output := make(chan T)
+	go func() {
+		defer close(output)
+		for item := range seq {
+			if !Send(ctx, output, item) {
+				return
+			}
+		}
+	}()
+	return output
+}
+
+// ToSeq returns an iterator over the items received from ch, ending when ch
+// is closed or ctx is cancelled. Breaking out of the loop leaves ch
+// unread; cancel ctx to release whatever is producing it.
+func ToSeq[T any](ctx context.Context, ch <-chan T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for {
+			item, ok := Recv(ctx, ch)
+			if !ok || !yield(item) {
+				return
+			}
+		}
+	}
+}
+
+// MapSeqConcurrent applies fn to each value of seq with at most n concurrent
+// calls and yields the results in the original order, each with its error.
+// Errors do not abort the others; breaking out of the loop cancels the
+// context passed to in-flight calls and waits for them to return before the
+// loop exits. Cancelling ctx ends the iteration.
+func MapSeqConcurrent[T any, R any](ctx context.Context, seq iter.Seq[T], n int, fn func(context.Context, T) (R, error)) iter.Seq2[R, error] {
+	if n <= 0 {
+		n = 1
+	}
+	type result struct {
+		value R
+		err   error
+	}
+	return func(yield func(R, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		var wg sync.WaitGroup
+		defer wg.Wait()
+
+		// pending holds each started call's result channel in input order.
+		pending := make(chan chan result, n)
+		sem := make(chan struct{}, n)
+		go func() {
+			defer close(pending)
+			for item := range seq {
+				select {
+				case <-ctx.Done():
+					return
+				case sem <- struct{}{}:
+				}
+				done := make(chan result, 1)
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					defer func() { <-sem }()
+					r, err := fn(ctx, item)
+					done <- result{r, err}
+				}()
+				if !Send(ctx, pending, done) {
+					return
+				}
+			}
+		}()
+		defer func() {
+			cancel()
+			for range pending {
+			}
+		}()
+
+		for done := range pending {
+			var res result
+			select {
+			case <-ctx.Done():
+				return
+			case res = <-done:
+			}
+			if !yield(res.value, res.err) {
+				return
+			}
+		}
+	}
+}
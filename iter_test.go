@@ -0,0 +1,118 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFromSeq(t *testing.T) {
+	t.Run("emits all values", func(t *testing.T) {
+		got := Collect(context.Background(), FromSeq(context.Background(), slices.Values([]int{1, 2, 3})), 0)
+		if !slices.Equal(got, []int{1, 2, 3}) {
+			t.Errorf("Expected [1 2 3], got %v", got)
+		}
+	})
+
+	t.Run("stops on cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		stopped := make(chan struct{})
+		infinite := func(yield func(int) bool) {
+			defer close(stopped)
+			for i := 0; yield(i); i++ {
+			}
+		}
+		output := FromSeq(ctx, infinite)
+		<-output
+		cancel()
+		select {
+		case <-stopped:
+		case <-time.After(time.Second):
+			t.Fatal("Expected iteration to stop after cancel")
+		}
+	})
+}
+
+func TestToSeq(t *testing.T) {
+	var got []int
+	for v := range ToSeq(context.Background(), sliceChan(1, 2, 3)) {
+		got = append(got, v)
+	}
+	if !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("Expected [1 2 3], got %v", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for v := range ToSeq(ctx, make(chan int)) {
+		t.Errorf("Expected no values after cancel, got %d", v)
+	}
+}
+
+func TestMapSeqConcurrent(t *testing.T) {
+	t.Run("ordered results with errors", func(t *testing.T) {
+		errOdd := errors.New("odd")
+		var got []int
+		var errs int
+		seq := MapSeqConcurrent(context.Background(), slices.Values([]int{5, 4, 3, 2, 1}), 3, func(ctx context.Context, v int) (int, error) {
+			time.Sleep(time.Duration(v) * time.Millisecond)
+			if v%2 == 1 {
+				return 0, errOdd
+			}
+			return v * 10, nil
+		})
+		for v, err := range seq {
+			if err != nil {
+				errs++
+				continue
+			}
+			got = append(got, v)
+		}
+		if !slices.Equal(got, []int{40, 20}) || errs != 3 {
+			t.Errorf("Expected [40 20] and 3 errors, got %v and %d", got, errs)
+		}
+	})
+
+	t.Run("bounded concurrency", func(t *testing.T) {
+		var active, peak atomic.Int32
+		seq := MapSeqConcurrent(context.Background(), slices.Values(make([]int, 20)), 2, func(ctx context.Context, v int) (int, error) {
+			n := active.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			active.Add(-1)
+			return v, nil
+		})
+		for range seq {
+		}
+		if peak.Load() > 2 {
+			t.Errorf("Expected at most 2 concurrent calls, got %d", peak.Load())
+		}
+	})
+
+	t.Run("break cancels in-flight", func(t *testing.T) {
+		var started, returned atomic.Int32
+		seq := MapSeqConcurrent(context.Background(), slices.Values([]int{0, 1, 2, 3}), 4, func(ctx context.Context, v int) (int, error) {
+			started.Add(1)
+			defer returned.Add(1)
+			if v == 0 {
+				return v, nil
+			}
+			<-ctx.Done()
+			return 0, ctx.Err()
+		})
+		for range seq {
+			break
+		}
+		if started.Load() != returned.Load() {
+			t.Errorf("Expected all %d started calls to have returned, got %d", started.Load(), returned.Load())
+		}
+	})
+}
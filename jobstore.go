@@ -0,0 +1,212 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrUnknownLease is returned when settling a job that is not leased, for
+// example because it was already acked.
+var ErrUnknownLease = errors.New("concurrent: unknown lease")
+
+// Lease is a job taken from a JobStore. It stays invisible to other
+// consumers until Deadline; a job neither acked nor nacked by then is leased
+// again.
+type Lease[T any] struct {
+	ID       string
+	Job      T
+	Attempts int // leases of this job so far, including this one
+	Deadline time.Time
+}
+
+// JobStore is a durable job queue with visibility timeouts, such as a table
+// in a SQL database or a cloud queue, for pools that must survive process
+// restarts. Jobs leased but never settled, because their consumer crashed,
+// become visible again once their visibility timeout expires.
+type JobStore[T any] interface {
+	// Enqueue adds job to the queue.
+	Enqueue(ctx context.Context, job T) error
+	// Lease blocks until a job is visible, or ctx is done, and hides it from
+	// other consumers for the visibility timeout.
+	Lease(ctx context.Context, visibility time.Duration) (Lease[T], error)
+	// Ack removes a leased job from the queue.
+	Ack(ctx context.Context, id string) error
+	// Nack makes a leased job visible again after delay.
+	Nack(ctx context.Context, id string, delay time.Duration) error
+}
+
+// StorePool is a Pool that takes its jobs from a JobStore instead of a
+// channel, acking each job once fn succeeds and nacking it, with backoff,
+// when fn fails.
+type StorePool[T any, R any] struct {
+	pool       *Pool[Lease[T], R]
+	store      JobStore[T]
+	visibility time.Duration
+}
+
+// NewStorePool creates a StorePool with n workers processing jobs from store
+// with fn. visibility is how long a worker may hold a job before it is
+// redelivered; it should comfortably exceed fn's longest run. Pool options
+// that take an item receive the job's Lease.
+func NewStorePool[T any, R any](n int, fn func(context.Context, T) (R, error), store JobStore[T], visibility time.Duration, opts ...PoolOption) *StorePool[T, R] {
+	if visibility <= 0 {
+		visibility = 30 * time.Second
+	}
+	backoff := DefaultRetryConfig()
+	return &StorePool[T, R]{
+		pool: NewPool(n, func(ctx context.Context, l Lease[T]) (R, error) {
+			r, err := fn(ctx, l.Job)
+			// A failed settle leaves the job to be redelivered when its
+			// lease expires, which at-least-once processing tolerates.
+			settleCtx := context.WithoutCancel(ctx)
+			if err != nil {
+				_ = store.Nack(settleCtx, l.ID, backoffDelay(l.Attempts-1, backoff))
+			} else {
+				_ = store.Ack(settleCtx, l.ID)
+			}
+			return r, err
+		}, opts...),
+		store:      store,
+		visibility: visibility,
+	}
+}
+
+// Run leases and processes jobs until ctx is canceled. One job is leased
+// ahead of the workers. The error channel receives the first Lease error
+// other than ctx's, which stops the pool; it is buffered, so it need not be
+// read. The caller MUST consume the results channel until it is closed.
+func (p *StorePool[T, R]) Run(ctx context.Context) (<-chan R, <-chan error) {
+	ctx, cancel := context.WithCancel(ctx)
+	leases := make(chan Lease[T])
+	errc := make(chan error, 1)
+	go func() {
+		defer close(errc)
+		defer close(leases)
+		defer cancel()
+		for {
+			l, err := p.store.Lease(ctx, p.visibility)
+			if err != nil {
+				if ctx.Err() == nil {
+					errc <- err
+				}
+				return
+			}
+			select {
+			case <-ctx.Done():
+				_ = p.store.Nack(context.WithoutCancel(ctx), l.ID, 0)
+				return
+			case leases <- l:
+			}
+		}
+	}()
+	return p.pool.Run(ctx, leases), errc
+}
+
+// MemoryJobStore is an in-process JobStore for tests and as a reference
+// implementation. It does not survive restarts.
+type MemoryJobStore[T any] struct {
+	clock Clock
+
+	mu      sync.Mutex
+	jobs    []*memoryJob[T] // in enqueue order
+	nextID  int
+	changed chan struct{} // closed when a job may have become visible
+}
+
+type memoryJob[T any] struct {
+	id        string
+	job       T
+	attempts  int
+	visibleAt time.Time
+}
+
+// NewMemoryJobStore creates an empty store timing visibility with clock (nil
+// means RealClock).
+func NewMemoryJobStore[T any](clock Clock) *MemoryJobStore[T] {
+	return &MemoryJobStore[T]{clock: clockOrReal(clock), changed: make(chan struct{})}
+}
+
+// Enqueue implements JobStore.
+func (s *MemoryJobStore[T]) Enqueue(_ context.Context, job T) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	s.jobs = append(s.jobs, &memoryJob[T]{id: strconv.Itoa(s.nextID), job: job})
+	s.notifyLocked()
+	return nil
+}
+
+// Lease implements JobStore.
+func (s *MemoryJobStore[T]) Lease(ctx context.Context, visibility time.Duration) (Lease[T], error) {
+	for {
+		s.mu.Lock()
+		now := s.clock.Now()
+		var next time.Time
+		for _, j := range s.jobs {
+			if !now.Before(j.visibleAt) {
+				j.attempts++
+				j.visibleAt = now.Add(visibility)
+				s.mu.Unlock()
+				return Lease[T]{ID: j.id, Job: j.job, Attempts: j.attempts, Deadline: j.visibleAt}, nil
+			}
+			if next.IsZero() || j.visibleAt.Before(next) {
+				next = j.visibleAt
+			}
+		}
+		changed := s.changed
+		s.mu.Unlock()
+
+		var timer <-chan time.Time
+		if !next.IsZero() {
+			timer = s.clock.After(next.Sub(now))
+		}
+		select {
+		case <-ctx.Done():
+			return Lease[T]{}, ctx.Err()
+		case <-changed:
+		case <-timer:
+		}
+	}
+}
+
+// Ack implements JobStore.
+func (s *MemoryJobStore[T]) Ack(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, j := range s.jobs {
+		if j.id == id && j.attempts > 0 {
+			s.jobs = append(s.jobs[:i], s.jobs[i+1:]...)
+			return nil
+		}
+	}
+	return ErrUnknownLease
+}
+
+// Nack implements JobStore.
+func (s *MemoryJobStore[T]) Nack(_ context.Context, id string, delay time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, j := range s.jobs {
+		if j.id == id && j.attempts > 0 {
+			j.visibleAt = s.clock.Now().Add(delay)
+			s.notifyLocked()
+			return nil
+		}
+	}
+	return ErrUnknownLease
+}
+
+// Len returns the number of jobs in the store, leased or not.
+func (s *MemoryJobStore[T]) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.jobs)
+}
+
+func (s *MemoryJobStore[T]) notifyLocked() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
@@ -0,0 +1,97 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMemoryJobStore(t *testing.T) {
+	ctx := context.Background()
+	clock := NewFakeClock(time.Now())
+	store := NewMemoryJobStore[string](clock)
+	_ = store.Enqueue(ctx, "a")
+	_ = store.Enqueue(ctx, "b")
+
+	first, _ := store.Lease(ctx, time.Minute)
+	second, _ := store.Lease(ctx, time.Minute)
+	if first.Job != "a" || second.Job != "b" || first.Attempts != 1 {
+		t.Errorf("Expected a then b on their first attempt, got %+v and %+v", first, second)
+	}
+	if err := store.Ack(ctx, first.ID); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := store.Ack(ctx, first.ID); !errors.Is(err, ErrUnknownLease) {
+		t.Errorf("Expected ErrUnknownLease, got %v", err)
+	}
+
+	t.Run("visibility timeout", func(t *testing.T) {
+		leased := make(chan Lease[string])
+		go func() {
+			l, _ := store.Lease(ctx, time.Minute)
+			leased <- l
+		}()
+		clock.BlockUntil(1)
+		clock.Advance(time.Minute)
+		if l := <-leased; l.ID != second.ID || l.Attempts != 2 {
+			t.Errorf("Expected b redelivered on its second attempt, got %+v", l)
+		}
+	})
+
+	t.Run("nack", func(t *testing.T) {
+		_ = store.Enqueue(ctx, "c")
+		l, _ := store.Lease(ctx, time.Minute)
+		if l.Job != "c" {
+			t.Fatalf("Expected c, got %+v", l)
+		}
+		_ = store.Nack(ctx, l.ID, 0)
+		if again, _ := store.Lease(ctx, time.Minute); again.ID != l.ID {
+			t.Errorf("Expected c redelivered after nack, got %+v", again)
+		}
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		if _, err := store.Lease(ctx, time.Minute); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	})
+}
+
+func TestStorePool(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := NewMemoryJobStore[int](nil)
+	for i := 1; i <= 10; i++ {
+		_ = store.Enqueue(ctx, i)
+	}
+
+	var failed atomic.Bool
+	pool := NewStorePool(3, func(_ context.Context, v int) (int, error) {
+		if v == 5 && failed.CompareAndSwap(false, true) {
+			return 0, errors.New("transient")
+		}
+		return v * 2, nil
+	}, store, time.Minute)
+
+	results, errc := pool.Run(ctx)
+	sum := 0
+	for range 10 {
+		sum += <-results
+	}
+	if sum != 110 {
+		t.Errorf("Expected every job processed once, got sum %d", sum)
+	}
+	cancel()
+	for range results {
+	}
+	if err := <-errc; err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if n := store.Len(); n != 0 {
+		t.Errorf("Expected every job acked, got %d left", n)
+	}
+}
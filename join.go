@@ -0,0 +1,113 @@
+package concurrent
+
+import (
+	"context"
+	"time"
+)
+
+// Joined is a pair of items matched by Join.
+type Joined[L any, R any] struct {
+	Left  L
+	Right R
+}
+
+type joinEntry[T any] struct {
+	item    T
+	arrived time.Time
+}
+
+// Join pairs items from left and right that share a key and arrive within
+// window of each other. Every match is emitted, so a key seen several times on
+// both sides produces every combination inside the window. Items that find no
+// partner within window are discarded.
+// The output channel is closed when both inputs are closed or ctx is cancelled.
+func Join[L any, R any, K comparable](ctx context.Context, left <-chan L, right <-chan R, leftKey func(L) K, rightKey func(R) K, window time.Duration, opts ...TimeOption) <-chan Joined[L, R] {
+	clock := timeClock(opts)
+	output := make(chan Joined[L, R])
+	go func() {
+		defer close(output)
+
+		lefts := make(map[K][]joinEntry[L])
+		rights := make(map[K][]joinEntry[R])
+
+		sweepEvery := window / 2
+		if sweepEvery <= 0 {
+			sweepEvery = time.Millisecond
+		}
+		ticker := clock.NewTicker(sweepEvery)
+		defer ticker.Stop()
+
+		emit := func(matches []Joined[L, R]) bool {
+			for _, m := range matches {
+				select {
+				case <-ctx.Done():
+					return false
+				case output <- m:
+				}
+			}
+			return true
+		}
+
+		for left != nil || right != nil {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C():
+				expireJoinEntries(lefts, now, window)
+				expireJoinEntries(rights, now, window)
+			case l, ok := <-left:
+				if !ok {
+					left = nil
+					continue
+				}
+				now := clock.Now()
+				k := leftKey(l)
+				var matches []Joined[L, R]
+				for _, r := range liveJoinEntries(rights, k, now, window) {
+					matches = append(matches, Joined[L, R]{Left: l, Right: r.item})
+				}
+				lefts[k] = append(lefts[k], joinEntry[L]{item: l, arrived: now})
+				if !emit(matches) {
+					return
+				}
+			case r, ok := <-right:
+				if !ok {
+					right = nil
+					continue
+				}
+				now := clock.Now()
+				k := rightKey(r)
+				var matches []Joined[L, R]
+				for _, l := range liveJoinEntries(lefts, k, now, window) {
+					matches = append(matches, Joined[L, R]{Left: l.item, Right: r})
+				}
+				rights[k] = append(rights[k], joinEntry[R]{item: r, arrived: now})
+				if !emit(matches) {
+					return
+				}
+			}
+		}
+	}()
+	return output
+}
+
+// liveJoinEntries prunes expired entries for key and returns the rest.
+func liveJoinEntries[K comparable, T any](m map[K][]joinEntry[T], key K, now time.Time, window time.Duration) []joinEntry[T] {
+	entries := m[key]
+	i := 0
+	for i < len(entries) && now.Sub(entries[i].arrived) > window {
+		i++
+	}
+	if i == len(entries) {
+		delete(m, key)
+		return nil
+	}
+	m[key] = entries[i:]
+	return entries[i:]
+}
+
+func expireJoinEntries[K comparable, T any](m map[K][]joinEntry[T], now time.Time, window time.Duration) {
+	for key := range m {
+		liveJoinEntries(m, key, now, window)
+	}
+}
@@ -0,0 +1,99 @@
+package concurrent
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type order struct {
+	ID     int
+	Amount int
+}
+
+type customer struct {
+	OrderID int
+	Name    string
+}
+
+func TestJoin(t *testing.T) {
+	t.Run("matches within window", func(t *testing.T) {
+		ctx := context.Background()
+		orders := make(chan order)
+		customers := make(chan customer)
+
+		output := Join(ctx, orders, customers,
+			func(o order) int { return o.ID },
+			func(c customer) int { return c.OrderID },
+			time.Second,
+		)
+
+		go func() {
+			orders <- order{ID: 1, Amount: 10}
+			customers <- customer{OrderID: 2, Name: "bob"}
+			customers <- customer{OrderID: 1, Name: "alice"}
+			orders <- order{ID: 2, Amount: 20}
+			orders <- order{ID: 3, Amount: 30}
+			close(orders)
+			close(customers)
+		}()
+
+		got := Collect(ctx, output, 0)
+		if len(got) != 2 {
+			t.Fatalf("Expected 2 joined pairs, got %v", got)
+		}
+		for _, j := range got {
+			if j.Left.ID != j.Right.OrderID {
+				t.Errorf("Mismatched pair: %+v", j)
+			}
+		}
+	})
+
+	t.Run("expires outside window", func(t *testing.T) {
+		ctx := context.Background()
+		left := make(chan int)
+		right := make(chan int)
+		identity := func(v int) int { return v }
+
+		output := Join(ctx, left, right, identity, identity, 10*time.Millisecond)
+
+		go func() {
+			left <- 1
+			time.Sleep(30 * time.Millisecond)
+			right <- 1
+			close(left)
+			close(right)
+		}()
+
+		if got := Collect(ctx, output, 0); len(got) != 0 {
+			t.Errorf("Expected no matches outside the window, got %v", got)
+		}
+	})
+
+	t.Run("uses the configured clock", func(t *testing.T) {
+		ctx := context.Background()
+		clock := NewFakeClock(time.Now())
+		left := make(chan int)
+		right := make(chan int)
+		identity := func(v int) int { return v }
+
+		output := Join(ctx, left, right, identity, identity, time.Minute, WithClock(clock))
+
+		go func() {
+			left <- 1
+			// Once this send is taken, the first item has been stamped.
+			right <- 99
+			clock.Advance(2 * time.Minute)
+			right <- 1
+			left <- 2
+			right <- 2
+			close(left)
+			close(right)
+		}()
+
+		got := Collect(ctx, output, 0)
+		if len(got) != 1 || got[0].Left != 2 || got[0].Right != 2 {
+			t.Errorf("Expected only the pair within the window, got %v", got)
+		}
+	})
+}
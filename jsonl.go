@@ -0,0 +1,108 @@
+package concurrent
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// JSONLSource decodes r as JSON lines, emitting one T per non-blank line.
+// A line that fails to decode is reported on the error channel, annotated
+// with its line number, and the stream continues with the next line; read
+// errors, including lines over the maximum length (see LinesSource and opts),
+// are reported last and end the stream.
+//
+// Both channels are closed when r is exhausted or ctx is cancelled.
+// The caller MUST consume both channels, otherwise the source blocks.
+func JSONLSource[T any](ctx context.Context, r io.Reader, opts ...LinesOption) (<-chan T, <-chan error) {
+	values := make(chan T)
+	errs := make(chan error)
+	go func() {
+		defer close(values)
+		defer close(errs)
+		lines, lineErr := LinesSource[[]byte](ctx, r, opts...)
+		sendErr := func(err error) bool {
+			select {
+			case <-ctx.Done():
+				return false
+			case errs <- err:
+				return true
+			}
+		}
+
+		lineNo := 0
+		for line := range lines {
+			lineNo++
+			if len(line) == 0 {
+				continue
+			}
+			var v T
+			if err := json.Unmarshal(line, &v); err != nil {
+				if !sendErr(fmt.Errorf("line %d: %w", lineNo, err)) {
+					Drain(context.Background(), lines)
+					return
+				}
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				Drain(context.Background(), lines)
+				return
+			case values <- v:
+			}
+		}
+		if err := <-lineErr; err != nil && err != ctx.Err() {
+			sendErr(err)
+		}
+	}()
+	return values, errs
+}
+
+// JSONLSink encodes every item from input as a JSON line written to w through
+// a buffer that is flushed when input closes or ctx is cancelled. An item that
+// fails to encode is reported on the returned channel and skipped; a write
+// error is reported and stops the sink.
+//
+// The returned channel is closed once everything written has been flushed.
+// The caller MUST consume it until it is closed.
+func JSONLSink[T any](ctx context.Context, w io.Writer, input <-chan T) <-chan error {
+	errs := make(chan error)
+	go func() {
+		defer close(errs)
+		bw := bufio.NewWriter(w)
+		report := func(err error) {
+			select {
+			case <-ctx.Done():
+			case errs <- err:
+			}
+		}
+		defer func() {
+			if err := bw.Flush(); err != nil {
+				report(err)
+			}
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case item, ok := <-input:
+				if !ok {
+					return
+				}
+				data, err := json.Marshal(item)
+				if err != nil {
+					report(err)
+					continue
+				}
+				if _, err := bw.Write(append(data, '\n')); err != nil {
+					report(err)
+					return
+				}
+			}
+		}
+	}()
+	return errs
+}
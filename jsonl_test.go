@@ -0,0 +1,89 @@
+package concurrent
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+type jsonRecord struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestJSONLSource(t *testing.T) {
+	ctx := context.Background()
+	input := `{"id":1,"name":"a"}
+not json
+
+{"id":2,"name":"b"}
+`
+	values, errs := JSONLSource[jsonRecord](ctx, strings.NewReader(input))
+
+	var got []jsonRecord
+	var gotErrs []error
+	for values != nil || errs != nil {
+		select {
+		case v, ok := <-values:
+			if !ok {
+				values = nil
+				continue
+			}
+			got = append(got, v)
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			gotErrs = append(gotErrs, err)
+		}
+	}
+
+	if len(got) != 2 || got[0].ID != 1 || got[1].Name != "b" {
+		t.Errorf("Expected both valid records, got %+v", got)
+	}
+	if len(gotErrs) != 1 || !strings.Contains(gotErrs[0].Error(), "line 2") {
+		t.Errorf("Expected one decode error on line 2, got %v", gotErrs)
+	}
+}
+
+func TestJSONLSink(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		ctx := context.Background()
+		var buf bytes.Buffer
+		errs := JSONLSink(ctx, &buf, sliceChan(jsonRecord{1, "a"}, jsonRecord{2, "b"}))
+		if n := Drain(ctx, errs); n != 0 {
+			t.Fatalf("Expected no errors, got %d", n)
+		}
+
+		values, decodeErrs := JSONLSource[jsonRecord](ctx, &buf)
+		go Drain(ctx, decodeErrs)
+		if got := Collect(ctx, values, 0); len(got) != 2 || got[1].ID != 2 {
+			t.Errorf("Expected records to round trip, got %+v", got)
+		}
+	})
+
+	t.Run("bad record", func(t *testing.T) {
+		ctx := context.Background()
+		var buf bytes.Buffer
+		errs := Collect(ctx, JSONLSink(ctx, &buf, sliceChan(1.0, math.Inf(1), 2.0)), 0)
+		if len(errs) != 1 {
+			t.Errorf("Expected one encode error, got %v", errs)
+		}
+		if buf.String() != "1\n2\n" {
+			t.Errorf("Expected good records to be written, got %q", buf.String())
+		}
+	})
+
+	t.Run("write error", func(t *testing.T) {
+		ctx := context.Background()
+		boom := errors.New("boom")
+		errs := Collect(ctx, JSONLSink(ctx, errWriter{boom}, sliceChan(1)), 0)
+		if len(errs) != 1 || !errors.Is(errs[0], boom) {
+			t.Errorf("Expected write error, got %v", errs)
+		}
+	})
+}
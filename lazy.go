@@ -0,0 +1,111 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// LazyValue computes a value on first use and caches it for later calls.
+// Unlike sync.Once, a waiting caller can give up via its context, and a lazy
+// value created with NewLazyValueRetry retries initialization on the next call
+// after a failure instead of latching the error forever.
+type LazyValue[T any] struct {
+	mu      sync.Mutex
+	init    func(context.Context) (T, error)
+	retry   bool
+	done    bool
+	value   T
+	err     error
+	running chan struct{}
+}
+
+// NewLazyValue creates a lazy value whose first result, success or failure,
+// is returned to every subsequent caller. A context.Canceled or
+// context.DeadlineExceeded error is not kept, since it reflects the caller
+// that ran init rather than init itself; the next call to Get runs init again.
+func NewLazyValue[T any](init func(context.Context) (T, error)) *LazyValue[T] {
+	return &LazyValue[T]{init: init}
+}
+
+// NewLazyValueRetry creates a lazy value that only caches a successful result.
+// A failed initialization is reported to the callers waiting on it, and the
+// next call to Get runs init again.
+func NewLazyValueRetry[T any](init func(context.Context) (T, error)) *LazyValue[T] {
+	return &LazyValue[T]{init: init, retry: true}
+}
+
+// Get returns the value, running init if it has not completed yet.
+// Only one init runs at a time; concurrent callers wait for it or for their
+// own ctx to be cancelled. init runs with the context of the caller that started it;
+// if that caller's context ends init early, waiters with a live context run init themselves.
+// A panic in init is recovered and handled like an error, as a *PanicError.
+func (l *LazyValue[T]) Get(ctx context.Context) (T, error) {
+	for {
+		l.mu.Lock()
+		if l.done {
+			l.mu.Unlock()
+			return l.value, l.err
+		}
+		if running := l.running; running != nil {
+			l.mu.Unlock()
+			select {
+			case <-ctx.Done():
+				var zero T
+				return zero, ctx.Err()
+			case <-running:
+			}
+			l.mu.Lock()
+			if !l.done {
+				err := l.err
+				if isContextErr(err) && ctx.Err() == nil {
+					// The caller running init gave up; try again with our context.
+					l.mu.Unlock()
+					continue
+				}
+				// The init we waited on failed and will be retried; report its error.
+				l.mu.Unlock()
+				var zero T
+				return zero, err
+			}
+			l.mu.Unlock()
+			continue
+		}
+		running := make(chan struct{})
+		l.running = running
+		l.mu.Unlock()
+		return l.run(ctx, running)
+	}
+}
+
+// errInitExited is the error of an init that called runtime.Goexit.
+var errInitExited = errors.New("concurrent: lazy value init exited")
+
+// run calls init and records its result, releasing the callers waiting on
+// running even if init panics or exits.
+func (l *LazyValue[T]) run(ctx context.Context, running chan struct{}) (v T, err error) {
+	defer func() {
+		l.mu.Lock()
+		l.value, l.err = v, err
+		if err == nil || !l.retry && !isContextErr(err) {
+			l.done = true
+		}
+		l.running = nil
+		close(running)
+		l.mu.Unlock()
+	}()
+	err = errInitExited
+	return protectValue(ctx, l.init)
+}
+
+// Done reports whether the value has been initialized for good.
+func (l *LazyValue[T]) Done() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.done
+}
+
+// isContextErr reports whether err comes from a cancelled or expired context.
+func isContextErr(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
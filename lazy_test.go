@@ -0,0 +1,183 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLazyValue(t *testing.T) {
+	t.Run("initializes once", func(t *testing.T) {
+		var calls atomic.Int32
+		lazy := NewLazyValue(func(context.Context) (int, error) {
+			calls.Add(1)
+			time.Sleep(10 * time.Millisecond)
+			return 42, nil
+		})
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if v, err := lazy.Get(context.Background()); err != nil || v != 42 {
+					t.Errorf("Expected 42, got %d, %v", v, err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if calls.Load() != 1 {
+			t.Errorf("Expected init to run once, ran %d times", calls.Load())
+		}
+		if !lazy.Done() {
+			t.Error("Expected lazy value to be done")
+		}
+	})
+
+	t.Run("latches errors by default", func(t *testing.T) {
+		var calls atomic.Int32
+		lazy := NewLazyValue(func(context.Context) (int, error) {
+			calls.Add(1)
+			return 0, errors.New("init failed")
+		})
+
+		for i := 0; i < 3; i++ {
+			if _, err := lazy.Get(context.Background()); err == nil {
+				t.Error("Expected error")
+			}
+		}
+		if calls.Load() != 1 {
+			t.Errorf("Expected init to run once, ran %d times", calls.Load())
+		}
+	})
+
+	t.Run("does not latch a cancelled caller", func(t *testing.T) {
+		lazy := NewLazyValue(func(ctx context.Context) (int, error) {
+			if err := ctx.Err(); err != nil {
+				return 0, err
+			}
+			return 7, nil
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := lazy.Get(ctx); !errors.Is(err, context.Canceled) {
+			t.Fatalf("Expected context.Canceled, got %v", err)
+		}
+		if lazy.Done() {
+			t.Fatal("Expected a cancelled init not to be kept")
+		}
+		if v, err := lazy.Get(context.Background()); err != nil || v != 7 {
+			t.Errorf("Expected 7, got %d, %v", v, err)
+		}
+	})
+
+	t.Run("waiters retry when the caller running init is cancelled", func(t *testing.T) {
+		started := make(chan struct{})
+		var calls atomic.Int32
+		lazy := NewLazyValue(func(ctx context.Context) (int, error) {
+			if calls.Add(1) == 1 {
+				close(started)
+				<-ctx.Done()
+				return 0, ctx.Err()
+			}
+			return 9, nil
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		first := make(chan error, 1)
+		go func() {
+			_, err := lazy.Get(ctx)
+			first <- err
+		}()
+		<-started
+
+		second := make(chan int, 1)
+		go func() {
+			v, err := lazy.Get(context.Background())
+			if err != nil {
+				t.Errorf("Expected waiter to succeed, got %v", err)
+			}
+			second <- v
+		}()
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+
+		if err := <-first; !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+		if v := <-second; v != 9 {
+			t.Errorf("Expected 9, got %d", v)
+		}
+	})
+
+	t.Run("retries after failure", func(t *testing.T) {
+		var calls atomic.Int32
+		lazy := NewLazyValueRetry(func(context.Context) (string, error) {
+			if calls.Add(1) < 3 {
+				return "", errors.New("not yet")
+			}
+			return "ready", nil
+		})
+
+		for i := 0; i < 2; i++ {
+			if _, err := lazy.Get(context.Background()); err == nil {
+				t.Fatal("Expected error")
+			}
+			if lazy.Done() {
+				t.Fatal("Expected lazy value not to be done after failure")
+			}
+		}
+		v, err := lazy.Get(context.Background())
+		if err != nil || v != "ready" {
+			t.Errorf("Expected ready, got %q, %v", v, err)
+		}
+		_, _ = lazy.Get(context.Background())
+		if calls.Load() != 3 {
+			t.Errorf("Expected 3 init calls, got %d", calls.Load())
+		}
+	})
+
+	t.Run("init panic", func(t *testing.T) {
+		var calls atomic.Int32
+		lazy := NewLazyValueRetry(func(context.Context) (int, error) {
+			if calls.Add(1) == 1 {
+				panic("boom")
+			}
+			return 42, nil
+		})
+		var pe *PanicError
+		if _, err := lazy.Get(context.Background()); !errors.As(err, &pe) || pe.Value != "boom" {
+			t.Fatalf("Expected a PanicError, got %v", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if v, err := lazy.Get(ctx); err != nil || v != 42 {
+			t.Errorf("Expected init to run again after the panic, got %d, %v", v, err)
+		}
+	})
+
+	t.Run("waiter context cancellation", func(t *testing.T) {
+		release := make(chan struct{})
+		started := make(chan struct{})
+		lazy := NewLazyValue(func(context.Context) (int, error) {
+			close(started)
+			<-release
+			return 1, nil
+		})
+
+		go func() { _, _ = lazy.Get(context.Background()) }()
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if _, err := lazy.Get(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected deadline exceeded, got %v", err)
+		}
+		close(release)
+	})
+}
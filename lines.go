@@ -0,0 +1,105 @@
+package concurrent
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrLineTooLong is reported by LinesSource for a line over the maximum
+// length when the LongLineError policy is in effect.
+var ErrLineTooLong = errors.New("concurrent: line too long")
+
+// LinesSource turns r into a pipeline source emitting one line at a time,
+// without the line terminator ("\n" or "\r\n"). It is the canonical input for
+// log-processing pipelines.
+//
+// Unlike a bufio.Scanner, whose default 64 KiB token limit silently ends a
+// scan with bufio.ErrTooLong, LinesSource reads lines of any length up to
+// MaxLineLength (1 MiB by default) and applies an explicit policy to longer
+// ones: stop with ErrLineTooLong, truncate, or skip. Memory use is bounded by
+// MaxLineLength regardless of the policy.
+//
+// Errors are reported as for SplitSource: at most one, on the buffered error
+// channel, after the line channel is closed.
+//
+//	lines, errc := concurrent.LinesSource[string](ctx, file, concurrent.WithLongLines(concurrent.LongLineSkip))
+func LinesSource[L string | []byte](ctx context.Context, r io.Reader, opts ...LinesOption) (<-chan L, <-chan error) {
+	options := DefaultLinesOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.MaxLineLength <= 0 {
+		options.MaxLineLength = DefaultLinesOptions().MaxLineLength
+	}
+
+	output := make(chan L)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(errc)
+		err := func() error {
+			defer close(output)
+			br := bufio.NewReader(r)
+			for lineNo := 1; ; lineNo++ {
+				line, tooLong, err := readLine(br, options.MaxLineLength)
+				if err == io.EOF {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+				if tooLong {
+					if options.OnLongLine != nil {
+						options.OnLongLine(lineNo)
+					}
+					switch options.LongLines {
+					case LongLineSkip:
+						continue
+					case LongLineError:
+						return fmt.Errorf("line %d: %w", lineNo, ErrLineTooLong)
+					}
+				}
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case output <- L(line):
+				}
+			}
+		}()
+		if err != nil {
+			errc <- err
+		}
+	}()
+	return output, errc
+}
+
+// readLine reads one line, keeping at most max bytes of it. tooLong reports
+// whether the rest was discarded. It returns io.EOF only when no line remains.
+func readLine(br *bufio.Reader, max int) (line []byte, tooLong bool, err error) {
+	read := false
+	for {
+		fragment, isPrefix, err := br.ReadLine()
+		if err != nil {
+			if err == io.EOF && read {
+				return line, tooLong, nil
+			}
+			return line, tooLong, err
+		}
+		read = true
+		if room := max - len(line); room > 0 {
+			if len(fragment) > room {
+				line = append(line, fragment[:room]...)
+				tooLong = true
+			} else {
+				line = append(line, fragment...)
+			}
+		} else if len(fragment) > 0 {
+			tooLong = true
+		}
+		if !isPrefix {
+			return line, tooLong, nil
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLinesSource(t *testing.T) {
+	ctx := context.Background()
+	long := strings.Repeat("x", 200_000)
+	input := "first\r\n" + long + "\nlast"
+
+	t.Run("default allows long lines", func(t *testing.T) {
+		out, errc := LinesSource[string](ctx, strings.NewReader(input))
+		got := Collect(ctx, out, 0)
+		if len(got) != 3 || got[0] != "first" || len(got[1]) != len(long) || got[2] != "last" {
+			t.Errorf("Expected 3 lines with the long one intact, got %d lines", len(got))
+		}
+		if err := <-errc; err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("error policy", func(t *testing.T) {
+		out, errc := LinesSource[[]byte](ctx, strings.NewReader(input), WithMaxLineLength(1000))
+		got := Collect(ctx, out, 0)
+		if len(got) != 1 || string(got[0]) != "first" {
+			t.Errorf("Expected lines before the long one, got %q", got)
+		}
+		err := <-errc
+		if !errors.Is(err, ErrLineTooLong) || !strings.Contains(err.Error(), "line 2") {
+			t.Errorf("Expected ErrLineTooLong on line 2, got %v", err)
+		}
+	})
+
+	t.Run("truncate policy", func(t *testing.T) {
+		out, _ := LinesSource[string](ctx, strings.NewReader(input), WithMaxLineLength(10), WithLongLines(LongLineTruncate))
+		got := Collect(ctx, out, 0)
+		if len(got) != 3 || got[1] != "xxxxxxxxxx" {
+			t.Errorf("Expected truncated second line, got %q", got)
+		}
+	})
+
+	t.Run("skip policy", func(t *testing.T) {
+		var skipped []int
+		out, errc := LinesSource[string](ctx, strings.NewReader(input),
+			WithMaxLineLength(10), WithLongLines(LongLineSkip), WithOnLongLine(func(n int) { skipped = append(skipped, n) }))
+		got := Collect(ctx, out, 0)
+		if len(got) != 2 || got[1] != "last" {
+			t.Errorf("Expected long line to be skipped, got %q", got)
+		}
+		<-errc
+		if len(skipped) != 1 || skipped[0] != 2 {
+			t.Errorf("Expected line 2 to be reported, got %v", skipped)
+		}
+	})
+
+	t.Run("empty lines", func(t *testing.T) {
+		out, _ := LinesSource[string](ctx, strings.NewReader("a\n\nb\n"))
+		if got := Collect(ctx, out, 0); len(got) != 3 || got[1] != "" {
+			t.Errorf("Expected [a  b], got %q", got)
+		}
+	})
+}
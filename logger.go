@@ -0,0 +1,31 @@
+package concurrent
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Logger receives lifecycle events from pools, pipelines, retries and circuit
+// breakers: worker start and stop, retry attempts, breaker transitions and
+// dropped items. Components log nothing unless a Logger is configured.
+//
+// *slog.Logger satisfies Logger, so it can be passed directly.
+type Logger interface {
+	Log(ctx context.Context, level slog.Level, msg string, args ...any)
+}
+
+// SlogLogger returns l as a Logger, or slog.Default() if l is nil.
+func SlogLogger(l *slog.Logger) Logger {
+	if l == nil {
+		return slog.Default()
+	}
+	return l
+}
+
+// logEvent logs through l if it is set.
+func logEvent(ctx context.Context, l Logger, level slog.Level, msg string, args ...any) {
+	if l == nil {
+		return
+	}
+	l.Log(ctx, level, msg, args...)
+}
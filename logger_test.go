@@ -0,0 +1,92 @@
+package concurrent
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func newTestLogger() (*slog.Logger, *syncBuffer) {
+	buf := &syncBuffer{}
+	return slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})), buf
+}
+
+func TestLogger(t *testing.T) {
+	t.Run("pool", func(t *testing.T) {
+		logger, buf := newTestLogger()
+		pool := NewPool[int, int](1, func(_ context.Context, v int) (int, error) {
+			if v == 0 {
+				return 0, errors.New("zero")
+			}
+			return v, nil
+		}, WithName("p"), WithLogger(SlogLogger(logger)))
+
+		Drain(context.Background(), pool.Run(context.Background(), sliceChan(0, 1)))
+
+		out := buf.String()
+		for _, msg := range []string{"pool worker started", "pool worker stopped", "pool dropped failed job"} {
+			if !strings.Contains(out, msg) {
+				t.Errorf("Expected log to contain %q, got:\n%s", msg, out)
+			}
+		}
+	})
+
+	t.Run("pipeline", func(t *testing.T) {
+		logger, buf := newTestLogger()
+		p := NewPipeline[int](context.Background(), WithPipelineLogger(logger))
+		Drain(context.Background(), p.Run(sliceChan(1)))
+		p.Close()
+
+		out := buf.String()
+		if !strings.Contains(out, "pipeline started") || !strings.Contains(out, "pipeline closed") {
+			t.Errorf("Expected pipeline lifecycle logs, got:\n%s", out)
+		}
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		logger, buf := newTestLogger()
+		config := RetryConfig{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond, Multiplier: 1, Logger: logger}
+		_ = Retry(context.Background(), 0, func(context.Context, int) error { return errors.New("fail") }, config)
+
+		if n := strings.Count(buf.String(), "retrying after error"); n != 2 {
+			t.Errorf("Expected 2 retry logs, got %d", n)
+		}
+	})
+
+	t.Run("circuit breaker", func(t *testing.T) {
+		logger, buf := newTestLogger()
+		cb := NewCircuitBreaker(1, time.Hour, WithBreakerLogger(logger))
+		_ = cb.Execute(context.Background(), func() error { return errors.New("fail") })
+
+		if !strings.Contains(buf.String(), "to=open") {
+			t.Errorf("Expected transition log, got:\n%s", buf.String())
+		}
+	})
+
+	t.Run("silent by default", func(t *testing.T) {
+		pool := NewPool[int, int](1, func(_ context.Context, v int) (int, error) { return v, nil })
+		Drain(context.Background(), pool.Run(context.Background(), sliceChan(1)))
+	})
+}
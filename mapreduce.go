@@ -97,3 +97,49 @@ waitComplete:
 		return out, nil
 	}
 }
+
+// IndexedResult is a result of MapConcurrentStream, tagged with the index of
+// the input element that produced it.
+type IndexedResult[R any] struct {
+	Index int
+	Value R
+	Err   error
+}
+
+// MapConcurrentStream is like MapConcurrent but emits each result as soon as
+// its element completes, in completion order, so a few slow elements don't
+// hold back the rest. Errors are reported per element and do not abort the
+// others; cancel ctx to stop early. The output channel is closed once every
+// started call has returned, or when ctx is cancelled and in-flight calls have
+// returned.
+func MapConcurrentStream[T any, R any](ctx context.Context, in []T, n int, fn func(context.Context, T) (R, error)) <-chan IndexedResult[R] {
+	if n <= 0 {
+		n = 1
+	}
+	output := make(chan IndexedResult[R])
+	go func() {
+		defer close(output)
+		var wg sync.WaitGroup
+		defer wg.Wait()
+
+		sem := make(chan struct{}, n)
+		for i, v := range in {
+			select {
+			case <-ctx.Done():
+				return
+			case sem <- struct{}{}:
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+				r, err := fn(ctx, v)
+				select {
+				case <-ctx.Done():
+				case output <- IndexedResult[R]{Index: i, Value: r, Err: err}:
+				}
+			}()
+		}
+	}()
+	return output
+}
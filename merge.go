@@ -0,0 +1,355 @@
+package concurrent
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Merge merges multiple inputs into one output, forwarding items as they
+// arrive. It is MergeWith using MergeFair.
+// The output channel is closed when all input channels are closed or context is cancelled.
+func Merge[T any](ctx context.Context, inputs ...<-chan T) <-chan T {
+	return MergeWith(ctx, MergeFair, inputs...)
+}
+
+// MergeWith merges multiple inputs into one output, reading them according
+// to strategy. The output channel is closed when all input channels are
+// closed or context is cancelled.
+func MergeWith[T any](ctx context.Context, strategy MergeStrategy, inputs ...<-chan T) <-chan T {
+	switch strategy {
+	case MergeSequential:
+		return mergeSequential(ctx, inputs)
+	case MergePriority:
+		return mergePriority(ctx, inputs)
+	default:
+		return mergeFair(ctx, inputs)
+	}
+}
+
+func mergeFair[T any](ctx context.Context, inputs []<-chan T) <-chan T {
+	output := make(chan T)
+	var wg sync.WaitGroup
+
+	for _, input := range inputs {
+		wg.Add(1)
+		go func(ch <-chan T) {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case item, ok := <-ch:
+					if !ok {
+						return
+					}
+					select {
+					case <-ctx.Done():
+						return
+					case output <- item:
+					}
+				}
+			}
+		}(input)
+	}
+
+	go func() {
+		wg.Wait()
+		close(output)
+	}()
+
+	return output
+}
+
+func mergeSequential[T any](ctx context.Context, inputs []<-chan T) <-chan T {
+	output := make(chan T)
+	go func() {
+		defer close(output)
+		for _, input := range inputs {
+			if !forwardAll(ctx, input, output) {
+				return
+			}
+		}
+	}()
+	return output
+}
+
+// forwardAll sends every item from input to output, reporting false if ctx
+// was cancelled first.
+func forwardAll[T any](ctx context.Context, input <-chan T, output chan<- T) bool {
+	for {
+		item, ok := Recv(ctx, input)
+		if !ok {
+			return ctx.Err() == nil
+		}
+		if !Send(ctx, output, item) {
+			return false
+		}
+	}
+}
+
+func mergePriority[T any](ctx context.Context, inputs []<-chan T) <-chan T {
+	inputs = slices.Clone(inputs)
+	output := make(chan T)
+	go func() {
+		defer close(output)
+		// Case layout: done, then one per input; closed inputs get a nil channel.
+		cases := make([]reflect.SelectCase, 1+len(inputs))
+		cases[0] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())}
+		for i, input := range inputs {
+			cases[1+i] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(input)}
+		}
+		open := len(inputs)
+		closeInput := func(i int) {
+			inputs[i] = nil
+			cases[1+i].Chan = reflect.Value{}
+			open--
+		}
+		for open > 0 {
+			item, ready := pollInputs(inputs, closeInput)
+			if !ready {
+				if open == 0 {
+					return
+				}
+				chosen, recv, ok := reflect.Select(cases)
+				if chosen == 0 {
+					return
+				}
+				if !ok {
+					closeInput(chosen - 1)
+					continue
+				}
+				item, _ = recv.Interface().(T)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case output <- item:
+			}
+		}
+	}()
+	return output
+}
+
+// pollInputs returns the first item ready on inputs, in order, calling
+// closeInput for any closed input it passes.
+func pollInputs[T any](inputs []<-chan T, closeInput func(int)) (T, bool) {
+	for i, input := range inputs {
+		if input == nil {
+			continue
+		}
+		select {
+		case item, ok := <-input:
+			if ok {
+				return item, true
+			}
+			closeInput(i)
+		default:
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// MergeAging merges inputs by priority, earliest input first, boosting the
+// priority of items as they wait according to policy. It holds up to one
+// item per input while choosing; waiting times are measured from when an
+// item is taken from its input. The output channel is closed when all input
+// channels are closed or context is cancelled.
+func MergeAging[T any](ctx context.Context, policy AgingPolicy, inputs ...<-chan T) <-chan T {
+	clock := clockOrReal(policy.Clock)
+	output := make(chan T)
+	go func() {
+		defer close(output)
+		heads := newHeldInputs(ctx, inputs)
+		for heads.fill(clock.Now) {
+			// Pick the overdue item that has waited longest, or else the
+			// item with the best aged priority.
+			now := clock.Now()
+			best, overdue := -1, false
+			var bestScore float64
+			for i, h := range heads.held {
+				if !h.ok {
+					continue
+				}
+				age := now.Sub(h.since)
+				late := policy.MaxWait > 0 && age >= policy.MaxWait
+				score := float64(i)
+				if late {
+					score = -float64(age)
+				} else if policy.Step > 0 {
+					score -= float64(age / policy.Step)
+				}
+				if best < 0 || (late && !overdue) || (late == overdue && score < bestScore) {
+					best, bestScore, overdue = i, score, late
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case output <- heads.take(best):
+			}
+		}
+	}()
+	return output
+}
+
+// WeightedSource is an input to MergeWeighted, such as the jobs of one
+// tenant.
+type WeightedSource[T any] struct {
+	Name   string
+	Input  <-chan T
+	Weight int // share of the output relative to other sources; at least 1
+	// Metrics, if set, counts every item forwarded from the source as a
+	// success, for per-source throughput.
+	Metrics *Metrics
+}
+
+// MergeWeighted merges sources with weighted fair queuing: while several
+// sources have items ready, each gets a share of the output proportional to
+// its weight, so a noisy source cannot monopolize a slow consumer such as a
+// Pool. A source that was idle does not build up credit to burst with later.
+// The output channel is closed when all sources are closed or context is
+// cancelled.
+func MergeWeighted[T any](ctx context.Context, sources ...WeightedSource[T]) <-chan T {
+	inputs := make([]<-chan T, len(sources))
+	strides := make([]float64, len(sources))
+	for i, src := range sources {
+		inputs[i] = src.Input
+		strides[i] = 1 / float64(max(src.Weight, 1))
+	}
+	output := make(chan T)
+	go func() {
+		defer close(output)
+		// Stride scheduling: the ready source with the lowest pass goes
+		// next, then advances its pass by its stride.
+		passes := make([]float64, len(sources))
+		var global float64
+		heads := newHeldInputs(ctx, inputs)
+		heads.arrived = func(i int) {
+			passes[i] = max(passes[i], global)
+		}
+		for heads.fill(time.Now) {
+			best := -1
+			for i, h := range heads.held {
+				if h.ok && (best < 0 || passes[i] < passes[best]) {
+					best = i
+				}
+			}
+			global = passes[best]
+			passes[best] += strides[best]
+			select {
+			case <-ctx.Done():
+				return
+			case output <- heads.take(best):
+			}
+			if m := sources[best].Metrics; m != nil {
+				m.RecordSuccess()
+			}
+		}
+	}()
+	return output
+}
+
+// heldInput is the item a merge has taken from an input but not yet
+// forwarded.
+type heldInput[T any] struct {
+	item  T
+	since time.Time
+	ok    bool
+}
+
+// heldInputs holds up to one item per input, so a merge can choose among
+// the inputs that have items ready.
+type heldInputs[T any] struct {
+	inputs []<-chan T
+	held   []heldInput[T]
+	cases  []reflect.SelectCase
+	open   int
+	// arrived, if set, is called when input i gets a held item after
+	// having none.
+	arrived func(i int)
+}
+
+func newHeldInputs[T any](ctx context.Context, inputs []<-chan T) *heldInputs[T] {
+	h := &heldInputs[T]{
+		inputs: slices.Clone(inputs),
+		held:   make([]heldInput[T], len(inputs)),
+		cases:  make([]reflect.SelectCase, 1+len(inputs)),
+		open:   len(inputs),
+	}
+	h.cases[0] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())}
+	return h
+}
+
+// fill takes an item from every input that has one ready and no held item,
+// blocking until there is at least one held item. It reports false once
+// every input is closed and drained, or ctx is cancelled.
+func (h *heldInputs[T]) fill(now func() time.Time) bool {
+	for {
+		count := 0
+		for i, input := range h.inputs {
+			if h.held[i].ok {
+				count++
+				continue
+			}
+			if input == nil {
+				continue
+			}
+			select {
+			case item, ok := <-input:
+				if !ok {
+					h.closeInput(i)
+					continue
+				}
+				h.hold(i, item, now())
+				count++
+			default:
+			}
+		}
+		if count > 0 {
+			return true
+		}
+		if h.open == 0 {
+			return false
+		}
+		for i, input := range h.inputs {
+			h.cases[1+i] = reflect.SelectCase{Dir: reflect.SelectRecv}
+			if input != nil {
+				h.cases[1+i].Chan = reflect.ValueOf(input)
+			}
+		}
+		chosen, recv, ok := reflect.Select(h.cases)
+		if chosen == 0 {
+			return false
+		}
+		if !ok {
+			h.closeInput(chosen - 1)
+			continue
+		}
+		item, _ := recv.Interface().(T)
+		h.hold(chosen-1, item, now())
+		return true
+	}
+}
+
+func (h *heldInputs[T]) hold(i int, item T, now time.Time) {
+	h.held[i] = heldInput[T]{item: item, since: now, ok: true}
+	if h.arrived != nil {
+		h.arrived(i)
+	}
+}
+
+// take removes and returns the item held for input i.
+func (h *heldInputs[T]) take(i int) T {
+	item := h.held[i].item
+	h.held[i] = heldInput[T]{}
+	return item
+}
+
+func (h *heldInputs[T]) closeInput(i int) {
+	h.inputs[i] = nil
+	h.open--
+}
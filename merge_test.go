@@ -0,0 +1,190 @@
+package concurrent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/logimos/concurrent/concurrenttest"
+)
+
+func TestMergeWith(t *testing.T) {
+	t.Run("sequential preserves input order", func(t *testing.T) {
+		ctx := context.Background()
+		a := make(chan int, 3)
+		b := make(chan int, 3)
+		for i := 1; i <= 3; i++ {
+			a <- i
+			b <- i + 10
+		}
+		close(a)
+		close(b)
+
+		results := Collect(ctx, MergeWith(ctx, MergeSequential, b, a), 0)
+		expected := []int{11, 12, 13, 1, 2, 3}
+		if len(results) != len(expected) {
+			t.Fatalf("Expected %v, got %v", expected, results)
+		}
+		for i, v := range results {
+			if v != expected[i] {
+				t.Errorf("Expected %d at index %d, got %d", expected[i], i, v)
+			}
+		}
+	})
+
+	t.Run("priority prefers earlier inputs", func(t *testing.T) {
+		ctx := context.Background()
+		high := make(chan int, 3)
+		low := make(chan int, 3)
+		for i := 1; i <= 3; i++ {
+			low <- i + 10
+			high <- i
+		}
+		close(high)
+		close(low)
+
+		results := Collect(ctx, MergeWith(ctx, MergePriority, high, low), 0)
+		expected := []int{1, 2, 3, 11, 12, 13}
+		if len(results) != len(expected) {
+			t.Fatalf("Expected %v, got %v", expected, results)
+		}
+		for i, v := range results {
+			if v != expected[i] {
+				t.Errorf("Expected %d at index %d, got %d", expected[i], i, v)
+			}
+		}
+	})
+
+	t.Run("priority waits for any input", func(t *testing.T) {
+		ctx := context.Background()
+		high := make(chan int)
+		low := make(chan int)
+		output := MergeWith(ctx, MergePriority, high, low)
+
+		go func() {
+			low <- 1
+			high <- 2
+			close(low)
+			close(high)
+		}()
+		results := Collect(ctx, output, 0)
+		if len(results) != 2 {
+			t.Errorf("Expected 2 results, got %v", results)
+		}
+	})
+
+	t.Run("cancellation", func(t *testing.T) {
+		for _, strategy := range []MergeStrategy{MergeFair, MergeSequential, MergePriority} {
+			ctx, cancel := context.WithCancel(context.Background())
+			output := MergeWith(ctx, strategy, make(chan int), make(chan int))
+			cancel()
+			concurrenttest.AssertClosedWithin(t, output, time.Second)
+		}
+	})
+}
+
+func TestMergeAging(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fill := func(v, n int) chan int {
+		ch := make(chan int, n)
+		for range n {
+			ch <- v
+		}
+		return ch
+	}
+
+	t.Run("max wait", func(t *testing.T) {
+		clock := NewFakeClock(time.Now())
+		high, low := fill(1, 100), fill(2, 1)
+		output := MergeAging(ctx, AgingPolicy{MaxWait: time.Second, Clock: clock}, high, low)
+		if v := <-output; v != 1 {
+			t.Fatalf("Expected high priority first, got %d", v)
+		}
+		clock.Advance(time.Second)
+		// One high item may already be chosen and waiting to be sent.
+		if a, b := <-output, <-output; a != 2 && b != 2 {
+			t.Errorf("Expected the overdue low priority item, got %d and %d", a, b)
+		}
+	})
+
+	t.Run("gradual aging", func(t *testing.T) {
+		clock := NewFakeClock(time.Now())
+		first, second, third := fill(1, 100), fill(2, 100), fill(3, 1)
+		output := MergeAging(ctx, AgingPolicy{Step: time.Second, Clock: clock}, first, second, third)
+		<-output
+		// After three steps the third input's item outranks new items from
+		// the first, though not the second input's item held as long.
+		clock.Advance(3 * time.Second)
+		found := false
+		for range 3 {
+			if <-output == 3 {
+				found = true
+			}
+		}
+		if !found {
+			t.Error("Expected the aged item within three reads")
+		}
+	})
+
+	t.Run("closes", func(t *testing.T) {
+		a, b := fill(1, 2), fill(2, 2)
+		close(a)
+		close(b)
+		got := Collect(ctx, MergeAging(ctx, AgingPolicy{}, a, b), 0)
+		if len(got) != 4 || got[0] != 1 || got[3] != 2 {
+			t.Errorf("Expected [1 1 2 2], got %v", got)
+		}
+	})
+}
+
+func TestMergeWeighted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	backlog := func(v, n int) chan int {
+		ch := make(chan int, n)
+		for range n {
+			ch <- v
+		}
+		return ch
+	}
+
+	t.Run("shares by weight", func(t *testing.T) {
+		noisy, quiet := NewMetrics(), NewMetrics()
+		output := MergeWeighted(ctx,
+			WeightedSource[int]{Name: "noisy", Input: backlog(1, 1000), Weight: 3, Metrics: noisy},
+			WeightedSource[int]{Name: "quiet", Input: backlog(2, 1000), Weight: 1, Metrics: quiet},
+		)
+		counts := map[int]int{}
+		for range 400 {
+			counts[<-output]++
+		}
+		if counts[1] < 295 || counts[1] > 305 {
+			t.Errorf("Expected about 300 items from the heavier source, got %v", counts)
+		}
+		if n := quiet.Snapshot().ProcessedCount; n < 95 || n > 105 {
+			t.Errorf("Expected about 100 items counted for the lighter source, got %d", n)
+		}
+	})
+
+	t.Run("idle sources build no credit", func(t *testing.T) {
+		late := make(chan int, 100)
+		output := MergeWeighted(ctx,
+			WeightedSource[int]{Input: backlog(1, 1000)},
+			WeightedSource[int]{Input: late},
+		)
+		for range 50 {
+			<-output
+		}
+		for range 100 {
+			late <- 2
+		}
+		counts := map[int]int{}
+		for range 20 {
+			counts[<-output]++
+		}
+		if counts[2] > 11 {
+			t.Errorf("Expected the late source to get an even share, got %v", counts)
+		}
+	})
+}
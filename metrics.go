@@ -0,0 +1,319 @@
+package concurrent
+
+import (
+	"context"
+	"math"
+	"math/bits"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Metrics holds performance metrics for concurrent operations.
+// All methods are safe for concurrent use. The exported fields are kept for
+// compatibility; use Snapshot for a consistent read while recording is ongoing.
+type Metrics struct {
+	ProcessedCount int64
+	ErrorCount     int64
+	Duration       time.Duration
+	StartTime      time.Time
+	EndTime        time.Time
+
+	mu      sync.Mutex
+	latency histogram
+}
+
+// MetricsSnapshot is a point-in-time copy of a Metrics.
+type MetricsSnapshot struct {
+	ProcessedCount int64
+	ErrorCount     int64
+	Duration       time.Duration
+	StartTime      time.Time
+	EndTime        time.Time
+	LatencyCount   int64
+	LatencyMin     time.Duration
+	LatencyMax     time.Duration
+	LatencyMean    time.Duration
+	P50            time.Duration
+	P95            time.Duration
+	P99            time.Duration
+}
+
+// NewMetrics creates a new metrics instance.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		StartTime: time.Now(),
+	}
+}
+
+// RecordSuccess records a successful operation.
+func (m *Metrics) RecordSuccess() {
+	atomic.AddInt64(&m.ProcessedCount, 1)
+}
+
+// RecordError records a failed operation.
+func (m *Metrics) RecordError() {
+	atomic.AddInt64(&m.ErrorCount, 1)
+}
+
+// RecordLatency records the duration of a single operation.
+func (m *Metrics) RecordLatency(d time.Duration) {
+	m.latency.record(d)
+}
+
+// Finish marks the end of the operation and calculates duration.
+func (m *Metrics) Finish() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.EndTime = time.Now()
+	m.Duration = m.EndTime.Sub(m.StartTime)
+}
+
+// SuccessRate returns the success rate as a percentage.
+func (m *Metrics) SuccessRate() float64 {
+	processed := atomic.LoadInt64(&m.ProcessedCount)
+	total := processed + atomic.LoadInt64(&m.ErrorCount)
+	if total == 0 {
+		return 0
+	}
+	return float64(processed) / float64(total) * 100
+}
+
+// Throughput returns the operations per second.
+func (m *Metrics) Throughput() float64 {
+	m.mu.Lock()
+	duration := m.Duration
+	m.mu.Unlock()
+	if duration == 0 {
+		return 0
+	}
+	return float64(atomic.LoadInt64(&m.ProcessedCount)) / duration.Seconds()
+}
+
+// ErrorRate returns the error rate as a percentage.
+func (m *Metrics) ErrorRate() float64 {
+	return 100 - m.SuccessRate()
+}
+
+// Percentile returns the latency at quantile q (0 < q <= 1) of recorded latencies.
+// Values are accurate to within about 3%.
+func (m *Metrics) Percentile(q float64) time.Duration {
+	return m.latency.quantile(q)
+}
+
+// P50 returns the median recorded latency.
+func (m *Metrics) P50() time.Duration {
+	return m.Percentile(0.50)
+}
+
+// P95 returns the 95th percentile recorded latency.
+func (m *Metrics) P95() time.Duration {
+	return m.Percentile(0.95)
+}
+
+// P99 returns the 99th percentile recorded latency.
+func (m *Metrics) P99() time.Duration {
+	return m.Percentile(0.99)
+}
+
+// Snapshot returns a copy of the current metrics.
+func (m *Metrics) Snapshot() MetricsSnapshot {
+	m.mu.Lock()
+	s := MetricsSnapshot{
+		Duration:  m.Duration,
+		StartTime: m.StartTime,
+		EndTime:   m.EndTime,
+	}
+	m.mu.Unlock()
+	s.ProcessedCount = atomic.LoadInt64(&m.ProcessedCount)
+	s.ErrorCount = atomic.LoadInt64(&m.ErrorCount)
+	s.LatencyCount = m.latency.count.Load()
+	if s.LatencyCount > 0 {
+		s.LatencyMin = m.latency.minValue()
+		s.LatencyMax = time.Duration(m.latency.max.Load())
+		s.LatencyMean = time.Duration(m.latency.sum.Load() / s.LatencyCount)
+	}
+	s.P50 = m.P50()
+	s.P95 = m.P95()
+	s.P99 = m.P99()
+	return s
+}
+
+// Stopwatch measures one operation and records its latency into a Metrics
+// when stopped. Lap marks intermediate points, such as the phases of a
+// request, without recording anything.
+type Stopwatch struct {
+	metrics *Metrics
+	clock   Clock
+
+	mu      sync.Mutex
+	start   time.Time
+	lap     time.Time
+	laps    []time.Duration
+	elapsed time.Duration
+	stopped bool
+}
+
+// StartStopwatch starts a stopwatch that records into m when stopped. A nil
+// m only measures; a nil clock means RealClock.
+func StartStopwatch(m *Metrics, clock Clock) *Stopwatch {
+	clock = clockOrReal(clock)
+	now := clock.Now()
+	return &Stopwatch{metrics: m, clock: clock, start: now, lap: now}
+}
+
+// Lap returns the time since the previous lap, or since the start for the
+// first lap, and begins a new one.
+func (s *Stopwatch) Lap() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.clock.Now()
+	d := now.Sub(s.lap)
+	s.lap = now
+	s.laps = append(s.laps, d)
+	return d
+}
+
+// Laps returns the durations of the laps taken so far.
+func (s *Stopwatch) Laps() []time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]time.Duration(nil), s.laps...)
+}
+
+// Elapsed returns the time since the start, or the total once stopped.
+func (s *Stopwatch) Elapsed() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return s.elapsed
+	}
+	return s.clock.Now().Sub(s.start)
+}
+
+// Stop stops the stopwatch, records the total as a latency in its Metrics
+// and returns it. Only the first call records; later calls return the same
+// total.
+func (s *Stopwatch) Stop() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.stopped {
+		s.stopped = true
+		s.elapsed = s.clock.Now().Sub(s.start)
+		if s.metrics != nil {
+			s.metrics.RecordLatency(s.elapsed)
+		}
+	}
+	return s.elapsed
+}
+
+// Timed wraps fn so every call records its latency into m, along with a
+// success or an error.
+func Timed(m *Metrics, fn func(context.Context) error) func(context.Context) error {
+	return func(ctx context.Context) error {
+		sw := StartStopwatch(m, nil)
+		err := fn(ctx)
+		sw.Stop()
+		if err != nil {
+			m.RecordError()
+		} else {
+			m.RecordSuccess()
+		}
+		return err
+	}
+}
+
+// Histogram layout: values below histSubBuckets get an exact bucket each;
+// every power of two above that is split into histSubBuckets linear buckets,
+// giving a relative error of at most 1/histSubBuckets.
+const (
+	histSubBits    = 5
+	histSubBuckets = 1 << histSubBits
+	histBuckets    = (64 - histSubBits + 1) * histSubBuckets
+)
+
+// histogram is a lock-free, HDR-style log-linear histogram of durations.
+type histogram struct {
+	buckets [histBuckets]atomic.Int64
+	count   atomic.Int64
+	sum     atomic.Int64
+	min     atomic.Int64
+	max     atomic.Int64
+}
+
+func (h *histogram) minValue() time.Duration {
+	if v := h.min.Load(); v > 0 {
+		return time.Duration(v - 1)
+	}
+	return 0
+}
+
+func histIndex(v uint64) int {
+	if v < histSubBuckets {
+		return int(v)
+	}
+	shift := bits.Len64(v) - histSubBits - 1
+	return (shift+1)*histSubBuckets + int(v>>shift) - histSubBuckets
+}
+
+// histValue returns the midpoint of the bucket at index i.
+func histValue(i int) uint64 {
+	if i < histSubBuckets {
+		return uint64(i)
+	}
+	shift := i/histSubBuckets - 1
+	lower := uint64(i%histSubBuckets+histSubBuckets) << shift
+	return lower + (uint64(1)<<shift)/2
+}
+
+func (h *histogram) record(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	v := int64(d)
+	h.buckets[histIndex(uint64(v))].Add(1)
+	h.count.Add(1)
+	h.sum.Add(v)
+	// min is stored off by one so that zero means "unset".
+	for {
+		cur := h.min.Load()
+		if (cur != 0 && cur-1 <= v) || h.min.CompareAndSwap(cur, v+1) {
+			break
+		}
+	}
+	for {
+		cur := h.max.Load()
+		if cur >= v || h.max.CompareAndSwap(cur, v) {
+			break
+		}
+	}
+}
+
+func (h *histogram) quantile(q float64) time.Duration {
+	total := h.count.Load()
+	if total == 0 {
+		return 0
+	}
+	if q <= 0 {
+		q = 0
+	}
+	if q > 1 {
+		q = 1
+	}
+	rank := int64(math.Ceil(q * float64(total)))
+	if rank < 1 {
+		rank = 1
+	}
+	var seen int64
+	for i := range h.buckets {
+		seen += h.buckets[i].Load()
+		if seen >= rank {
+			v := time.Duration(histValue(i))
+			if max := time.Duration(h.max.Load()); v > max {
+				v = max
+			}
+			return v
+		}
+	}
+	return time.Duration(h.max.Load())
+}
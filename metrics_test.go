@@ -0,0 +1,163 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	t.Run("concurrent recording", func(t *testing.T) {
+		m := NewMetrics()
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					if j%10 == 0 {
+						m.RecordError()
+					} else {
+						m.RecordSuccess()
+					}
+					m.RecordLatency(time.Duration(j+1) * time.Millisecond)
+				}
+			}(i)
+		}
+		wg.Wait()
+		m.Finish()
+
+		s := m.Snapshot()
+		if s.ProcessedCount != 4500 || s.ErrorCount != 500 {
+			t.Errorf("Expected 4500/500, got %d/%d", s.ProcessedCount, s.ErrorCount)
+		}
+		if s.LatencyCount != 5000 {
+			t.Errorf("Expected 5000 latency samples, got %d", s.LatencyCount)
+		}
+		if s.LatencyMin != time.Millisecond || s.LatencyMax != 100*time.Millisecond {
+			t.Errorf("Expected min 1ms and max 100ms, got %v and %v", s.LatencyMin, s.LatencyMax)
+		}
+		if m.SuccessRate() != 90 {
+			t.Errorf("Expected 90%% success rate, got %v", m.SuccessRate())
+		}
+	})
+
+	t.Run("percentiles", func(t *testing.T) {
+		m := NewMetrics()
+		for i := 1; i <= 1000; i++ {
+			m.RecordLatency(time.Duration(i) * time.Microsecond)
+		}
+
+		checks := []struct {
+			got, want time.Duration
+		}{
+			{m.P50(), 500 * time.Microsecond},
+			{m.P95(), 950 * time.Microsecond},
+			{m.P99(), 990 * time.Microsecond},
+		}
+		for _, c := range checks {
+			diff := float64(c.got-c.want) / float64(c.want)
+			if diff < -0.04 || diff > 0.04 {
+				t.Errorf("Expected ~%v, got %v", c.want, c.got)
+			}
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		m := NewMetrics()
+		if m.P99() != 0 || m.Throughput() != 0 || m.SuccessRate() != 0 {
+			t.Error("Expected zero values for empty metrics")
+		}
+	})
+
+	t.Run("pool option", func(t *testing.T) {
+		m := NewMetrics()
+		pool := NewPool[int, int](2, func(_ context.Context, v int) (int, error) {
+			if v == 0 {
+				return 0, errors.New("zero")
+			}
+			return v, nil
+		}, WithMetrics(m))
+
+		Drain(context.Background(), pool.Run(context.Background(), sliceChan(0, 1, 2, 3)))
+
+		s := m.Snapshot()
+		if s.ProcessedCount != 3 || s.ErrorCount != 1 || s.LatencyCount != 4 {
+			t.Errorf("Unexpected snapshot: %+v", s)
+		}
+		if s.EndTime.IsZero() {
+			t.Error("Expected metrics to be finished")
+		}
+	})
+
+	t.Run("pipeline option", func(t *testing.T) {
+		m := NewMetrics()
+		ctx := context.Background()
+		p := NewPipeline[int](ctx, WithPipelineMetrics(m)).AddStage(Filter(func(v int) bool { return v > 1 }))
+
+		Drain(ctx, p.Run(sliceChan(1, 2, 3)))
+
+		if s := m.Snapshot(); s.ProcessedCount != 2 {
+			t.Errorf("Expected 2 processed items, got %d", s.ProcessedCount)
+		}
+	})
+}
+
+func TestStopwatch(t *testing.T) {
+	t.Run("laps and stop", func(t *testing.T) {
+		clock := NewFakeClock(time.Now())
+		m := NewMetrics()
+		sw := StartStopwatch(m, clock)
+
+		clock.Advance(10 * time.Millisecond)
+		if d := sw.Lap(); d != 10*time.Millisecond {
+			t.Errorf("Expected a 10ms lap, got %v", d)
+		}
+		clock.Advance(30 * time.Millisecond)
+		sw.Lap()
+		if laps := sw.Laps(); len(laps) != 2 || laps[1] != 30*time.Millisecond {
+			t.Errorf("Expected laps [10ms 30ms], got %v", laps)
+		}
+
+		clock.Advance(10 * time.Millisecond)
+		if d := sw.Stop(); d != 50*time.Millisecond {
+			t.Errorf("Expected 50ms total, got %v", d)
+		}
+		clock.Advance(time.Second)
+		if d := sw.Stop(); d != 50*time.Millisecond {
+			t.Errorf("Expected Stop to keep the total, got %v", d)
+		}
+		if d := sw.Elapsed(); d != 50*time.Millisecond {
+			t.Errorf("Expected Elapsed to keep the total, got %v", d)
+		}
+
+		s := m.Snapshot()
+		if s.LatencyCount != 1 {
+			t.Errorf("Expected 1 latency recorded, got %d", s.LatencyCount)
+		}
+		if s.LatencyMax < 48*time.Millisecond || s.LatencyMax > 52*time.Millisecond {
+			t.Errorf("Expected about 50ms recorded, got %v", s.LatencyMax)
+		}
+	})
+
+	t.Run("timed", func(t *testing.T) {
+		m := NewMetrics()
+		boom := errors.New("boom")
+		ok := Timed(m, func(context.Context) error { return nil })
+		fail := Timed(m, func(context.Context) error { return boom })
+
+		ok(context.Background())
+		ok(context.Background())
+		if err := fail(context.Background()); err != boom {
+			t.Errorf("Expected boom, got %v", err)
+		}
+
+		s := m.Snapshot()
+		if s.LatencyCount != 3 || s.ProcessedCount != 2 || s.ErrorCount != 1 {
+			t.Errorf("Expected 3 latencies, 2 successes and 1 error, got %d, %d, %d",
+				s.LatencyCount, s.ProcessedCount, s.ErrorCount)
+		}
+	})
+}
@@ -0,0 +1,88 @@
+package concurrent
+
+import (
+	"context"
+	"time"
+)
+
+// MonitorStats is a throughput report from Monitor.
+type MonitorStats struct {
+	Items       int64         // items seen since the stage started
+	Bytes       int64         // bytes seen since the stage started; needs WithMonitorSize
+	ItemsPerSec float64       // over the reporting period
+	BytesPerSec float64       // over the reporting period; needs WithMonitorSize
+	Lag         time.Duration // age of the latest item; needs WithMonitorTimestamp
+	Period      time.Duration // length of the reporting period
+}
+
+// Monitor creates a pass-through stage that calls report every interval with
+// the throughput seen since the previous report, and once more when its
+// input closes. report runs on the stage's goroutine, so it should return
+// quickly; send the stats on to a channel if they need slow handling.
+func Monitor[T any](interval time.Duration, report func(MonitorStats), opts ...MonitorOption) Stage[T, T] {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	var options MonitorOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	clock := clockOrReal(options.Clock)
+
+	return func(ctx context.Context, input <-chan T) <-chan T {
+		output := make(chan T)
+		go func() {
+			defer close(output)
+			ticker := clock.NewTicker(interval)
+			defer ticker.Stop()
+
+			var stats MonitorStats
+			var items, bytes int64 // since the last report
+			var latest time.Time
+			last := clock.Now()
+			flush := func(now time.Time) {
+				stats.Period = now.Sub(last)
+				stats.ItemsPerSec, stats.BytesPerSec = 0, 0
+				if secs := stats.Period.Seconds(); secs > 0 {
+					stats.ItemsPerSec = float64(items) / secs
+					stats.BytesPerSec = float64(bytes) / secs
+				}
+				if !latest.IsZero() {
+					stats.Lag = now.Sub(latest)
+				}
+				report(stats)
+				items, bytes, last = 0, 0, now
+			}
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case now := <-ticker.C():
+					flush(now)
+				case item, ok := <-input:
+					if !ok {
+						flush(clock.Now())
+						return
+					}
+					stats.Items++
+					items++
+					if options.Size != nil {
+						n := int64(options.Size(item))
+						stats.Bytes += n
+						bytes += n
+					}
+					if options.Timestamp != nil {
+						latest = options.Timestamp(item)
+					}
+					select {
+					case <-ctx.Done():
+						return
+					case output <- item:
+					}
+				}
+			}
+		}()
+		return output
+	}
+}
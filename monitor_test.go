@@ -0,0 +1,52 @@
+package concurrent
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMonitor(t *testing.T) {
+	type event struct {
+		payload string
+		at      time.Time
+	}
+
+	clock := NewFakeClock(time.Now())
+	start := clock.Now()
+	reports := make(chan MonitorStats, 2)
+	stage := Monitor[event](time.Second, func(s MonitorStats) { reports <- s },
+		WithMonitorClock(clock),
+		WithMonitorSize(func(item any) int { return len(item.(event).payload) }),
+		WithMonitorTimestamp(func(item any) time.Time { return item.(event).at }),
+	)
+
+	ctx := context.Background()
+	input := make(chan event)
+	output := stage(ctx, input)
+	clock.BlockUntil(1)
+
+	for _, p := range []string{"ab", "cd", "ef", "gh"} {
+		input <- event{payload: p, at: start.Add(-500 * time.Millisecond)}
+		<-output
+	}
+	clock.Advance(time.Second)
+
+	s := <-reports
+	if s.Items != 4 || s.Bytes != 8 {
+		t.Errorf("Expected 4 items and 8 bytes, got %d and %d", s.Items, s.Bytes)
+	}
+	if s.ItemsPerSec != 4 || s.BytesPerSec != 8 {
+		t.Errorf("Expected 4 items/s and 8 bytes/s, got %v and %v", s.ItemsPerSec, s.BytesPerSec)
+	}
+	if s.Lag != 1500*time.Millisecond {
+		t.Errorf("Expected 1.5s lag, got %v", s.Lag)
+	}
+
+	close(input)
+	Drain(ctx, output)
+	s = <-reports
+	if s.Items != 4 || s.ItemsPerSec != 0 {
+		t.Errorf("Expected a final report with no new items, got %+v", s)
+	}
+}
@@ -0,0 +1,106 @@
+package concurrent
+
+import "time"
+
+// EventKind identifies what an instrumentation Event reports.
+type EventKind int
+
+const (
+	// EventStarted reports that an item entered a component.
+	EventStarted EventKind = iota
+	// EventCompleted reports that an item finished processing; Latency and Err describe the outcome.
+	EventCompleted
+	// EventEmitted reports that an item left a pipeline stage.
+	EventEmitted
+	// EventThrottled reports that a rate limiter delayed or denied an operation.
+	EventThrottled
+	// EventRejected reports that a circuit breaker rejected a call.
+	EventRejected
+	// EventStateChanged reports a circuit breaker transition; State holds the new state.
+	EventStateChanged
+	// EventQueueDepth reports the number of items waiting in Depth.
+	EventQueueDepth
+	// EventRetried reports that Retry will try again after the error in Err;
+	// Attempt is the number of the failed attempt and Latency the backoff delay.
+	EventRetried
+	// EventDropped reports that an item was discarded; Err holds the reason.
+	EventDropped
+	// EventBatched reports that a batch of Depth items was flushed.
+	EventBatched
+)
+
+// String returns the name of the event kind.
+func (k EventKind) String() string {
+	switch k {
+	case EventStarted:
+		return "started"
+	case EventCompleted:
+		return "completed"
+	case EventEmitted:
+		return "emitted"
+	case EventThrottled:
+		return "throttled"
+	case EventRejected:
+		return "rejected"
+	case EventStateChanged:
+		return "state_changed"
+	case EventQueueDepth:
+		return "queue_depth"
+	case EventRetried:
+		return "retried"
+	case EventDropped:
+		return "dropped"
+	case EventBatched:
+		return "batched"
+	default:
+		return "unknown"
+	}
+}
+
+// Event is a single instrumentation event emitted by a named component.
+type Event struct {
+	Component string
+	Kind      EventKind
+	Time      time.Time
+	Latency   time.Duration
+	Err       error
+	Depth     int
+	State     CircuitState
+	Attempt   int
+}
+
+// Observer receives events from instrumented pools, pipelines, rate limiters
+// and circuit breakers. Implementations must be safe for concurrent use and
+// should return quickly, since events are delivered synchronously.
+type Observer interface {
+	Observe(Event)
+}
+
+// ObserverFunc adapts an ordinary function to the Observer interface.
+type ObserverFunc func(Event)
+
+// Observe calls f(e).
+func (f ObserverFunc) Observe(e Event) {
+	f(e)
+}
+
+// observer binds an Observer to a component name. The zero value discards events.
+type observer struct {
+	name string
+	o    Observer
+}
+
+func (ob observer) enabled() bool {
+	return ob.o != nil
+}
+
+func (ob observer) emit(e Event) {
+	if ob.o == nil {
+		return
+	}
+	e.Component = ob.name
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	ob.o.Observe(e)
+}
@@ -0,0 +1,109 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type eventLog struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (l *eventLog) Observe(e Event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, e)
+}
+
+func (l *eventLog) count(component string, kind EventKind) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n := 0
+	for _, e := range l.events {
+		if e.Component == component && e.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+func TestObserver(t *testing.T) {
+	t.Run("pool", func(t *testing.T) {
+		log := &eventLog{}
+		pool := NewPool[int, int](2, func(_ context.Context, v int) (int, error) {
+			if v == 0 {
+				return 0, errors.New("zero")
+			}
+			return v, nil
+		}, WithName("pool"), WithObserver(log))
+
+		Drain(context.Background(), pool.Run(context.Background(), sliceChan(0, 1, 2)))
+
+		if n := log.count("pool", EventStarted); n != 3 {
+			t.Errorf("Expected 3 started events, got %d", n)
+		}
+		if n := log.count("pool", EventCompleted); n != 3 {
+			t.Errorf("Expected 3 completed events, got %d", n)
+		}
+		failed := 0
+		for _, e := range log.events {
+			if e.Kind == EventCompleted && e.Err != nil {
+				failed++
+			}
+		}
+		if failed != 1 {
+			t.Errorf("Expected 1 failed completion, got %d", failed)
+		}
+	})
+
+	t.Run("pipeline", func(t *testing.T) {
+		log := &eventLog{}
+		ctx := context.Background()
+		p := NewPipeline[int](ctx, WithPipelineName("p"), WithPipelineObserver(log)).
+			AddStage(Map(func(v int) int { return v * 2 })).
+			AddStage(Filter(func(v int) bool { return v > 2 }))
+
+		if got := Collect(ctx, p.Run(sliceChan(1, 2, 3)), 0); len(got) != 2 {
+			t.Fatalf("Expected 2 results, got %v", got)
+		}
+		if n := log.count("p/stage-0", EventEmitted); n != 3 {
+			t.Errorf("Expected 3 items emitted by stage 0, got %d", n)
+		}
+		if n := log.count("p/stage-1", EventEmitted); n != 2 {
+			t.Errorf("Expected 2 items emitted by stage 1, got %d", n)
+		}
+	})
+
+	t.Run("rate limiter", func(t *testing.T) {
+		log := &eventLog{}
+		rl := NewRateLimiter(1, time.Hour, WithLimiterName("rl"), WithLimiterObserver(log))
+		rl.Allow()
+		rl.Allow()
+		if n := log.count("rl", EventThrottled); n != 1 {
+			t.Errorf("Expected 1 throttled event, got %d", n)
+		}
+	})
+
+	t.Run("circuit breaker", func(t *testing.T) {
+		log := &eventLog{}
+		cb := NewCircuitBreaker(1, time.Hour, WithBreakerName("cb"), WithBreakerObserver(log))
+		ctx := context.Background()
+
+		_ = cb.Execute(ctx, func() error { return errors.New("fail") })
+		_ = cb.Execute(ctx, func() error { return nil })
+
+		if n := log.count("cb", EventStateChanged); n != 1 {
+			t.Errorf("Expected 1 state change, got %d", n)
+		}
+		if n := log.count("cb", EventRejected); n != 1 {
+			t.Errorf("Expected 1 rejection, got %d", n)
+		}
+		if log.events[0].State != StateOpen || log.events[0].State.String() != "open" {
+			t.Errorf("Expected transition to open, got %v", log.events[0].State)
+		}
+	})
+}
@@ -0,0 +1,164 @@
+package concurrent
+
+import (
+	"context"
+	"encoding/binary"
+	"os"
+)
+
+// OverflowBuffer creates a stage that decouples a fast producer from a slow consumer.
+// Up to memItems items are held in memory; anything beyond that is spilled to a
+// temporary segment file in dir (os.TempDir() if empty) using encode, and read back
+// with decode in FIFO order. The segment file is removed when the stage exits.
+// If an item cannot be spilled, the stage stops reading input until it can be
+// buffered again, so items are never dropped because of a spill failure.
+// Items that fail to decode are skipped.
+func OverflowBuffer[T any](memItems int, dir string, encode func(T) ([]byte, error), decode func([]byte) (T, error)) Stage[T, T] {
+	if memItems <= 0 {
+		memItems = 1
+	}
+	return func(ctx context.Context, input <-chan T) <-chan T {
+		output := make(chan T)
+		go func() {
+			defer close(output)
+
+			seg := &spillSegment[T]{dir: dir, encode: encode, decode: decode}
+			defer seg.close()
+
+			mem := make([]T, 0, memItems)
+			var held T // item waiting to be buffered after a spill failure
+			holding := false
+
+			enqueue := func(item T) bool {
+				if seg.count == 0 && len(mem) < memItems {
+					mem = append(mem, item)
+					return true
+				}
+				return seg.push(item) == nil
+			}
+
+			in := input
+			for {
+				if in == nil && len(mem) == 0 && !holding {
+					return
+				}
+
+				var out chan<- T
+				var next T
+				if len(mem) > 0 {
+					out = output
+					next = mem[0]
+				}
+				recv := in
+				if holding {
+					recv = nil
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case item, ok := <-recv:
+					if !ok {
+						in = nil
+						continue
+					}
+					if !enqueue(item) {
+						held, holding = item, true
+					}
+				case out <- next:
+					var zero T
+					mem[0] = zero
+					mem = mem[1:]
+					for len(mem) < memItems && seg.count > 0 {
+						if item, ok := seg.pop(); ok {
+							mem = append(mem, item)
+						}
+					}
+					if holding && enqueue(held) {
+						held, holding = zero, false
+					}
+				}
+			}
+		}()
+		return output
+	}
+}
+
+// spillSegment is an append-only file of length-prefixed records that is
+// truncated whenever every record has been read back.
+type spillSegment[T any] struct {
+	dir     string
+	encode  func(T) ([]byte, error)
+	decode  func([]byte) (T, error)
+	file    *os.File
+	readOff int64
+	sizeOff int64
+	count   int
+}
+
+func (s *spillSegment[T]) push(item T) error {
+	data, err := s.encode(item)
+	if err != nil {
+		return err
+	}
+	if s.file == nil {
+		f, err := os.CreateTemp(s.dir, "concurrent-spill-*")
+		if err != nil {
+			return err
+		}
+		s.file = f
+	}
+	rec := make([]byte, 4+len(data))
+	binary.BigEndian.PutUint32(rec, uint32(len(data)))
+	copy(rec[4:], data)
+	if _, err := s.file.WriteAt(rec, s.sizeOff); err != nil {
+		return err
+	}
+	s.sizeOff += int64(len(rec))
+	s.count++
+	return nil
+}
+
+// pop reads the oldest record. It reports false if the record could not be
+// read or decoded; an I/O error discards the rest of the segment.
+func (s *spillSegment[T]) pop() (T, bool) {
+	var zero T
+	var hdr [4]byte
+	if _, err := s.file.ReadAt(hdr[:], s.readOff); err != nil {
+		s.reset()
+		return zero, false
+	}
+	data := make([]byte, binary.BigEndian.Uint32(hdr[:]))
+	if _, err := s.file.ReadAt(data, s.readOff+4); err != nil {
+		s.reset()
+		return zero, false
+	}
+	s.readOff += 4 + int64(len(data))
+	s.count--
+	if s.count == 0 {
+		s.reset()
+	}
+	item, err := s.decode(data)
+	if err != nil {
+		return zero, false
+	}
+	return item, true
+}
+
+func (s *spillSegment[T]) reset() {
+	s.count = 0
+	s.readOff = 0
+	s.sizeOff = 0
+	if s.file != nil {
+		_ = s.file.Truncate(0)
+	}
+}
+
+func (s *spillSegment[T]) close() {
+	if s.file == nil {
+		return
+	}
+	name := s.file.Name()
+	_ = s.file.Close()
+	_ = os.Remove(name)
+}
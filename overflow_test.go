@@ -0,0 +1,104 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/logimos/concurrent/concurrenttest"
+)
+
+func encodeInt(v int) ([]byte, error) { return []byte(strconv.Itoa(v)), nil }
+
+func decodeInt(b []byte) (int, error) { return strconv.Atoi(string(b)) }
+
+func TestOverflowBuffer(t *testing.T) {
+	t.Run("spills and preserves order", func(t *testing.T) {
+		ctx := context.Background()
+		dir := t.TempDir()
+		input := make(chan int)
+
+		output := OverflowBuffer(3, dir, encodeInt, decodeInt)(ctx, input)
+
+		// The producer must not be blocked by the slow consumer.
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 100; i++ {
+				input <- i
+			}
+			close(input)
+		}()
+
+		concurrenttest.AssertClosedWithin(t, done, time.Second)
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 1 {
+			t.Errorf("Expected 1 spill file, got %d", len(entries))
+		}
+
+		var results []int
+		for v := range output {
+			results = append(results, v)
+		}
+		if len(results) != 100 {
+			t.Fatalf("Expected 100 results, got %d", len(results))
+		}
+		for i, v := range results {
+			if v != i {
+				t.Fatalf("Expected %d at index %d, got %d", i, i, v)
+			}
+		}
+
+		entries, _ = os.ReadDir(dir)
+		if len(entries) != 0 {
+			t.Errorf("Expected spill file to be removed, found %d entries", len(entries))
+		}
+	})
+
+	t.Run("encode failure applies backpressure", func(t *testing.T) {
+		ctx := context.Background()
+		input := make(chan int)
+		failing := func(int) ([]byte, error) { return nil, errors.New("encode failed") }
+
+		output := OverflowBuffer(2, t.TempDir(), failing, decodeInt)(ctx, input)
+
+		go func() {
+			for i := 0; i < 10; i++ {
+				input <- i
+			}
+			close(input)
+		}()
+
+		var results []int
+		for v := range output {
+			results = append(results, v)
+		}
+		if len(results) != 10 {
+			t.Fatalf("Expected 10 results, got %d", len(results))
+		}
+		for i, v := range results {
+			if v != i {
+				t.Fatalf("Expected %d at index %d, got %d", i, i, v)
+			}
+		}
+	})
+
+	t.Run("context cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		input := make(chan int)
+		output := OverflowBuffer(1, t.TempDir(), encodeInt, decodeInt)(ctx, input)
+
+		input <- 1
+		cancel()
+
+		// A buffered item may still be delivered; the channel must close afterwards.
+		concurrenttest.AssertClosedWithin(t, output, time.Second)
+	})
+}
@@ -0,0 +1,100 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+)
+
+// PanicError records a panic recovered from a function run by this package.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+// Error implements error.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("concurrent: panic: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// ParallelDo runs fns concurrently and waits for all of them. The first
+// error cancels the context passed to the others. Panics are recovered as
+// *PanicError and all of them are reported, joined with the first error.
+func ParallelDo(ctx context.Context, fns ...func(context.Context) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		first  error
+		panics []error
+	)
+	for _, fn := range fns {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := protect(ctx, fn)
+			if err == nil {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			var pe *PanicError
+			if errors.As(err, &pe) {
+				panics = append(panics, err)
+			} else if first == nil {
+				first = err
+			}
+			cancel()
+		}()
+	}
+	wg.Wait()
+	if len(panics) == 0 {
+		return first
+	}
+	return errors.Join(append([]error{first}, panics...)...)
+}
+
+// Parallel2 runs two functions returning different types concurrently, as
+// ParallelDo does, and returns both results.
+func Parallel2[A, B any](ctx context.Context, fa func(context.Context) (A, error), fb func(context.Context) (B, error)) (A, B, error) {
+	var a A
+	var b B
+	err := ParallelDo(ctx,
+		func(ctx context.Context) (err error) { a, err = fa(ctx); return err },
+		func(ctx context.Context) (err error) { b, err = fb(ctx); return err },
+	)
+	return a, b, err
+}
+
+// Parallel3 is Parallel2 for three functions.
+func Parallel3[A, B, C any](ctx context.Context, fa func(context.Context) (A, error), fb func(context.Context) (B, error), fc func(context.Context) (C, error)) (A, B, C, error) {
+	var a A
+	var b B
+	var c C
+	err := ParallelDo(ctx,
+		func(ctx context.Context) (err error) { a, err = fa(ctx); return err },
+		func(ctx context.Context) (err error) { b, err = fb(ctx); return err },
+		func(ctx context.Context) (err error) { c, err = fc(ctx); return err },
+	)
+	return a, b, c, err
+}
+
+// protect calls fn, converting a panic into a *PanicError.
+func protect(ctx context.Context, fn func(context.Context) error) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &PanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+	return fn(ctx)
+}
@@ -0,0 +1,80 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParallelDo(t *testing.T) {
+	t.Run("runs all", func(t *testing.T) {
+		var a, b, c bool
+		err := ParallelDo(context.Background(),
+			func(context.Context) error { a = true; return nil },
+			func(context.Context) error { b = true; return nil },
+			func(context.Context) error { c = true; return nil },
+		)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !a || !b || !c {
+			t.Error("Expected every function to run")
+		}
+	})
+
+	t.Run("first error cancels the rest", func(t *testing.T) {
+		boom := errors.New("boom")
+		err := ParallelDo(context.Background(),
+			func(context.Context) error { return boom },
+			func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		)
+		if err != boom {
+			t.Errorf("Expected boom, got %v", err)
+		}
+	})
+
+	t.Run("panics are aggregated", func(t *testing.T) {
+		boom := errors.New("boom")
+		err := ParallelDo(context.Background(),
+			func(context.Context) error { panic("first") },
+			func(context.Context) error { panic(boom) },
+		)
+		var pe *PanicError
+		if !errors.As(err, &pe) {
+			t.Fatalf("Expected a PanicError, got %v", err)
+		}
+		if !errors.Is(err, boom) {
+			t.Error("Expected the panic value to be unwrapped")
+		}
+		if !strings.Contains(err.Error(), "first") {
+			t.Errorf("Expected both panics to be reported, got %q", err)
+		}
+		if len(pe.Stack) == 0 {
+			t.Error("Expected a stack trace")
+		}
+	})
+
+	t.Run("typed results", func(t *testing.T) {
+		n, s, err := Parallel2(context.Background(),
+			func(context.Context) (int, error) { return 1, nil },
+			func(context.Context) (string, error) { return "a", nil },
+		)
+		if err != nil || n != 1 || s != "a" {
+			t.Errorf("Expected 1, a, <nil>, got %d, %s, %v", n, s, err)
+		}
+
+		boom := errors.New("boom")
+		_, _, _, err = Parallel3(context.Background(),
+			func(context.Context) (int, error) { return 1, nil },
+			func(context.Context) (string, error) { return "", boom },
+			func(context.Context) (bool, error) { return true, nil },
+		)
+		if err != boom {
+			t.Errorf("Expected boom, got %v", err)
+		}
+	})
+}
@@ -2,7 +2,14 @@ package concurrent
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Stage is a transformation function from in -> out channel.
@@ -10,29 +17,415 @@ type Stage[T any, R any] func(context.Context, <-chan T) <-chan R
 
 // Pipeline represents a data processing pipeline.
 type Pipeline[T any] struct {
-	stages []Stage[T, T]
-	ctx    context.Context
-	cancel context.CancelFunc
+	stages  []pipelineStage[T]
+	ctx     context.Context
+	cancel  context.CancelCauseFunc
+	options *PipelineOptions // shared zero options when none are given
+
+	mu      sync.Mutex
+	emitted []*atomic.Int64 // items emitted per stage, tracked when registered
+	started time.Time
+
+	errs          *errorStream // created by Errors
+	errsRequested atomic.Bool
 }
 
+// noPipelineOptions is shared by the pipelines created without options.
+var noPipelineOptions PipelineOptions
+
 // NewPipeline creates a new pipeline.
-func NewPipeline[T any](ctx context.Context) *Pipeline[T] {
-	ctx, cancel := context.WithCancel(ctx)
-	return &Pipeline[T]{
-		stages: make([]Stage[T, T], 0),
-		ctx:    ctx,
-		cancel: cancel,
+func NewPipeline[T any](ctx context.Context, opts ...PipelineOption) *Pipeline[T] {
+	ctx, cancel := context.WithCancelCause(ctx)
+	p := &Pipeline[T]{
+		ctx:     ctx,
+		cancel:  cancel,
+		options: &noPipelineOptions,
+	}
+	if len(opts) > 0 {
+		options := new(PipelineOptions)
+		for _, opt := range opts {
+			opt(options)
+		}
+		if options.TrackLatency && options.Metrics == nil {
+			options.Metrics = NewMetrics()
+		}
+		p.options = options
+	}
+	if p.options.Registry != nil {
+		p.options.Registry.Register(p.options.Name, p)
+	}
+	return p
+}
+
+// Metrics returns the metrics the pipeline records into, or nil if it has
+// none.
+func (p *Pipeline[T]) Metrics() *Metrics {
+	return p.options.Metrics
+}
+
+// StageError is an error reported with SendError from inside a pipeline stage.
+type StageError struct {
+	Stage int    // index of the stage in the pipeline
+	Name  string // "<pipeline name>/stage-<index>"
+	Err   error
+}
+
+func (e *StageError) Error() string {
+	return e.Name + ": " + e.Err.Error()
+}
+
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
+
+// Errors returns the pipeline-wide stream of *StageError values reported by
+// stages through SendError. It must be called before Run; once called, the
+// caller MUST consume the channel, which is closed after the pipeline's
+// output channel. Errors reported while nobody asked for this channel are
+// discarded.
+func (p *Pipeline[T]) Errors() <-chan error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.errs == nil {
+		p.errs = &errorStream{ch: make(chan error), stop: make(chan struct{})}
+	}
+	p.errsRequested.Store(true)
+	return p.errs.ch
+}
+
+// errorStream is a pipeline's error channel. It is closed only once no
+// SendError call can still be sending on it.
+type errorStream struct {
+	ch      chan error
+	stop    chan struct{}
+	mu      sync.Mutex
+	closed  bool
+	senders sync.WaitGroup
+}
+
+func (s *errorStream) send(ctx context.Context, err error) bool {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return false
+	}
+	s.senders.Add(1)
+	s.mu.Unlock()
+	defer s.senders.Done()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-s.stop:
+		return false
+	case s.ch <- err:
+		return true
+	}
+}
+
+func (s *errorStream) close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	s.mu.Unlock()
+	close(s.stop)
+	s.senders.Wait()
+	close(s.ch)
+}
+
+type stageKey struct{}
+
+// stageInfo is the context of each pipeline stage, carrying itself under
+// stageKey. It routes SendError calls to the pipeline and lets the stage
+// cancel the stages upstream of it.
+type stageInfo struct {
+	context.Context
+	stage    int
+	pipeline string // name of the pipeline
+	errs     *errorStream
+	upstream context.CancelCauseFunc // nil for the first stage
+}
+
+func (se *stageInfo) Value(key any) any {
+	if key == (stageKey{}) {
+		return se
+	}
+	return se.Context.Value(key)
+}
+
+// name returns the stage's name, "<pipeline name>/stage-<index>".
+func (se *stageInfo) name() string {
+	return fmt.Sprintf("%s/stage-%d", se.pipeline, se.stage)
+}
+
+// SendError reports an item-level failure from inside a stage run by a
+// Pipeline, attributing it to that stage. It blocks until the error is
+// received from Pipeline.Errors or ctx is done, and reports whether the
+// error was delivered. It returns false if ctx does not belong to a
+// pipeline stage or nobody called Errors.
+func SendError(ctx context.Context, err error) bool {
+	se, ok := ctx.Value(stageKey{}).(*stageInfo)
+	if !ok || se.errs == nil {
+		return false
 	}
+	return se.errs.send(ctx, &StageError{Stage: se.stage, Name: se.name(), Err: err})
+}
+
+// Report implements Reporter.
+func (p *Pipeline[T]) Report() Report {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	emitted := make([]int64, len(p.emitted))
+	for i, n := range p.emitted {
+		emitted[i] = n.Load()
+	}
+	// Items handed to a stage but not yet emitted; exact for one-to-one
+	// stages, an upper bound for stages that drop items.
+	inFlight := make([]int64, len(emitted))
+	throughput := make([]float64, len(emitted))
+	elapsed := time.Since(p.started).Seconds()
+	for i, n := range emitted {
+		if i > 0 {
+			inFlight[i] = max(emitted[i-1]-n, 0)
+		}
+		if elapsed > 0 {
+			throughput[i] = float64(n) / elapsed
+		}
+	}
+	stats := map[string]any{
+		"stages":     len(p.stages),
+		"emitted":    emitted,
+		"in_flight":  inFlight,
+		"throughput": throughput,
+		"closed":     p.ctx.Err() != nil,
+	}
+	if m := p.options.Metrics; m != nil {
+		stats["metrics"] = m.Snapshot()
+	}
+	return Report{Kind: "pipeline", Stats: stats}
 }
 
 // AddStage adds a stage to the pipeline.
-func (p *Pipeline[T]) AddStage(stage Stage[T, T]) *Pipeline[T] {
-	p.stages = append(p.stages, stage)
+func (p *Pipeline[T]) AddStage(stage Stage[T, T], opts ...StageOption) *Pipeline[T] {
+	if len(opts) > 0 {
+		var options StageOptions
+		for _, opt := range opts {
+			opt(&options)
+		}
+		if options.Parallelism > 1 {
+			stage = Parallel(stage, options.Parallelism, options.PreserveOrder)
+		}
+	}
+	if p.stages == nil {
+		p.stages = make([]pipelineStage[T], 0, 2)
+	}
+	p.stages = append(p.stages, pipelineStage[T]{stage: stage, elem: elemFunc(stage)})
 	return p
 }
 
+// pipelineStage is a stage of a Pipeline. elem is set for Map and Filter
+// stages, which the pipeline runs fused with adjacent ones.
+type pipelineStage[T any] struct {
+	stage Stage[T, T]
+	elem  func(T) (T, bool)
+}
+
+// groupEnd returns the end of the group of stages starting at i: a fused run
+// of element stages, or the single stage i. Stages are not fused when the
+// pipeline tracks them one by one.
+func (p *Pipeline[T]) groupEnd(i int, track bool) int {
+	end := i + 1
+	if !track && p.stages[i].elem != nil {
+		for end < len(p.stages) && p.stages[end].elem != nil {
+			end++
+		}
+	}
+	return end
+}
+
+// runGroup runs a fused group of element stages.
+func runGroup[T any](ctx context.Context, input <-chan T, output chan<- T, group []pipelineStage[T]) {
+	runElems(ctx, input, output, group[0].elem, group[1:])
+}
+
+// stageCtx is the context of a group of pipeline stages and the function
+// canceling it.
+type stageCtx struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+}
+
+// stageCtxs recycles the slices in which pipelines build stage contexts.
+var stageCtxs = sync.Pool{New: func() any { return new([]stageCtx) }}
+
+// elemStages holds the code pointers of the stages made by elemStageFunc,
+// one per instantiation, so a pipeline can tell them apart from others.
+var elemStages struct {
+	sync.RWMutex
+	pcs map[uintptr]bool
+}
+
+// elemProbe is the context in which a stage made by elemStageFunc hands
+// over its function instead of running.
+type elemProbe struct {
+	context.Context
+	fn any
+}
+
+var elemProbes = sync.Pool{New: func() any { return &elemProbe{Context: context.Background()} }}
+
+// elemFunc returns the function of a stage made by Map or Filter, or nil
+// for any other stage.
+func elemFunc[T any](stage Stage[T, T]) func(T) (T, bool) {
+	elemStages.RLock()
+	ok := elemStages.pcs[reflect.ValueOf(stage).Pointer()]
+	elemStages.RUnlock()
+	if !ok {
+		return nil
+	}
+	probe := elemProbes.Get().(*elemProbe)
+	stage(probe, nil)
+	fn, _ := probe.fn.(func(T) (T, bool))
+	probe.fn = nil
+	elemProbes.Put(probe)
+	return fn
+}
+
+// elemStageFunc returns a stage applying fn to each item, dropping those for
+// which it reports false. A pipeline runs consecutive such stages on one
+// goroutine.
+func elemStageFunc[T any](fn func(T) (T, bool)) Stage[T, T] {
+	stage := func(ctx context.Context, input <-chan T) <-chan T {
+		if probe, ok := ctx.(*elemProbe); ok {
+			probe.fn = fn
+			return nil
+		}
+		output := make(chan T)
+		go runElems(ctx, input, output, fn, nil)
+		return output
+	}
+	pc := reflect.ValueOf(stage).Pointer()
+	elemStages.RLock()
+	known := elemStages.pcs[pc]
+	elemStages.RUnlock()
+	if !known {
+		elemStages.Lock()
+		if elemStages.pcs == nil {
+			elemStages.pcs = make(map[uintptr]bool)
+		}
+		elemStages.pcs[pc] = true
+		elemStages.Unlock()
+	}
+	return stage
+}
+
+// runElems applies fn, then the element functions of rest, to every item
+// from input and sends those they keep on output, closing output when done.
+// Like Recv and Send it prefers cancellation, but it looks up ctx.Done only
+// once.
+func runElems[T any](ctx context.Context, input <-chan T, output chan<- T, fn func(T) (T, bool), rest []pipelineStage[T]) {
+	defer close(output)
+	done := ctx.Done()
+items:
+	for {
+		var item T
+		var ok bool
+		select {
+		case <-done:
+			return
+		default:
+		}
+		select {
+		case item, ok = <-input:
+		default:
+			select {
+			case <-done:
+				return
+			case item, ok = <-input:
+			}
+		}
+		if !ok {
+			return
+		}
+		if item, ok = fn(item); !ok {
+			continue
+		}
+		for _, s := range rest {
+			if item, ok = s.elem(item); !ok {
+				continue items
+			}
+		}
+		select {
+		case <-done:
+			return
+		default:
+		}
+		select {
+		case output <- item:
+		default:
+			select {
+			case <-done:
+				return
+			case output <- item:
+			}
+		}
+	}
+}
+
 // Run executes the pipeline with the given input channel.
 func (p *Pipeline[T]) Run(input <-chan T) <-chan T {
+	if p.options.Logger != nil {
+		logEvent(p.ctx, p.options.Logger, slog.LevelDebug, "pipeline started", "pipeline", p.options.Name, "stages", len(p.stages))
+	}
+	var latency *latencyTracker
+	if p.options.TrackLatency {
+		latency = &latencyTracker{key: p.options.LatencyKey}
+		input = stampInput(p.ctx, latency, input)
+	}
+	var acks *ackTracker
+	if p.options.AckMode {
+		acks = &ackTracker{pending: make(map[*atomic.Bool]ackable)}
+		input = trackDeliveries(p.ctx, acks, input)
+	}
+	output := p.run(input)
+	if acks != nil {
+		output = settleDropped(p.ctx, acks, output)
+	}
+	if latency != nil {
+		output = recordLatency(p.ctx, latency, p.options.Metrics, output)
+	}
+	if p.options.Metrics != nil {
+		output = measureOutput(p.ctx, p.options.Metrics, output)
+	}
+	if p.errsRequested.Load() {
+		output = closeAfter(p.ctx, output, p.errs)
+	}
+	return output
+}
+
+// closeAfter forwards items from input and closes errs once input is
+// drained or ctx is cancelled.
+func closeAfter[T any](ctx context.Context, input <-chan T, errs *errorStream) <-chan T {
+	output := make(chan T)
+	go func() {
+		defer errs.close()
+		defer close(output)
+		forwardAll(ctx, input, output)
+	}()
+	return output
+}
+
+// RunSlice runs the pipeline over items and returns everything it emits.
+// Every goroutine it starts has exited or is exiting when it returns, which
+// makes it convenient inside testing/synctest bubbles.
+func (p *Pipeline[T]) RunSlice(items []T) []T {
+	return Collect(p.ctx, p.Run(emitSlice(p.ctx, items)), 0)
+}
+
+func (p *Pipeline[T]) run(input <-chan T) <-chan T {
 	if len(p.stages) == 0 {
 		// No stages, just pass through
 		output := make(chan T)
@@ -58,16 +451,278 @@ func (p *Pipeline[T]) Run(input <-chan T) <-chan T {
 	}
 
 	// Chain stages together
+	track := p.options.Observer != nil || p.options.Registry != nil
+	var counters []*atomic.Int64
+	if w, ok := p.options.Observer.(stageWatcher); ok {
+		w.watchStages(p.options.Name, len(p.stages))
+	}
+	var errs *errorStream
+	if p.errsRequested.Load() {
+		p.mu.Lock()
+		errs = p.errs
+		p.mu.Unlock()
+	}
+	// Each group of stages, a single stage or a fused run of element
+	// stages, has a context that is a child of the next group's, so a stage
+	// can cancel everything upstream of it (see Take) while the stages after
+	// it finish the items it already emitted. Nothing is downstream of the
+	// last group, which runs in the pipeline's context.
+	groups := 0
+	for i := 0; i < len(p.stages); i = p.groupEnd(i, track) {
+		groups++
+	}
+	buf := stageCtxs.Get().(*[]stageCtx)
+	ctxs := slices.Grow((*buf)[:0], groups)[:groups]
+	ctxs[groups-1] = stageCtx{ctx: p.ctx}
+	for k := groups - 2; k >= 0; k-- {
+		ctx, cancel := context.WithCancelCause(ctxs[k+1].ctx)
+		ctxs[k] = stageCtx{ctx: ctx, cancel: cancel}
+	}
 	ch := input
-	for _, stage := range p.stages {
-		ch = stage(p.ctx, ch)
+	for i, k := 0, 0; i < len(p.stages); k++ {
+		end := p.groupEnd(i, track)
+		if s := p.stages[i]; s.elem != nil {
+			output := make(chan T)
+			go runGroup(ctxs[k].ctx, ch, output, p.stages[i:end])
+			ch = output
+		} else {
+			info := &stageInfo{Context: ctxs[k].ctx, stage: i, pipeline: p.options.Name, errs: errs}
+			if k > 0 {
+				info.upstream = ctxs[k-1].cancel
+			}
+			ch = s.stage(info, ch)
+		}
+		if track {
+			counter := new(atomic.Int64)
+			counters = append(counters, counter)
+			name := fmt.Sprintf("%s/stage-%d", p.options.Name, i)
+			ch = observeStage(ctxs[k].ctx, observer{name: name, o: p.options.Observer}, counter, ch)
+		}
+		i = end
+	}
+	clear(ctxs)
+	*buf = ctxs[:0]
+	stageCtxs.Put(buf)
+	if track {
+		p.mu.Lock()
+		p.emitted = counters
+		p.started = time.Now()
+		p.mu.Unlock()
 	}
 	return ch
 }
 
-// Close cancels the pipeline context.
+// latencyTracker holds the entry times of items still in a pipeline, in
+// entry order or by LatencyKey.
+type latencyTracker struct {
+	key   func(item any) any
+	mu    sync.Mutex
+	order []time.Time
+	byKey map[any][]time.Time
+}
+
+func (l *latencyTracker) enter(item any, now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.key == nil {
+		l.order = append(l.order, now)
+		return
+	}
+	if l.byKey == nil {
+		l.byKey = make(map[any][]time.Time)
+	}
+	k := l.key(item)
+	l.byKey[k] = append(l.byKey[k], now)
+}
+
+// exit returns the entry time matching item, if any.
+func (l *latencyTracker) exit(item any) (time.Time, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	queue := l.order
+	var k any
+	if l.key != nil {
+		k = l.key(item)
+		queue = l.byKey[k]
+	}
+	if len(queue) == 0 {
+		return time.Time{}, false
+	}
+	entered := queue[0]
+	queue = queue[1:]
+	if l.key == nil {
+		l.order = queue
+	} else if len(queue) == 0 {
+		delete(l.byKey, k)
+	} else {
+		l.byKey[k] = queue
+	}
+	return entered, true
+}
+
+// stampInput forwards items from input, noting when each one entered.
+func stampInput[T any](ctx context.Context, l *latencyTracker, input <-chan T) <-chan T {
+	output := make(chan T)
+	go func() {
+		defer close(output)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case item, ok := <-input:
+				if !ok {
+					return
+				}
+				l.enter(item, time.Now())
+				select {
+				case <-ctx.Done():
+					return
+				case output <- item:
+				}
+			}
+		}
+	}()
+	return output
+}
+
+// recordLatency forwards items from input, recording each one's time in the
+// pipeline in m.
+func recordLatency[T any](ctx context.Context, l *latencyTracker, m *Metrics, input <-chan T) <-chan T {
+	output := make(chan T)
+	go func() {
+		defer close(output)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case item, ok := <-input:
+				if !ok {
+					return
+				}
+				if entered, ok := l.exit(item); ok {
+					m.RecordLatency(time.Since(entered))
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case output <- item:
+				}
+			}
+		}
+	}()
+	return output
+}
+
+// measureOutput forwards items from input, counting each one in m.
+func measureOutput[T any](ctx context.Context, m *Metrics, input <-chan T) <-chan T {
+	output := make(chan T)
+	go func() {
+		defer close(output)
+		defer m.Finish()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case item, ok := <-input:
+				if !ok {
+					return
+				}
+				m.RecordSuccess()
+				select {
+				case <-ctx.Done():
+					return
+				case output <- item:
+				}
+			}
+		}
+	}()
+	return output
+}
+
+// observeStage forwards items from input, counting and reporting each one as emitted.
+func observeStage[T any](ctx context.Context, obs observer, counter *atomic.Int64, input <-chan T) <-chan T {
+	output := make(chan T)
+	go func() {
+		defer close(output)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case item, ok := <-input:
+				if !ok {
+					return
+				}
+				counter.Add(1)
+				obs.emit(Event{Kind: EventEmitted})
+				select {
+				case <-ctx.Done():
+					return
+				case output <- item:
+				}
+			}
+		}
+	}()
+	return output
+}
+
+// ErrPipelineClosed is the cancellation cause of a pipeline's context after
+// Close.
+var ErrPipelineClosed = errors.New("concurrent: pipeline closed")
+
+// Close cancels the pipeline context with ErrPipelineClosed as its cause.
 func (p *Pipeline[T]) Close() {
-	p.cancel()
+	p.CloseWithCause(ErrPipelineClosed)
+}
+
+// CloseWithCause cancels the pipeline context with the given cause, which
+// stages can read with context.Cause.
+func (p *Pipeline[T]) CloseWithCause(cause error) {
+	logEvent(p.ctx, p.options.Logger, slog.LevelDebug, "pipeline closed", "pipeline", p.options.Name, "cause", cause)
+	p.cancel(cause)
+}
+
+// Cause returns why the pipeline was cancelled: ErrPipelineClosed after
+// Close, the cause given to CloseWithCause, or the cause of the parent
+// context. It returns nil while the pipeline has not been cancelled, which
+// includes a run that completed normally.
+func (p *Pipeline[T]) Cause() error {
+	return context.Cause(p.ctx)
+}
+
+// ErrTakeComplete is the cancellation cause Take gives the stages upstream
+// of it once it has emitted its items.
+var ErrTakeComplete = errors.New("concurrent: take complete")
+
+// Take creates a stage that emits the first n items and then closes its
+// output. In a Pipeline it also cancels the stages before it with
+// ErrTakeComplete as the cause, so they stop producing items nobody will
+// read; elsewhere the caller must stop the input itself.
+func Take[T any](n int) Stage[T, T] {
+	return func(ctx context.Context, input <-chan T) <-chan T {
+		output := make(chan T)
+		go func() {
+			defer close(output)
+			if info, ok := ctx.Value(stageKey{}).(*stageInfo); ok && info.upstream != nil {
+				defer info.upstream(ErrTakeComplete)
+			}
+			for i := 0; i < n; i++ {
+				select {
+				case <-ctx.Done():
+					return
+				case item, ok := <-input:
+					if !ok {
+						return
+					}
+					select {
+					case <-ctx.Done():
+						return
+					case output <- item:
+					}
+				}
+			}
+		}()
+		return output
+	}
 }
 
 // PipelineBuilder provides a fluent interface for building pipelines.
@@ -76,15 +731,15 @@ type PipelineBuilder[T any] struct {
 }
 
 // NewPipelineBuilder creates a new pipeline builder.
-func NewPipelineBuilder[T any](ctx context.Context) *PipelineBuilder[T] {
+func NewPipelineBuilder[T any](ctx context.Context, opts ...PipelineOption) *PipelineBuilder[T] {
 	return &PipelineBuilder[T]{
-		pipeline: NewPipeline[T](ctx),
+		pipeline: NewPipeline[T](ctx, opts...),
 	}
 }
 
 // AddStage adds a stage to the pipeline.
-func (pb *PipelineBuilder[T]) AddStage(stage Stage[T, T]) *PipelineBuilder[T] {
-	pb.pipeline.AddStage(stage)
+func (pb *PipelineBuilder[T]) AddStage(stage Stage[T, T], opts ...StageOption) *PipelineBuilder[T] {
+	pb.pipeline.AddStage(stage, opts...)
 	return pb
 }
 
@@ -93,13 +748,35 @@ func (pb *PipelineBuilder[T]) Build() *Pipeline[T] {
 	return pb.pipeline
 }
 
-// Map creates a stage that applies a function to each item.
-func Map[T any](fn func(T) T) Stage[T, T] {
-	return func(ctx context.Context, input <-chan T) <-chan T {
-		output := make(chan T)
+// Parallel runs n copies of stage and merges their outputs. Unordered
+// copies share the input channel; ordered copies are fed round-robin and
+// read back in the same order, which requires a stage that emits exactly
+// one item per input.
+func Parallel[T any, R any](stage Stage[T, R], n int, ordered bool) Stage[T, R] {
+	if n <= 1 {
+		return stage
+	}
+	return func(ctx context.Context, input <-chan T) <-chan R {
+		outputs := make([]<-chan R, n)
+		if !ordered {
+			for i := range outputs {
+				outputs[i] = stage(ctx, input)
+			}
+			return Merge(ctx, outputs...)
+		}
+
+		inputs := make([]chan T, n)
+		for i := range inputs {
+			inputs[i] = make(chan T)
+			outputs[i] = stage(ctx, inputs[i])
+		}
 		go func() {
-			defer close(output)
-			for {
+			defer func() {
+				for _, ch := range inputs {
+					close(ch)
+				}
+			}()
+			for i := 0; ; i = (i + 1) % n {
 				select {
 				case <-ctx.Done():
 					return
@@ -107,11 +784,30 @@ func Map[T any](fn func(T) T) Stage[T, T] {
 					if !ok {
 						return
 					}
-					result := fn(item)
 					select {
 					case <-ctx.Done():
 						return
-					case output <- result:
+					case inputs[i] <- item:
+					}
+				}
+			}
+		}()
+
+		output := make(chan R)
+		go func() {
+			defer close(output)
+			for i := 0; ; i = (i + 1) % n {
+				select {
+				case <-ctx.Done():
+					return
+				case item, ok := <-outputs[i]:
+					if !ok {
+						return
+					}
+					select {
+					case <-ctx.Done():
+						return
+					case output <- item:
 					}
 				}
 			}
@@ -120,10 +816,18 @@ func Map[T any](fn func(T) T) Stage[T, T] {
 	}
 }
 
-// Filter creates a stage that filters items based on a predicate.
-func Filter[T any](predicate func(T) bool) Stage[T, T] {
-	return func(ctx context.Context, input <-chan T) <-chan T {
-		output := make(chan T)
+// Map creates a stage that applies a function to each item. Consecutive Map
+// and Filter stages of a pipeline run fused in a single goroutine, unless
+// the pipeline reports per-stage counts to an observer or registry.
+func Map[T any](fn func(T) T) Stage[T, T] {
+	return elemStageFunc(func(item T) (T, bool) { return fn(item), true })
+}
+
+// MapR creates a stage that applies a fallible function to each item and emits
+// its outcome as a Result, letting downstream stages branch on success or failure.
+func MapR[T any, R any](fn func(T) (R, error)) Stage[T, Result[R]] {
+	return func(ctx context.Context, input <-chan T) <-chan Result[R] {
+		output := make(chan Result[R])
 		go func() {
 			defer close(output)
 			for {
@@ -134,12 +838,11 @@ func Filter[T any](predicate func(T) bool) Stage[T, T] {
 					if !ok {
 						return
 					}
-					if predicate(item) {
-						select {
-						case <-ctx.Done():
-							return
-						case output <- item:
-						}
+					result := ResultOf(fn(item))
+					select {
+					case <-ctx.Done():
+						return
+					case output <- result:
 					}
 				}
 			}
@@ -148,16 +851,117 @@ func Filter[T any](predicate func(T) bool) Stage[T, T] {
 	}
 }
 
+// Filter creates a stage that filters items based on a predicate. It is
+// fused with adjacent stages like Map.
+func Filter[T any](predicate func(T) bool) Stage[T, T] {
+	return elemStageFunc(func(item T) (T, bool) { return item, predicate(item) })
+}
+
 // Batch creates a stage that batches items into slices.
 func Batch[T any](size int) Stage[T, []T] {
+	return BatchByCost[T](size, 0, nil)
+}
+
+// BatchByCost creates a stage that batches items into slices of at most
+// size items whose summed cost stays within maxCost, e.g. to respect a
+// request size limit in bytes. A batch is flushed before an item that would
+// push it over maxCost; an item costing more than maxCost on its own is
+// emitted alone. A maxCost of zero or less, or a nil cost, disables the
+// cost limit, and a size of zero or less with a cost limit leaves the item
+// count unbounded.
+func BatchByCost[T any](size int, maxCost int64, cost func(T) int64) Stage[T, []T] {
+	return batchStage(size, maxCost, cost, nil)
+}
+
+// BatchPool recycles batch slices between a PooledBatch stage and whatever
+// consumes its batches, cutting allocations in high-rate batch pipelines.
+type BatchPool[T any] struct {
+	size int
+	pool sync.Pool
+}
+
+// NewBatchPool creates a pool of slices with room for size items.
+func NewBatchPool[T any](size int) *BatchPool[T] {
 	if size <= 0 {
 		size = 1
 	}
+	p := &BatchPool[T]{size: size}
+	p.pool.New = func() any {
+		batch := make([]T, 0, size)
+		return &batch
+	}
+	return p
+}
+
+// Get returns an empty slice with room for the pool's size.
+func (p *BatchPool[T]) Get() []T {
+	return (*p.pool.Get().(*[]T))[:0]
+}
+
+// Put returns batch to the pool for reuse. The caller must not use batch
+// afterwards.
+func (p *BatchPool[T]) Put(batch []T) {
+	if cap(batch) < p.size {
+		return
+	}
+	clear(batch)
+	batch = batch[:0]
+	p.pool.Put(&batch)
+}
+
+// PooledBatch is like Batch with the pool's size, but fills slices taken
+// from p instead of allocating a copy per batch. Every batch it emits must
+// be released with p.Put once consumed, for example by PooledUnbatch.
+func PooledBatch[T any](p *BatchPool[T]) Stage[T, []T] {
+	return batchStage(p.size, 0, nil, p)
+}
+
+// PooledUnbatch is like Unbatch but releases each slice to p once its items
+// have been forwarded.
+func PooledUnbatch[T any](p *BatchPool[T]) Stage[[]T, T] {
+	return unbatchStage(p)
+}
+
+// batchStage implements BatchByCost, taking batch slices from pool if it
+// is not nil and copying them otherwise.
+func batchStage[T any](size int, maxCost int64, cost func(T) int64, pool *BatchPool[T]) Stage[T, []T] {
+	costed := maxCost > 0 && cost != nil
+	if size <= 0 && !costed {
+		size = 1
+	}
 	return func(ctx context.Context, input <-chan T) <-chan []T {
 		output := make(chan []T)
 		go func() {
 			defer close(output)
-			batch := make([]T, 0, size)
+			var batch []T
+			if pool != nil {
+				batch = pool.Get()
+				defer func() { pool.Put(batch) }()
+			} else {
+				batch = make([]T, 0, max(size, 0))
+			}
+			var total int64
+			flush := func() bool {
+				if len(batch) == 0 {
+					return true
+				}
+				out := batch
+				if pool == nil {
+					out = append([]T(nil), batch...)
+				}
+				select {
+				case <-ctx.Done():
+					return false
+				case output <- out:
+				}
+				if pool != nil {
+					batch = pool.Get()
+				} else {
+					batch = batch[:0] // Reset batch
+				}
+				total = 0
+				return true
+			}
 			for {
 				select {
 				case <-ctx.Done():
@@ -165,23 +969,22 @@ func Batch[T any](size int) Stage[T, []T] {
 				case item, ok := <-input:
 					if !ok {
 						// Send final batch if it has items
-						if len(batch) > 0 {
-							select {
-							case <-ctx.Done():
-								return
-							case output <- append([]T(nil), batch...):
-							}
-						}
+						flush()
 						return
 					}
+					var c int64
+					if costed {
+						c = cost(item)
+						if total+c > maxCost && !flush() {
+							return
+						}
+					}
 					batch = append(batch, item)
-					if len(batch) >= size {
-						select {
-						case <-ctx.Done():
+					total += c
+					if (size > 0 && len(batch) >= size) || (costed && total >= maxCost) {
+						if !flush() {
 							return
-						case output <- append([]T(nil), batch...):
 						}
-						batch = batch[:0] // Reset batch
 					}
 				}
 			}
@@ -192,6 +995,12 @@ func Batch[T any](size int) Stage[T, []T] {
 
 // Unbatch creates a stage that unbatch slices into individual items.
 func Unbatch[T any]() Stage[[]T, T] {
+	return unbatchStage[T](nil)
+}
+
+// unbatchStage implements Unbatch, releasing slices to pool if it is not
+// nil.
+func unbatchStage[T any](pool *BatchPool[T]) Stage[[]T, T] {
 	return func(ctx context.Context, input <-chan []T) <-chan T {
 		output := make(chan T)
 		go func() {
@@ -211,6 +1020,9 @@ func Unbatch[T any]() Stage[[]T, T] {
 						case output <- item:
 						}
 					}
+					if pool != nil {
+						pool.Put(batch)
+					}
 				}
 			}
 		}()
@@ -218,21 +1030,18 @@ func Unbatch[T any]() Stage[[]T, T] {
 	}
 }
 
-// Tee creates a stage that splits the input into multiple outputs.
-// Note: Tee closes the provided output channels when the input channel closes.
-// Do not reuse these channels after passing them to Tee.
-func Tee[T any](outputs ...chan<- T) Stage[T, T] {
+// Prefetch creates a stage that reads up to n items ahead of downstream
+// demand, so upstream latency (such as fetching the next page of an API)
+// overlaps with downstream processing. A non-positive n is treated as 1.
+func Prefetch[T any](n int) Stage[T, T] {
+	if n <= 0 {
+		n = 1
+	}
 	return func(ctx context.Context, input <-chan T) <-chan T {
-		output := make(chan T)
+		// One item is held by the goroutine below, the rest wait in the buffer.
+		output := make(chan T, n-1)
 		go func() {
 			defer close(output)
-			// Close all output channels when done
-			defer func() {
-				for _, out := range outputs {
-					close(out)
-				}
-			}()
-
 			for {
 				select {
 				case <-ctx.Done():
@@ -241,68 +1050,14 @@ func Tee[T any](outputs ...chan<- T) Stage[T, T] {
 					if !ok {
 						return
 					}
-					// Send to all outputs concurrently
-					var wg sync.WaitGroup
-					for _, out := range outputs {
-						wg.Add(1)
-						go func(ch chan<- T) {
-							defer wg.Done()
-							select {
-							case <-ctx.Done():
-								return
-							case ch <- item:
-							}
-						}(out)
-					}
-
-					// Also send to main output
 					select {
 					case <-ctx.Done():
 						return
 					case output <- item:
 					}
-
-					// Wait for all outputs to complete
-					wg.Wait()
 				}
 			}
 		}()
 		return output
 	}
 }
-
-// Merge creates a stage that merges multiple inputs into one output.
-// The output channel is closed when all input channels are closed or context is cancelled.
-func Merge[T any](ctx context.Context, inputs ...<-chan T) <-chan T {
-	output := make(chan T)
-	var wg sync.WaitGroup
-
-	for _, input := range inputs {
-		wg.Add(1)
-		go func(ch <-chan T) {
-			defer wg.Done()
-			for {
-				select {
-				case <-ctx.Done():
-					return
-				case item, ok := <-ch:
-					if !ok {
-						return
-					}
-					select {
-					case <-ctx.Done():
-						return
-					case output <- item:
-					}
-				}
-			}
-		}(input)
-	}
-
-	go func() {
-		wg.Wait()
-		close(output)
-	}()
-
-	return output
-}
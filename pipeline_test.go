@@ -2,9 +2,18 @@ package concurrent
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/logimos/concurrent/concurrenttest"
 )
 
 func TestNewPipeline(t *testing.T) {
@@ -135,6 +144,195 @@ func TestNewPipeline(t *testing.T) {
 	})
 }
 
+func TestPipelineErrors(t *testing.T) {
+	rejectOdd := func(ctx context.Context, input <-chan int) <-chan int {
+		output := make(chan int)
+		go func() {
+			defer close(output)
+			for v := range input {
+				if v%2 != 0 {
+					SendError(ctx, fmt.Errorf("odd value %d", v))
+					continue
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case output <- v:
+				}
+			}
+		}()
+		return output
+	}
+
+	t.Run("errors are attributed to their stage", func(t *testing.T) {
+		pipeline := NewPipeline[int](context.Background(), WithPipelineName("p"))
+		defer pipeline.Close()
+		pipeline.AddStage(Map(func(v int) int { return v })).AddStage(rejectOdd)
+
+		errs := pipeline.Errors()
+		var collected []error
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for err := range errs {
+				collected = append(collected, err)
+			}
+		}()
+
+		results := pipeline.RunSlice([]int{1, 2, 3, 4})
+		<-done
+		if len(results) != 2 {
+			t.Errorf("Expected 2 results, got %v", results)
+		}
+		if len(collected) != 2 {
+			t.Fatalf("Expected 2 errors, got %v", collected)
+		}
+		var stageErr *StageError
+		if !errors.As(collected[0], &stageErr) || stageErr.Stage != 1 || stageErr.Name != "p/stage-1" {
+			t.Errorf("Expected error from p/stage-1, got %v", collected[0])
+		}
+		if collected[0].Error() != "p/stage-1: odd value 1" {
+			t.Errorf("Expected message %q, got %q", "p/stage-1: odd value 1", collected[0].Error())
+		}
+	})
+
+	t.Run("errors are discarded when not requested", func(t *testing.T) {
+		pipeline := NewPipeline[int](context.Background())
+		defer pipeline.Close()
+		pipeline.AddStage(rejectOdd)
+
+		if results := pipeline.RunSlice([]int{1, 2, 3}); len(results) != 1 {
+			t.Errorf("Expected 1 result, got %v", results)
+		}
+	})
+
+	t.Run("outside a pipeline", func(t *testing.T) {
+		if SendError(context.Background(), errors.New("boom")) {
+			t.Error("Expected SendError to report false outside a pipeline")
+		}
+	})
+
+	t.Run("cancellation closes the error stream", func(t *testing.T) {
+		pipeline := NewPipeline[int](context.Background())
+		pipeline.AddStage(rejectOdd)
+		errs := pipeline.Errors()
+		input := make(chan int)
+		output := pipeline.Run(input)
+		go func() { input <- 1 }() // its error is never read
+		time.Sleep(10 * time.Millisecond)
+		pipeline.Close()
+
+		concurrenttest.AssertClosedWithin(t, output, time.Second)
+		concurrenttest.AssertClosedWithin(t, errs, time.Second)
+		close(input)
+	})
+}
+
+func TestPipelineLatency(t *testing.T) {
+	slow := Map(func(v int) int {
+		time.Sleep(5 * time.Millisecond)
+		return v
+	})
+
+	t.Run("in order", func(t *testing.T) {
+		pipeline := NewPipeline[int](context.Background(), WithEndToEndLatency())
+		defer pipeline.Close()
+		pipeline.AddStage(slow)
+
+		pipeline.RunSlice([]int{1, 2, 3})
+		snapshot := pipeline.Metrics().Snapshot()
+		if snapshot.LatencyCount != 3 {
+			t.Errorf("Expected 3 latencies, got %d", snapshot.LatencyCount)
+		}
+		if snapshot.LatencyMin < 5*time.Millisecond {
+			t.Errorf("Expected latencies of at least 5ms, got %v", snapshot.LatencyMin)
+		}
+		if pipeline.Report().Stats["metrics"] == nil {
+			t.Error("Expected latency to be reported with the pipeline metrics")
+		}
+	})
+
+	t.Run("keyed with dropped items", func(t *testing.T) {
+		metrics := NewMetrics()
+		pipeline := NewPipeline[int](context.Background(),
+			WithPipelineMetrics(metrics),
+			WithLatencyKey(func(item any) any { return item }))
+		defer pipeline.Close()
+		pipeline.AddStage(Filter(func(v int) bool { return v%2 == 0 })).AddStage(slow)
+
+		pipeline.RunSlice([]int{1, 2, 3, 4})
+		if n := metrics.Snapshot().LatencyCount; n != 2 {
+			t.Errorf("Expected 2 latencies, got %d", n)
+		}
+		if metrics.P50() < 5*time.Millisecond {
+			t.Errorf("Expected median latency of at least 5ms, got %v", metrics.P50())
+		}
+	})
+}
+
+func TestPipelineCancellationCause(t *testing.T) {
+	t.Run("close", func(t *testing.T) {
+		pipeline := NewPipeline[int](context.Background())
+		if err := pipeline.Cause(); err != nil {
+			t.Errorf("Expected no cause before Close, got %v", err)
+		}
+		pipeline.Close()
+		if err := pipeline.Cause(); !errors.Is(err, ErrPipelineClosed) {
+			t.Errorf("Expected ErrPipelineClosed, got %v", err)
+		}
+	})
+
+	t.Run("close with cause", func(t *testing.T) {
+		errUpstream := errors.New("upstream failed")
+		pipeline := NewPipeline[int](context.Background())
+		pipeline.CloseWithCause(errUpstream)
+		if err := pipeline.Cause(); !errors.Is(err, errUpstream) {
+			t.Errorf("Expected upstream failure, got %v", err)
+		}
+	})
+
+	t.Run("take cancels upstream stages", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		causes := make(chan error, 1)
+		counting := func(ctx context.Context, _ <-chan int) <-chan int {
+			output := make(chan int)
+			go func() {
+				defer close(output)
+				defer func() { causes <- context.Cause(ctx) }()
+				for i := 0; ; i++ {
+					select {
+					case <-ctx.Done():
+						return
+					case output <- i:
+					}
+				}
+			}()
+			return output
+		}
+
+		pipeline := NewPipeline[int](context.Background())
+		defer pipeline.Close()
+		pipeline.AddStage(counting).AddStage(Take[int](3))
+
+		results := Collect(ctx, pipeline.Run(make(chan int)), 0)
+		if len(results) != 3 {
+			t.Errorf("Expected 3 results, got %v", results)
+		}
+		select {
+		case err := <-causes:
+			if !errors.Is(err, ErrTakeComplete) {
+				t.Errorf("Expected ErrTakeComplete, got %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Expected the upstream stage to stop")
+		}
+		if err := pipeline.Cause(); err != nil {
+			t.Errorf("Expected the pipeline itself not to be cancelled, got %v", err)
+		}
+	})
+}
+
 func TestPipelineBuilder(t *testing.T) {
 	t.Run("fluent interface", func(t *testing.T) {
 		ctx := context.Background()
@@ -169,6 +367,52 @@ func TestPipelineBuilder(t *testing.T) {
 	})
 }
 
+func TestParallelStage(t *testing.T) {
+	slowDouble := Map(func(v int) int {
+		time.Sleep(time.Duration(10-v%10) * time.Millisecond)
+		return v * 2
+	})
+	items := make([]int, 20)
+	for i := range items {
+		items[i] = i
+	}
+
+	t.Run("unordered", func(t *testing.T) {
+		pipeline := NewPipeline[int](context.Background())
+		defer pipeline.Close()
+		pipeline.AddStage(slowDouble, WithParallelism(4))
+
+		results := pipeline.RunSlice(items)
+		if len(results) != len(items) {
+			t.Fatalf("Expected %d results, got %d", len(items), len(results))
+		}
+		sum := 0
+		for _, v := range results {
+			sum += v
+		}
+		if sum != 380 {
+			t.Errorf("Expected sum 380, got %d", sum)
+		}
+	})
+
+	t.Run("preserve order", func(t *testing.T) {
+		pipeline := NewPipelineBuilder[int](context.Background()).
+			AddStage(slowDouble, WithParallelism(4), WithPreserveOrder()).
+			Build()
+		defer pipeline.Close()
+
+		results := pipeline.RunSlice(items)
+		if len(results) != len(items) {
+			t.Fatalf("Expected %d results, got %d", len(items), len(results))
+		}
+		for i, v := range results {
+			if v != i*2 {
+				t.Errorf("Expected %d at index %d, got %d", i*2, i, v)
+			}
+		}
+	})
+}
+
 func TestMap(t *testing.T) {
 	t.Run("basic mapping", func(t *testing.T) {
 		ctx := context.Background()
@@ -205,6 +449,34 @@ func TestMap(t *testing.T) {
 	})
 }
 
+func TestMapR(t *testing.T) {
+	ctx := context.Background()
+	input := make(chan string)
+
+	output := MapR(strconv.Atoi)(ctx, input)
+
+	go func() {
+		for _, s := range []string{"1", "two", "3"} {
+			input <- s
+		}
+		close(input)
+	}()
+
+	var results []Result[int]
+	for r := range output {
+		results = append(results, r)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+	if !results[0].Ok() || results[0].Value != 1 {
+		t.Errorf("Expected first result to be 1, got %+v", results[0])
+	}
+	if results[1].Ok() {
+		t.Error("Expected second result to be an error")
+	}
+}
+
 func TestFilter(t *testing.T) {
 	t.Run("basic filtering", func(t *testing.T) {
 		ctx := context.Background()
@@ -295,6 +567,100 @@ func TestBatch(t *testing.T) {
 	})
 }
 
+func TestPooledBatch(t *testing.T) {
+	ctx := context.Background()
+	pool := NewBatchPool[int](3)
+	items := make([]int, 10)
+	for i := range items {
+		items[i] = i
+	}
+
+	batches := PooledBatch(pool)(ctx, emitSlice(ctx, items))
+	results := Collect(ctx, PooledUnbatch(pool)(ctx, batches), 0)
+	if len(results) != 10 {
+		t.Fatalf("Expected 10 items, got %v", results)
+	}
+	for i, v := range results {
+		if v != i {
+			t.Fatalf("Expected items in order, got %v", results)
+		}
+	}
+
+	batch := pool.Get()
+	if len(batch) != 0 || cap(batch) < 3 {
+		t.Errorf("Expected an empty slice with room for 3, got len %d cap %d", len(batch), cap(batch))
+	}
+}
+
+func TestBatchByCost(t *testing.T) {
+	words := []string{"aa", "bbb", "c", "dddddddddd", "ee", "f"}
+	length := func(s string) int64 { return int64(len(s)) }
+
+	t.Run("flushes before exceeding cost", func(t *testing.T) {
+		ctx := context.Background()
+		output := BatchByCost(0, 5, length)(ctx, emitSlice(ctx, words))
+
+		results := Collect(ctx, output, 0)
+		// [aa bbb] reaches 5, [c] is followed by an oversized item, which is
+		// emitted alone, then [ee f].
+		expected := [][]string{{"aa", "bbb"}, {"c"}, {"dddddddddd"}, {"ee", "f"}}
+		if len(results) != len(expected) {
+			t.Fatalf("Expected %v, got %v", expected, results)
+		}
+		for i, batch := range results {
+			if strings.Join(batch, ",") != strings.Join(expected[i], ",") {
+				t.Errorf("Expected batch %d to be %v, got %v", i, expected[i], batch)
+			}
+		}
+	})
+
+	t.Run("item count still applies", func(t *testing.T) {
+		ctx := context.Background()
+		output := BatchByCost(2, 100, length)(ctx, emitSlice(ctx, words))
+
+		results := Collect(ctx, output, 0)
+		if len(results) != 3 {
+			t.Errorf("Expected 3 batches, got %v", results)
+		}
+	})
+}
+
+func TestPrefetch(t *testing.T) {
+	t.Run("reads ahead", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		input := make(chan int)
+		var sent atomic.Int32
+		go func() {
+			for i := 0; i < 10; i++ {
+				select {
+				case <-ctx.Done():
+					return
+				case input <- i:
+					sent.Add(1)
+				}
+			}
+			close(input)
+		}()
+
+		output := Prefetch[int](3)(ctx, input)
+		time.Sleep(20 * time.Millisecond)
+		if n := sent.Load(); n != 3 {
+			t.Errorf("Expected 3 items read ahead, got %d", n)
+		}
+
+		results := Collect(ctx, output, 0)
+		for i, v := range results {
+			if v != i {
+				t.Fatalf("Expected items in order, got %v", results)
+			}
+		}
+		if len(results) != 10 {
+			t.Errorf("Expected 10 items, got %d", len(results))
+		}
+	})
+}
+
 func TestUnbatch(t *testing.T) {
 	t.Run("basic unbatching", func(t *testing.T) {
 		ctx := context.Background()
@@ -426,6 +792,102 @@ func TestMerge(t *testing.T) {
 	})
 }
 
+func TestPipelineFusion(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("fused chain", func(t *testing.T) {
+		p := NewPipeline[int](ctx)
+		p.AddStage(Map(func(v int) int { return v * 2 })).
+			AddStage(Filter(func(v int) bool { return v%3 != 0 })).
+			AddStage(Map(func(v int) int { return v + 1 })).
+			AddStage(Map(func(v int) int { return v * 10 })).
+			AddStage(Filter(func(v int) bool { return v < 100 }))
+		results := p.RunSlice([]int{1, 2, 3, 4, 5})
+		want := []int{30, 50, 90}
+		if len(results) != len(want) {
+			t.Fatalf("Expected %v, got %v", want, results)
+		}
+		for i := range want {
+			if results[i] != want[i] {
+				t.Errorf("Expected %v, got %v", want, results)
+				break
+			}
+		}
+	})
+
+	t.Run("stage indexes", func(t *testing.T) {
+		p := NewPipeline[int](ctx)
+		errs := p.Errors()
+		p.AddStage(Map(func(v int) int { return v })).
+			AddStage(Filter(func(int) bool { return true })).
+			AddStage(func(ctx context.Context, input <-chan int) <-chan int {
+				output := make(chan int)
+				go func() {
+					defer close(output)
+					for range input {
+						SendError(ctx, errors.New("boom"))
+					}
+				}()
+				return output
+			})
+		output := p.Run(emitSlice(ctx, []int{1}))
+		var stageErr *StageError
+		if err := <-errs; !errors.As(err, &stageErr) || stageErr.Stage != 2 {
+			t.Errorf("Expected an error from stage 2, got %v", err)
+		}
+		Drain(ctx, output)
+	})
+
+	t.Run("take stops fused stages", func(t *testing.T) {
+		stopped := make(chan struct{})
+		counting := func(ctx context.Context, _ <-chan int) <-chan int {
+			output := make(chan int)
+			go func() {
+				defer close(output)
+				defer close(stopped)
+				for i := 0; Send(ctx, output, i); i++ {
+				}
+			}()
+			return output
+		}
+		p := NewPipeline[int](ctx)
+		defer p.Close()
+		p.AddStage(counting).
+			AddStage(Map(func(v int) int { return v * 2 })).
+			AddStage(Filter(func(v int) bool { return v%4 == 0 })).
+			AddStage(Take[int](3))
+		results := Collect(ctx, p.Run(make(chan int)), 0)
+		if !slices.Equal(results, []int{0, 4, 8}) {
+			t.Errorf("Expected [0 4 8], got %v", results)
+		}
+		select {
+		case <-stopped:
+		case <-time.After(time.Second):
+			t.Fatal("Expected Take to stop the stages upstream of the fused ones")
+		}
+	})
+
+	t.Run("parallel map", func(t *testing.T) {
+		var calls atomic.Int64
+		p := NewPipeline[int](ctx)
+		p.AddStage(Map(func(v int) int { return v + 1 })).
+			AddStage(Parallel(Map(func(v int) int {
+				calls.Add(1)
+				return v * 2
+			}), 4, false)).
+			AddStage(Filter(func(v int) bool { return v > 0 }))
+		results := p.RunSlice([]int{1, 2, 3, 4, 5, 6, 7, 8})
+		sort.Ints(results)
+		want := []int{4, 6, 8, 10, 12, 14, 16, 18}
+		if !slices.Equal(results, want) {
+			t.Errorf("Expected %v, got %v", want, results)
+		}
+		if n := calls.Load(); n != 8 {
+			t.Errorf("Expected 8 calls, got %d", n)
+		}
+	})
+}
+
 func BenchmarkPipeline(b *testing.B) {
 	ctx := context.Background()
 
@@ -501,3 +963,27 @@ func BenchmarkFilter(b *testing.B) {
 		}
 	}
 }
+
+func BenchmarkBatchUnbatch(b *testing.B) {
+	ctx := context.Background()
+	run := func(b *testing.B, batch Stage[int, []int], unbatch Stage[[]int, int]) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			input := make(chan int, 100)
+			for j := 0; j < 100; j++ {
+				input <- j
+			}
+			close(input)
+			for range unbatch(ctx, batch(ctx, input)) {
+				// Consume results
+			}
+		}
+	}
+	b.Run("copy", func(b *testing.B) {
+		run(b, Batch[int](64), Unbatch[int]())
+	})
+	b.Run("pooled", func(b *testing.B) {
+		pool := NewBatchPool[int](64)
+		run(b, PooledBatch(pool), PooledUnbatch(pool))
+	})
+}
@@ -2,64 +2,1092 @@ package concurrent
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"hash/maphash"
+	"log/slog"
+	"runtime"
+	"runtime/debug"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
-// Pool runs jobs with a fixed number of workers.
-// If fn returns an error, that job's result is simply dropped.
-// Use a wrapper fn if you need to propagate per-item errors.
+// ErrDuplicateJob is the error of a job submitted with Pool.Submit that was
+// not run because WithDedup coalesced it into a job still running, or
+// WithIdempotency found it already processed.
+var ErrDuplicateJob = errors.New("concurrent: duplicate job skipped")
+
+// ErrPoolClosed is the error of a job submitted after Pool.Shutdown.
+var ErrPoolClosed = errors.New("concurrent: pool is shut down")
+
+// Pool runs jobs with a number of workers that SetWorkers can change.
+// A job whose fn returns an error produces no result; WithErrorPolicy
+// decides whether the pool drops the failure, stops on it or routes it to
+// the error channel of RunE as a *JobError carrying the failed input.
 type Pool[T any, R any] struct {
-	workers int
 	fn      func(context.Context, T) (R, error)
+	obs     observer
+	options PoolOptions
+	dedup   *dedupGroup[R]
+	limiter HTTPLimiter // from WithRateLimit
+	retry   RetryConfig // from WithRetryConfig
+
+	// keepFailed, if set, turns a failed job into a result that is
+	// delivered like any other, as JobPool does.
+	keepFailed func(j T, r R, err error) R
+
+	resizeMu  sync.Mutex // serializes SetWorkers and Shutdown
+	mu        sync.Mutex
+	workers   int
+	closed    bool
+	runs      map[*poolRun[R]]struct{} // runs in progress
+	submitIDs []bool                   // worker IDs held by submitted jobs
+
+	submitted *Semaphore     // bounds the jobs run by Submit
+	submits   sync.WaitGroup // submitted jobs not yet finished
+	waiting   atomic.Int64
+
+	active    atomic.Int64
+	processed atomic.Int64
+	failed    atomic.Int64
 }
 
-// NewPool creates a pool with n workers and a processing function.
-func NewPool[T any, R any](n int, fn func(context.Context, T) (R, error)) *Pool[T, R] {
+// NewPool creates a pool with n workers and a processing function. Every
+// PoolOption applies except WithWorkers; see NewPoolWithOptions.
+func NewPool[T any, R any](n int, fn func(context.Context, T) (R, error), opts ...PoolOption) *Pool[T, R] {
 	if n <= 0 {
 		n = 1
 	}
-	return &Pool[T, R]{workers: n, fn: fn}
+	var options PoolOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.Chaos != nil {
+		fn = Chaos(*options.Chaos, fn)
+	}
+	p := &Pool[T, R]{
+		workers: n,
+		fn:      fn,
+		obs:     observer{name: options.Name, o: options.Observer},
+		options: options,
+		runs:    make(map[*poolRun[R]]struct{}),
+
+		submitted: NewSemaphore(n),
+	}
+	if options.DedupKey != nil {
+		p.dedup = &dedupGroup[R]{ttl: options.DedupTTL, calls: make(map[any]*dedupCall[R])}
+	}
+	if rl := options.RateLimit; rl != nil {
+		p.limiter = NewBurstRateLimit(rl.Limit, rl.Interval, rl.Burst, WithLimiterName(options.Name))
+	}
+	if options.RetryCount > 0 {
+		p.retry = DefaultRetryConfig()
+		p.retry.MaxRetries = options.RetryCount
+		p.retry.BaseDelay = options.Backoff
+		p.retry.Logger = options.Logger
+		p.retry.Name = options.Name
+		p.retry.Observer = options.Observer
+	}
+	if options.Registry != nil {
+		options.Registry.Register(options.Name, p)
+	}
+	return p
+}
+
+// NewPoolWithOptions creates a pool configured entirely by opts, taking the
+// number of workers from WithWorkers, or from DefaultPoolOptions if it is
+// not given.
+func NewPoolWithOptions[T any, R any](fn func(context.Context, T) (R, error), opts ...PoolOption) *Pool[T, R] {
+	var options PoolOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	n := options.Workers
+	if n <= 0 {
+		n = DefaultPoolOptions().Workers
+	}
+	return NewPool(n, fn, opts...)
+}
+
+// Report implements Reporter.
+func (p *Pool[T, R]) Report() Report {
+	workers := p.Workers()
+	stats := map[string]any{
+		"workers":     workers,
+		"active":      p.active.Load(),
+		"processed":   p.processed.Load(),
+		"errors":      p.failed.Load(),
+		"utilization": float64(p.active.Load()) / float64(workers),
+	}
+	if m := p.options.Metrics; m != nil {
+		stats["metrics"] = m.Snapshot()
+	}
+	return Report{Kind: "pool", Stats: stats}
+}
+
+// Workers returns the number of workers the pool runs.
+func (p *Pool[T, R]) Workers() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.workers
+}
+
+// SetWorkers changes the number of workers, applying to runs in progress as
+// well as later ones, so concurrency can be tuned without recreating the
+// pool. New workers start at once; surplus workers exit once they finish
+// their current job. Runs using WithAffinity keep the number of workers
+// they started with, since their jobs are routed by worker count. A count
+// of zero or less means one worker.
+func (p *Pool[T, R]) SetWorkers(n int) {
+	if n <= 0 {
+		n = 1
+	}
+	p.resizeMu.Lock()
+	defer p.resizeMu.Unlock()
+	p.mu.Lock()
+	p.workers = n
+	runs := p.activeRuns()
+	p.mu.Unlock()
+	p.submitted.SetCapacity(n)
+	for _, run := range runs {
+		run.resize(n)
+	}
+}
+
+// activeRuns returns the runs in progress, or none once the pool is shut
+// down. Callers must hold mu.
+func (p *Pool[T, R]) activeRuns() []*poolRun[R] {
+	if p.closed {
+		return nil
+	}
+	runs := make([]*poolRun[R], 0, len(p.runs))
+	for run := range p.runs {
+		runs = append(runs, run)
+	}
+	return runs
+}
+
+// Shutdown stops the pool gracefully. Its runs stop taking jobs from their
+// channels, and Submit fails with ErrPoolClosed. Shutdown then waits until
+// the jobs already running have finished, their results have been
+// delivered and every results channel is closed. Jobs still in a channel
+// are left unread. If ctx is done first, the runs are canceled with ctx's
+// cause and Shutdown returns ctx's error; submitted jobs keep their own
+// contexts. Runs started after Shutdown close their results at once.
+func (p *Pool[T, R]) Shutdown(ctx context.Context) error {
+	p.resizeMu.Lock()
+	p.mu.Lock()
+	runs := p.activeRuns()
+	p.closed = true
+	p.mu.Unlock()
+	for _, run := range runs {
+		run.retire()
+	}
+	p.resizeMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, run := range runs {
+			<-run.closed
+		}
+		p.submits.Wait()
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		for _, run := range runs {
+			run.stop(context.Cause(ctx))
+		}
+		return ctx.Err()
+	}
+}
+
+// Resize is SetWorkers.
+func (p *Pool[T, R]) Resize(n int) {
+	p.SetWorkers(n)
 }
 
 // Run executes jobs until ctx is canceled or jobs is closed.
+// See WithDrainOnCancel for finishing accepted jobs after cancellation, and
+// WithErrorPolicy for what happens to failed jobs.
 // The caller MUST consume the results channel until it is closed.
 func (p *Pool[T, R]) Run(ctx context.Context, jobs <-chan T) <-chan R {
-	results := make(chan R)
+	return p.start(ctx, jobs, false).results
+}
+
+// RunE is like Run but also returns the errors of failed jobs, as selected
+// by the pool's ErrorPolicy: none for ErrorDrop, the error that stopped the
+// pool for ErrorStop, and every error for ErrorRoute. Job failures are
+// reported as *JobError[T] values carrying the failed input. The error
+// channel is closed after the results channel.
+// The caller MUST consume both channels until they are closed.
+func (p *Pool[T, R]) RunE(ctx context.Context, jobs <-chan T) (<-chan R, <-chan error) {
+	run := p.start(ctx, jobs, true)
+	return run.results, run.errs
+}
+
+// poolRun is the state shared by the workers of one Run.
+type poolRun[R any] struct {
+	results chan R
+	errs    chan error // nil when nobody reads errors
+	stop    context.CancelCauseFunc
+	once    sync.Once
+
+	// spawn starts worker id, which exits when quit is closed. It is nil
+	// for runs that cannot be resized.
+	spawn    func(id int, quit <-chan struct{}) error
+	mu       sync.Mutex
+	quits    []chan struct{} // one per current worker, indexed by worker ID
+	live     int             // worker goroutines not yet returned
+	finished chan struct{}   // closed when live drops to zero
+	closed   chan struct{}   // closed after results and errs
+}
 
-	var wg sync.WaitGroup
-	wg.Add(p.workers)
+// resize starts or retires workers until the run has n of them.
+func (run *poolRun[R]) resize(n int) {
+	run.mu.Lock()
+	if run.spawn == nil || run.live == 0 {
+		run.mu.Unlock()
+		return
+	}
+	for len(run.quits) > n {
+		last := len(run.quits) - 1
+		close(run.quits[last])
+		run.quits = run.quits[:last]
+	}
+	first := len(run.quits)
+	for len(run.quits) < n {
+		run.quits = append(run.quits, make(chan struct{}))
+		run.live++
+	}
+	quits := run.quits[first:]
+	run.mu.Unlock()
+	for i, quit := range quits {
+		if run.spawn(first+i, quit) != nil {
+			run.exit()
+		}
+	}
+}
+
+// retire makes every worker exit once it finishes its current job.
+func (run *poolRun[R]) retire() {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	for _, quit := range run.quits {
+		close(quit)
+	}
+	run.quits = nil
+}
+
+// exit records that a worker goroutine has returned.
+func (run *poolRun[R]) exit() {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	if run.live--; run.live == 0 {
+		close(run.finished)
+	}
+}
 
-	for i := 0; i < p.workers; i++ {
-		go func() {
-			defer wg.Done()
-			for {
+func (p *Pool[T, R]) start(ctx context.Context, jobs <-chan T, withErrors bool) *poolRun[R] {
+	ctx, stop := context.WithCancelCause(ctx)
+	run := &poolRun[R]{
+		results:  make(chan R, max(p.options.BufferSize, 0)),
+		stop:     stop,
+		finished: make(chan struct{}),
+		closed:   make(chan struct{}),
+	}
+	if withErrors {
+		// ErrorStop sends a single error, which must not block the worker
+		// that stops the pool.
+		run.errs = make(chan error, 1)
+	}
+
+	p.mu.Lock()
+	n := p.workers
+	if p.closed {
+		n = 0
+		close(run.finished)
+	}
+	run.quits = make([]chan struct{}, n)
+	for i := range run.quits {
+		run.quits[i] = make(chan struct{})
+	}
+	run.live = n
+	initial := slices.Clone(run.quits)
+	var sources []<-chan T
+	if n > 0 {
+		sources = p.sources(ctx, jobs, n)
+	}
+	budget := budgetFor(p.options.Registry)
+	spawn := func(id int, source <-chan T, quit <-chan struct{}) error {
+		worker := func() {
+			defer run.exit()
+			p.work(ctx, id, source, quit, run)
+		}
+		if budget == nil {
+			go worker()
+			return nil
+		}
+		return budget.Go(ctx, p.budgetName(), worker)
+	}
+	if p.options.Affinity == nil {
+		run.spawn = func(id int, quit <-chan struct{}) error {
+			return spawn(id, jobs, quit)
+		}
+	}
+	p.runs[run] = struct{}{}
+	p.mu.Unlock()
+
+	for i, quit := range initial {
+		if err := spawn(i, sources[i], quit); err != nil {
+			// A pool never runs short of workers: with affinity routing,
+			// the jobs of a missing worker would never be read. The error
+			// is reported from the missing worker's slot, since the caller
+			// cannot read errs before start returns.
+			go func() {
+				defer run.exit()
+				run.once.Do(func() {
+					if run.errs != nil {
+						deliverTo[error](ctx, run.errs, err, false)
+					}
+					stop(err)
+				})
+			}()
+			for range n - i - 1 {
+				run.exit()
+			}
+			break
+		}
+	}
+
+	// Closer
+	go func() {
+		<-run.finished
+		p.mu.Lock()
+		delete(p.runs, run)
+		p.mu.Unlock()
+		stop(nil)
+		if p.options.Metrics != nil {
+			p.options.Metrics.Finish()
+		}
+		close(run.results)
+		if run.errs != nil {
+			close(run.errs)
+		}
+		close(run.closed)
+	}()
+
+	return run
+}
+
+// budgetName is the component name the pool's workers are counted under
+// in a GoroutineBudget.
+func (p *Pool[T, R]) budgetName() string {
+	if p.options.Name != "" {
+		return p.options.Name
+	}
+	return "pool"
+}
+
+// work is the loop of worker id, processing jobs until ctx is canceled or
+// jobs is closed, then draining according to the pool's DrainPolicy. The
+// worker also returns, without draining, once quit is closed.
+func (p *Pool[T, R]) work(ctx context.Context, id int, jobs <-chan T, quit <-chan struct{}, run *poolRun[R]) {
+	ctx = context.WithValue(ctx, workerIDKey{}, id)
+	// Jobs run with jobCtx, which outlives ctx when they must be finished.
+	jobCtx := ctx
+	if p.options.Drain != DrainNone {
+		jobCtx = context.WithoutCancel(ctx)
+	}
+	if p.options.LockThread {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+	}
+	if init := p.options.WorkerInit; init != nil {
+		if err := init(ctx, id); err != nil {
+			run.once.Do(func() {
+				logEvent(ctx, p.options.Logger, slog.LevelWarn, "pool worker init failed", "pool", p.options.Name, "worker", id, "error", err)
+				if run.errs != nil {
+					deliverTo[error](ctx, run.errs, err, false)
+				}
+				run.stop(err)
+			})
+			return
+		}
+	}
+	logEvent(ctx, p.options.Logger, slog.LevelDebug, "pool worker started", "pool", p.options.Name, "worker", id)
+	defer logEvent(ctx, p.options.Logger, slog.LevelDebug, "pool worker stopped", "pool", p.options.Name, "worker", id)
+	for {
+		// Check first so a ready job doesn't win over a cancellation that
+		// happened while the last one was running.
+		if ctx.Err() != nil {
+			if p.options.Drain == DrainBuffered {
+				p.drain(ctx, jobCtx, id, jobs, run)
+			}
+			return
+		}
+		select {
+		case <-quit:
+			return
+		default:
+		}
+		select {
+		case <-ctx.Done():
+			continue
+		case <-quit:
+			return
+		case j, ok := <-jobs:
+			if !ok {
+				return
+			}
+			// compute outside select to avoid blocking ctx.Done path
+			if !p.handle(ctx, jobCtx, id, j, len(jobs), run) {
+				return
+			}
+		}
+	}
+}
+
+// handle runs j and sends its results, reporting false if ctx was canceled
+// before they could be delivered. Pools that drain deliver them regardless.
+func (p *Pool[T, R]) handle(ctx, jobCtx context.Context, id int, j T, queued int, run *poolRun[R]) bool {
+	r, copies, err := p.runJob(jobCtx, j, queued)
+	if err != nil {
+		if p.keepFailed == nil {
+			return p.fail(ctx, id, &JobError[T]{Input: j, Err: err}, run)
+		}
+		r, copies = p.keepFailed(j, r, err), max(copies, 1)
+	}
+	for range copies {
+		if !deliverTo(ctx, run.results, r, p.options.Drain != DrainNone) {
+			return false
+		}
+	}
+	if err != nil && p.options.ErrorPolicy == ErrorStop {
+		// The failure was delivered as a result; it only stops the run.
+		return p.fail(ctx, id, &JobError[T]{Input: j, Err: err}, run)
+	}
+	return true
+}
+
+// JobError is the error of a failed pool job, as reported by Pool.RunE. It
+// carries the job's input so callers can tell which job failed.
+type JobError[T any] struct {
+	Input T
+	Err   error
+}
+
+func (e *JobError[T]) Error() string {
+	return fmt.Sprintf("concurrent: job %v: %v", e.Input, e.Err)
+}
+
+func (e *JobError[T]) Unwrap() error {
+	return e.Err
+}
+
+// fail applies the pool's ErrorPolicy to a failed job. The run is stopped
+// with the job's own error as the cause.
+func (p *Pool[T, R]) fail(ctx context.Context, id int, err *JobError[T], run *poolRun[R]) bool {
+	switch p.options.ErrorPolicy {
+	case ErrorStop:
+		run.once.Do(func() {
+			logEvent(ctx, p.options.Logger, slog.LevelWarn, "pool stopped by failed job", "pool", p.options.Name, "worker", id, "error", err.Err)
+			if run.errs != nil {
+				run.errs <- err
+			}
+			run.stop(err.Err)
+		})
+		return false
+	case ErrorRoute:
+		if run.errs != nil {
+			return deliverTo[error](ctx, run.errs, err, p.options.Drain != DrainNone)
+		}
+	}
+	logEvent(ctx, p.options.Logger, slog.LevelWarn, "pool dropped failed job", "pool", p.options.Name, "worker", id, "error", err.Err)
+	p.obs.emit(Event{Kind: EventDropped, Err: err.Err})
+	return true
+}
+
+// deliverTo sends v on ch, giving up when ctx is canceled unless drain is
+// set.
+func deliverTo[V any](ctx context.Context, ch chan<- V, v V, drain bool) bool {
+	if drain {
+		ch <- v
+		return true
+	}
+	select {
+	case <-ctx.Done():
+		return false
+	case ch <- v:
+		return true
+	}
+}
+
+// drain processes the jobs still buffered in jobs after cancellation. A
+// worker fed by the affinity router reads until the router closes its
+// channel, since the router drains the shared channel into it.
+func (p *Pool[T, R]) drain(ctx, jobCtx context.Context, id int, jobs <-chan T, run *poolRun[R]) {
+	routed := p.options.Affinity != nil
+	for {
+		var j T
+		var ok bool
+		if routed {
+			j, ok = <-jobs
+		} else {
+			select {
+			case j, ok = <-jobs:
+			default:
+				return
+			}
+		}
+		if !ok {
+			return
+		}
+		p.handle(ctx, jobCtx, id, j, len(jobs), run)
+	}
+}
+
+// RunSources is like Run but reads jobs from several channels, such as a
+// control channel and a bulk channel, interleaving them as they arrive so
+// neither starves the other. The results channel is closed once every
+// source is closed or ctx is canceled.
+// The caller MUST consume the results channel until it is closed.
+func (p *Pool[T, R]) RunSources(ctx context.Context, sources ...<-chan T) <-chan R {
+	return p.Run(ctx, Merge(ctx, sources...))
+}
+
+// RunWeighted is like RunSources but shares the workers among sources in
+// proportion to their weights while they are backlogged, so one noisy
+// tenant cannot monopolize the pool. See MergeWeighted.
+// The caller MUST consume the results channel until it is closed.
+func (p *Pool[T, R]) RunWeighted(ctx context.Context, sources ...WeightedSource[T]) <-chan R {
+	return p.Run(ctx, MergeWeighted(ctx, sources...))
+}
+
+// sources returns the channel each worker reads jobs from: jobs itself, or
+// with WithAffinity a channel per worker fed by a router goroutine.
+func (p *Pool[T, R]) sources(ctx context.Context, jobs <-chan T, n int) []<-chan T {
+	sources := make([]<-chan T, n)
+	if p.options.Affinity == nil {
+		for i := range sources {
+			sources[i] = jobs
+		}
+		return sources
+	}
+	routes := make([]chan T, n)
+	for i := range routes {
+		routes[i] = make(chan T)
+		sources[i] = routes[i]
+	}
+	go func() {
+		defer func() {
+			for _, ch := range routes {
+				close(ch)
+			}
+		}()
+		route := func(j T) chan T {
+			return routes[affinityHash(p.options.Affinity(j))%uint64(n)]
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				if p.options.Drain == DrainBuffered {
+					// Workers keep reading their routes until they are closed.
+					for {
+						select {
+						case j, ok := <-jobs:
+							if !ok {
+								return
+							}
+							route(j) <- j
+						default:
+							return
+						}
+					}
+				}
+				return
+			case j, ok := <-jobs:
+				if !ok {
+					return
+				}
+				if p.options.Drain == DrainBuffered {
+					route(j) <- j
+					continue
+				}
 				select {
 				case <-ctx.Done():
 					return
-				case j, ok := <-jobs:
-					if !ok {
-						return
-					}
-					// compute outside select to avoid blocking ctx.Done path
-					r, err := p.fn(ctx, j)
-					if err != nil {
-						continue
-					}
-					select {
-					case <-ctx.Done():
-						return
-					case results <- r:
-					}
+				case route(j) <- j:
 				}
 			}
-		}()
+		}
+	}()
+	return sources
+}
+
+var affinitySeed = maphash.MakeSeed()
+
+// affinityHash hashes a WithAffinity key. Common key types are hashed
+// directly; anything else is hashed by its fmt representation.
+func affinityHash(key any) uint64 {
+	switch k := key.(type) {
+	case string:
+		return maphash.String(affinitySeed, k)
+	case int:
+		return mixHash(uint64(k))
+	case int64:
+		return mixHash(uint64(k))
+	case uint64:
+		return mixHash(k)
+	default:
+		return maphash.String(affinitySeed, fmt.Sprint(k))
 	}
+}
 
-	// Closer
-	go func() {
-		wg.Wait()
-		close(results)
+// mixHash is the splitmix64 finalizer, spreading consecutive integers over
+// all workers.
+func mixHash(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// RunSlice runs items through the pool and returns the successful results
+// in completion order. Like Pipeline.RunSlice, it leaves no goroutines
+// running once it returns, which makes it convenient inside testing/synctest
+// bubbles.
+func (p *Pool[T, R]) RunSlice(ctx context.Context, items []T) []R {
+	return Collect(ctx, p.Run(ctx, emitSlice(ctx, items)), 0)
+}
+
+// Submit runs j on the pool and returns a future for its result, without
+// the caller managing job and result channels. At most Workers submitted
+// jobs run at once, each seeing a WorkerID; the rest wait for a free
+// worker, or fail with ctx's error if ctx is cancelled first. Submitted
+// jobs do not share workers with Run. Panics are recovered as *PanicError.
+// After Shutdown, the future fails with ErrPoolClosed.
+func (p *Pool[T, R]) Submit(ctx context.Context, j T) *Future[R] {
+	var zero R
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return Resolved(ctx, zero, ErrPoolClosed)
+	}
+	p.submits.Add(1)
+	p.mu.Unlock()
+	return Async(ctx, nil, func(ctx context.Context) (R, error) {
+		defer p.submits.Done()
+		p.waiting.Add(1)
+		err := p.submitted.Acquire(ctx)
+		queued := int(p.waiting.Add(-1))
+		if err != nil {
+			return zero, err
+		}
+		defer p.submitted.Release()
+		id := p.takeSubmitID()
+		defer p.releaseSubmitID(id)
+		ctx = context.WithValue(ctx, workerIDKey{}, id)
+		r, copies, err := p.runJob(ctx, j, queued)
+		if err == nil && copies == 0 {
+			return zero, ErrDuplicateJob
+		}
+		return r, err
+	})
+}
+
+// takeSubmitID returns the lowest worker ID not held by a submitted job.
+func (p *Pool[T, R]) takeSubmitID() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for id, held := range p.submitIDs {
+		if !held {
+			p.submitIDs[id] = true
+			return id
+		}
+	}
+	p.submitIDs = append(p.submitIDs, true)
+	return len(p.submitIDs) - 1
+}
+
+func (p *Pool[T, R]) releaseSubmitID(id int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.submitIDs[id] = false
+}
+
+// runJob processes j and returns its result and how many copies of it to
+// emit: one normally, more when WithDedup coalesced other jobs into this one,
+// and none when j itself was coalesced into a job still running or was
+// already processed according to WithIdempotency.
+func (p *Pool[T, R]) runJob(ctx context.Context, j T, queued int) (R, int, error) {
+	if idem := p.options.Idempotency; idem != nil {
+		var zero R
+		key := idem.Key(j)
+		fresh, err := idem.Store.CheckAndSet(ctx, key, idem.TTL)
+		if err != nil || !fresh {
+			return zero, 0, err
+		}
+		r, copies, err := p.coalesce(ctx, j, queued)
+		if err != nil {
+			_ = idem.Store.Delete(context.WithoutCancel(ctx), key)
+		}
+		return r, copies, err
+	}
+	return p.coalesce(ctx, j, queued)
+}
+
+// coalesce processes j, applying WithDedup if it is set.
+func (p *Pool[T, R]) coalesce(ctx context.Context, j T, queued int) (R, int, error) {
+	if p.dedup == nil {
+		r, err := p.process(ctx, j, queued)
+		return r, 1, err
+	}
+	key := p.options.DedupKey(j)
+	call, leader := p.dedup.join(key, time.Now())
+	if !leader {
+		if call == nil {
+			var zero R
+			return zero, 0, nil
+		}
+		return call.result, 1, nil
+	}
+	r, err := p.process(ctx, j, queued)
+	return r, p.dedup.finish(key, call, r, err, time.Now()), err
+}
+
+// dedupGroup tracks the jobs a Pool is running or has recently finished, by
+// WithDedup key.
+type dedupGroup[R any] struct {
+	ttl       time.Duration
+	mu        sync.Mutex
+	calls     map[any]*dedupCall[R]
+	lastSweep time.Time
+}
+
+type dedupCall[R any] struct {
+	waiters  int
+	done     bool
+	finished time.Time
+	result   R
+}
+
+// join registers a job with key. The leader gets a new call to run; other
+// jobs get nil while the leader is running, or its finished call if that is
+// still within the ttl.
+func (g *dedupGroup[R]) join(key any, now time.Time) (*dedupCall[R], bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.ttl > 0 && now.Sub(g.lastSweep) >= g.ttl {
+		for k, c := range g.calls {
+			if c.done && now.Sub(c.finished) >= g.ttl {
+				delete(g.calls, k)
+			}
+		}
+		g.lastSweep = now
+	}
+	if c, ok := g.calls[key]; ok {
+		if !c.done {
+			c.waiters++
+			return nil, false
+		}
+		if now.Sub(c.finished) < g.ttl {
+			return c, false
+		}
+	}
+	c := &dedupCall[R]{}
+	g.calls[key] = c
+	return c, true
+}
+
+// finish records the leader's outcome and returns the number of results to
+// emit for it and its waiters.
+func (g *dedupGroup[R]) finish(key any, c *dedupCall[R], r R, err error, now time.Time) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if err != nil || g.ttl <= 0 {
+		delete(g.calls, key)
+	} else {
+		c.done = true
+		c.finished = now
+		c.result = r
+	}
+	return c.waiters + 1
+}
+
+// process runs fn for a single job, reporting it to the pool's observer,
+// metrics and tracer.
+func (p *Pool[T, R]) process(ctx context.Context, j T, queued int) (R, error) {
+	p.obs.emit(Event{Kind: EventQueueDepth, Depth: queued})
+	p.obs.emit(Event{Kind: EventStarted})
+	p.active.Add(1)
+	start := time.Now()
+	r, err := p.call(ctx, j)
+	latency := time.Since(start)
+	p.active.Add(-1)
+	if err != nil {
+		p.failed.Add(1)
+	} else {
+		p.processed.Add(1)
+	}
+	p.obs.emit(Event{Kind: EventCompleted, Latency: latency, Err: err})
+	if m := p.options.Metrics; m != nil {
+		m.RecordLatency(latency)
+		if err != nil {
+			m.RecordError()
+		} else {
+			m.RecordSuccess()
+		}
+	}
+	return r, err
+}
+
+func (p *Pool[T, R]) call(ctx context.Context, j T) (R, error) {
+	if a := p.options.AutoTune; a != nil {
+		release, err := a.Acquire(ctx)
+		if err != nil {
+			var zero R
+			return zero, err
+		}
+		defer release()
+	}
+	if q := p.options.Quota; q != nil {
+		release, err := q.Acquire(ctx, p.options.QuotaNames...)
+		if err != nil {
+			var zero R
+			return zero, err
+		}
+		defer release()
+	}
+	if p.options.Tracer == nil {
+		return p.invoke(ctx, j)
+	}
+	name := p.options.Name + ".job"
+	if p.options.Name == "" {
+		name = "pool.job"
+	}
+	if p.options.SpanName != nil {
+		name = p.options.SpanName(j)
+	}
+	var attrs SpanAttributes
+	if p.options.SpanAttrs != nil {
+		attrs = p.options.SpanAttrs(j)
+	}
+	ctx, span := p.options.Tracer.Start(ctx, name, attrs)
+	r, err := p.invoke(ctx, j)
+	span.End(err)
+	return r, err
+}
+
+// invoke calls fn for j, retrying failed attempts as set by
+// WithRetryConfig.
+func (p *Pool[T, R]) invoke(ctx context.Context, j T) (R, error) {
+	if p.retry.MaxRetries == 0 {
+		return p.attempt(ctx, j)
+	}
+	var r R
+	err := Retry(ctx, j, func(ctx context.Context, j T) (err error) {
+		r, err = p.attempt(ctx, j)
+		return err
+	}, p.retry)
+	if err != nil {
+		var zero R
+		return zero, err
+	}
+	return r, nil
+}
+
+// attempt calls fn once for j, once the pool's rate limit allows it and
+// bounded by its timeout. A panic is recovered as a *PanicError if
+// WithPanicRecovery is set.
+func (p *Pool[T, R]) attempt(ctx context.Context, j T) (r R, err error) {
+	if p.limiter != nil {
+		if err := p.throttle(ctx); err != nil {
+			return r, err
+		}
+	}
+	if timeout := p.options.Timeout; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if !p.options.Recover {
+		return p.fn(ctx, j)
+	}
+	defer func() {
+		if v := recover(); v != nil {
+			var zero R
+			r, err = zero, &PanicError{Value: v, Stack: debug.Stack()}
+			logEvent(ctx, p.options.Logger, slog.LevelError, "pool job panicked", "pool", p.options.Name, "panic", v)
+			if h := p.options.OnPanic; h != nil {
+				h(v, j)
+			}
+		}
 	}()
+	return p.fn(ctx, j)
+}
+
+// throttle waits until the pool's rate limiter allows another call,
+// refilling it as time passes.
+func (p *Pool[T, R]) throttle(ctx context.Context) error {
+	for {
+		p.limiter.Refill()
+		if p.limiter.Allow() {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(max(p.limiter.RetryAfter(), time.Millisecond)):
+		}
+	}
+}
+
+type workerIDKey struct{}
+
+// WorkerID returns the index of the pool worker running the job that ctx
+// was passed to, and false if ctx did not come from a pool worker.
+func WorkerID(ctx context.Context) (int, bool) {
+	id, ok := ctx.Value(workerIDKey{}).(int)
+	return id, ok
+}
+
+// PoolR is a Pool that reports failures as values instead of dropping them.
+// Every job produces exactly one Result on the output channel.
+type PoolR[T any, R any] struct {
+	pool *Pool[T, Result[R]]
+}
+
+// NewPoolR creates a PoolR with n workers and a processing function.
+func NewPoolR[T any, R any](n int, fn func(context.Context, T) (R, error), opts ...PoolOption) *PoolR[T, R] {
+	return &PoolR[T, R]{pool: NewPool[T, Result[R]](n, WrapResults(fn), opts...)}
+}
+
+// Run executes jobs until ctx is canceled or jobs is closed.
+// The caller MUST consume the results channel until it is closed.
+func (p *PoolR[T, R]) Run(ctx context.Context, jobs <-chan T) <-chan Result[R] {
+	return p.pool.Run(ctx, jobs)
+}
+
+// Job is the outcome of one item processed by a JobPool.
+type Job[T any, R any] struct {
+	Input    T
+	Output   R
+	Err      error
+	WorkerID int
+	Enqueued time.Time // when the pool took the item from its input channel
+	Started  time.Time // when a worker began processing it
+	Finished time.Time
+}
 
-	return results
+// QueueTime returns how long the job waited for a worker.
+func (j Job[T, R]) QueueTime() time.Duration {
+	return j.Started.Sub(j.Enqueued)
+}
+
+// ProcessingTime returns how long the worker spent on the job.
+func (j Job[T, R]) ProcessingTime() time.Duration {
+	return j.Finished.Sub(j.Started)
+}
+
+// queuedJob is an item with the time a JobPool accepted it.
+type queuedJob[T any] struct {
+	item     T
+	enqueued time.Time
+}
+
+// JobPool is a Pool whose results carry their input, error, worker and
+// timings, so consumers can correlate outputs with inputs.
+// Every job produces exactly one Job on the output channel.
+type JobPool[T any, R any] struct {
+	pool *Pool[queuedJob[T], Job[T, R]]
+}
+
+// NewJobPool creates a JobPool with n workers and a processing function.
+// Failed jobs count as failures for retries, metrics and events, and are
+// still delivered as a Job with Err set; ErrorStop also stops the run.
+// Option callbacks that take an item receive the caller's T.
+func NewJobPool[T any, R any](n int, fn func(context.Context, T) (R, error), opts ...PoolOption) *JobPool[T, R] {
+	opts = append(slices.Clip(opts), unwrapJobItems[T])
+	pool := NewPool(n, func(ctx context.Context, q queuedJob[T]) (Job[T, R], error) {
+		job := Job[T, R]{Input: q.item, Enqueued: q.enqueued, Started: time.Now()}
+		job.WorkerID, _ = WorkerID(ctx)
+		job.Output, job.Err = fn(ctx, q.item)
+		job.Finished = time.Now()
+		return job, job.Err
+	}, opts...)
+	pool.keepFailed = func(q queuedJob[T], job Job[T, R], err error) Job[T, R] {
+		if job.Started.IsZero() {
+			// fn never ran, or its result was lost to a retry or panic.
+			now := time.Now()
+			job = Job[T, R]{Input: q.item, Enqueued: q.enqueued, Started: now, Finished: now}
+		}
+		job.Err = err
+		return job
+	}
+	return &JobPool[T, R]{pool: pool}
+}
+
+// unwrapJobItems makes the item callbacks of a JobPool's options see the
+// caller's items rather than the queuedJob wrapping them.
+func unwrapJobItems[T any](opts *PoolOptions) {
+	item := func(v any) any {
+		if q, ok := v.(queuedJob[T]); ok {
+			return q.item
+		}
+		return v
+	}
+	if f := opts.SpanName; f != nil {
+		opts.SpanName = func(v any) string { return f(item(v)) }
+	}
+	if f := opts.SpanAttrs; f != nil {
+		opts.SpanAttrs = func(v any) SpanAttributes { return f(item(v)) }
+	}
+	if f := opts.DedupKey; f != nil {
+		opts.DedupKey = func(v any) any { return f(item(v)) }
+	}
+	if f := opts.Affinity; f != nil {
+		opts.Affinity = func(v any) any { return f(item(v)) }
+	}
+	if f := opts.OnPanic; f != nil {
+		opts.OnPanic = func(recovered any, v any) { f(recovered, item(v)) }
+	}
+	if idem := opts.Idempotency; idem != nil && idem.Key != nil {
+		unwrapped := *idem
+		unwrapped.Key = func(v any) string { return idem.Key(item(v)) }
+		opts.Idempotency = &unwrapped
+	}
+}
+
+// Run executes jobs until ctx is canceled or jobs is closed. Up to one item
+// per worker is accepted ahead of the workers, so QueueTime reflects time
+// spent waiting for a free worker.
+// The caller MUST consume the results channel until it is closed.
+func (p *JobPool[T, R]) Run(ctx context.Context, jobs <-chan T) <-chan Job[T, R] {
+	queued := make(chan queuedJob[T], p.pool.Workers())
+	go func() {
+		defer close(queued)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case item, ok := <-jobs:
+				if !ok {
+					return
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case queued <- queuedJob[T]{item: item, enqueued: time.Now()}:
+				}
+			}
+		}
+	}()
+	return p.pool.Run(ctx, queued)
 }
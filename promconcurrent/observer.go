@@ -0,0 +1,123 @@
+// Package promconcurrent exposes instrumentation events from the concurrent
+// package as Prometheus metrics.
+//
+// Create an Observer, register it with a Prometheus registry and pass it to
+// the components you want to monitor:
+//
+//	obs := promconcurrent.NewObserver("myapp")
+//	prometheus.MustRegister(obs)
+//	pool := concurrent.NewPool(4, fn, concurrent.WithName("ingest"), concurrent.WithObserver(obs))
+//
+// It is a separate module, so that the concurrent package itself has no
+// dependencies:
+//
+//	go get github.com/logimos/concurrent/promconcurrent
+package promconcurrent
+
+import (
+	"github.com/logimos/concurrent"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Observer is a concurrent.Observer that records events as Prometheus metrics,
+// labelled by component name. It implements prometheus.Collector.
+type Observer struct {
+	inFlight     *prometheus.GaugeVec
+	queueDepth   *prometheus.GaugeVec
+	processed    *prometheus.CounterVec
+	errors       *prometheus.CounterVec
+	throttled    *prometheus.CounterVec
+	rejected     *prometheus.CounterVec
+	latency      *prometheus.HistogramVec
+	breakerState *prometheus.GaugeVec
+}
+
+var _ concurrent.Observer = (*Observer)(nil)
+var _ prometheus.Collector = (*Observer)(nil)
+
+// NewObserver creates an observer whose metrics are prefixed with namespace.
+func NewObserver(namespace string) *Observer {
+	labels := []string{"component"}
+	return &Observer{
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace, Subsystem: "concurrent", Name: "in_flight",
+			Help: "Number of items currently being processed.",
+		}, labels),
+		queueDepth: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace, Subsystem: "concurrent", Name: "queue_depth",
+			Help: "Number of items waiting to be processed.",
+		}, labels),
+		processed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "concurrent", Name: "processed_total",
+			Help: "Number of items processed successfully or emitted by a stage.",
+		}, labels),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "concurrent", Name: "errors_total",
+			Help: "Number of items that failed processing.",
+		}, labels),
+		throttled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "concurrent", Name: "throttled_total",
+			Help: "Number of operations delayed or denied by a rate limiter.",
+		}, labels),
+		rejected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "concurrent", Name: "rejected_total",
+			Help: "Number of calls rejected by an open circuit breaker.",
+		}, labels),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Subsystem: "concurrent", Name: "latency_seconds",
+			Help:    "Time spent processing a single item.",
+			Buckets: prometheus.DefBuckets,
+		}, labels),
+		breakerState: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace, Subsystem: "concurrent", Name: "breaker_state",
+			Help: "Circuit breaker state: 0 closed, 1 open, 2 half-open.",
+		}, labels),
+	}
+}
+
+// Observe implements concurrent.Observer.
+func (o *Observer) Observe(e concurrent.Event) {
+	switch e.Kind {
+	case concurrent.EventStarted:
+		o.inFlight.WithLabelValues(e.Component).Inc()
+	case concurrent.EventCompleted:
+		o.inFlight.WithLabelValues(e.Component).Dec()
+		o.latency.WithLabelValues(e.Component).Observe(e.Latency.Seconds())
+		if e.Err != nil {
+			o.errors.WithLabelValues(e.Component).Inc()
+		} else {
+			o.processed.WithLabelValues(e.Component).Inc()
+		}
+	case concurrent.EventEmitted:
+		o.processed.WithLabelValues(e.Component).Inc()
+	case concurrent.EventThrottled:
+		o.throttled.WithLabelValues(e.Component).Inc()
+	case concurrent.EventRejected:
+		o.rejected.WithLabelValues(e.Component).Inc()
+	case concurrent.EventStateChanged:
+		o.breakerState.WithLabelValues(e.Component).Set(float64(e.State))
+	case concurrent.EventQueueDepth:
+		o.queueDepth.WithLabelValues(e.Component).Set(float64(e.Depth))
+	}
+}
+
+func (o *Observer) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		o.inFlight, o.queueDepth, o.processed, o.errors,
+		o.throttled, o.rejected, o.latency, o.breakerState,
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (o *Observer) Describe(ch chan<- *prometheus.Desc) {
+	for _, c := range o.collectors() {
+		c.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (o *Observer) Collect(ch chan<- prometheus.Metric) {
+	for _, c := range o.collectors() {
+		c.Collect(ch)
+	}
+}
@@ -0,0 +1,64 @@
+package promconcurrent
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/logimos/concurrent"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestObserver(t *testing.T) {
+	obs := NewObserver("test")
+	reg := prometheus.NewRegistry()
+	if err := reg.Register(obs); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	pool := concurrent.NewPool[int, int](2, func(_ context.Context, v int) (int, error) {
+		if v%2 == 0 {
+			return 0, errors.New("even")
+		}
+		return v, nil
+	}, concurrent.WithName("pool"), concurrent.WithObserver(obs))
+
+	jobs := make(chan int, 4)
+	for i := 0; i < 4; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	for range pool.Run(context.Background(), jobs) {
+	}
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	values := make(map[string]float64)
+	for _, f := range families {
+		for _, m := range f.GetMetric() {
+			switch {
+			case m.GetCounter() != nil:
+				values[f.GetName()] = m.GetCounter().GetValue()
+			case m.GetGauge() != nil:
+				values[f.GetName()] = m.GetGauge().GetValue()
+			case m.GetHistogram() != nil:
+				values[f.GetName()] = float64(m.GetHistogram().GetSampleCount())
+			}
+		}
+	}
+
+	expected := map[string]float64{
+		"test_concurrent_processed_total": 2,
+		"test_concurrent_errors_total":    2,
+		"test_concurrent_latency_seconds": 4,
+		"test_concurrent_in_flight":       0,
+	}
+	for name, want := range expected {
+		if got, ok := values[name]; !ok || got != want {
+			t.Errorf("Expected %s = %v, got %v (present: %v)", name, want, got, ok)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ErrUnknownQuota is returned when acquiring from a budget that was never
+// defined.
+var ErrUnknownQuota = errors.New("concurrent: unknown quota")
+
+// QuotaManager holds named budgets shared by independent components, such
+// as "db-connections" limited to 50 concurrent holders or "external-api"
+// limited to 100 calls a second, so global ceilings hold however many
+// pools, limiters and pipelines draw on them.
+type QuotaManager struct {
+	mu     sync.Mutex
+	quotas map[string]*quota
+}
+
+// quota is a named budget: a concurrency limit, a rate limit, or both.
+type quota struct {
+	sem     *Semaphore
+	limiter *RateLimiter
+}
+
+// NewQuotaManager creates a manager with no budgets.
+func NewQuotaManager() *QuotaManager {
+	return &QuotaManager{quotas: make(map[string]*quota)}
+}
+
+// SetConcurrency limits the named budget to n concurrent holders, resizing
+// it if it already has a concurrency limit.
+func (q *QuotaManager) SetConcurrency(name string, n int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	qt := q.quotaLocked(name)
+	if qt.sem == nil {
+		qt.sem = NewSemaphore(n)
+		return
+	}
+	qt.sem.SetCapacity(n)
+}
+
+// SetRate limits the named budget to limit acquisitions per interval,
+// replacing any previous rate limit.
+func (q *QuotaManager) SetRate(name string, limit int, interval time.Duration, opts ...LimiterOption) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.quotaLocked(name).limiter = NewRateLimiter(limit, interval, opts...)
+}
+
+func (q *QuotaManager) quotaLocked(name string) *quota {
+	qt, ok := q.quotas[name]
+	if !ok {
+		qt = &quota{}
+		q.quotas[name] = qt
+	}
+	return qt
+}
+
+// Limiter returns the named budget's rate limiter, or nil if it has none.
+// Components with limiters of their own can pass it to WithLimiterParent.
+func (q *QuotaManager) Limiter(name string) *RateLimiter {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if qt, ok := q.quotas[name]; ok {
+		return qt.limiter
+	}
+	return nil
+}
+
+// Acquire blocks until every named budget admits one more holder, or ctx
+// is cancelled. Rate limits are consumed as they are passed, and
+// concurrency slots are held until release is called. Budgets are taken in
+// name order, so components acquiring overlapping sets cannot deadlock.
+func (q *QuotaManager) Acquire(ctx context.Context, names ...string) (release func(), err error) {
+	names = slices.Clone(names)
+	slices.Sort(names)
+	names = slices.Compact(names)
+
+	q.mu.Lock()
+	quotas := make([]*quota, len(names))
+	for i, name := range names {
+		qt, ok := q.quotas[name]
+		if !ok {
+			q.mu.Unlock()
+			return nil, fmt.Errorf("%w: %q", ErrUnknownQuota, name)
+		}
+		quotas[i] = &quota{sem: qt.sem, limiter: qt.limiter}
+	}
+	q.mu.Unlock()
+
+	var held []*Semaphore
+	releaseAll := func() {
+		for _, sem := range held {
+			sem.Release()
+		}
+	}
+	for _, qt := range quotas {
+		if qt.limiter != nil {
+			if err := qt.limiter.Wait(ctx); err != nil {
+				releaseAll()
+				return nil, err
+			}
+		}
+		if qt.sem != nil {
+			if err := qt.sem.Acquire(ctx); err != nil {
+				releaseAll()
+				return nil, err
+			}
+			held = append(held, qt.sem)
+		}
+	}
+	var once sync.Once
+	return func() { once.Do(releaseAll) }, nil
+}
+
+// Report implements Reporter, with the usage of every budget.
+func (q *QuotaManager) Report() Report {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	stats := make(map[string]any, len(q.quotas))
+	for name, qt := range q.quotas {
+		s := map[string]any{}
+		if qt.sem != nil {
+			s["capacity"] = qt.sem.Capacity()
+			s["in_use"] = qt.sem.InUse()
+		}
+		if qt.limiter != nil {
+			s["tokens"] = qt.limiter.Tokens()
+		}
+		stats[name] = s
+	}
+	return Report{Kind: "quota_manager", Stats: stats}
+}
+
+// WithQuotaFunc wraps fn so every call first acquires the named budgets
+// from q and holds them until fn returns, for use in pipeline stages such
+// as MapConcurrent.
+func WithQuotaFunc[T any, R any](q *QuotaManager, fn func(context.Context, T) (R, error), names ...string) func(context.Context, T) (R, error) {
+	return func(ctx context.Context, item T) (R, error) {
+		release, err := q.Acquire(ctx, names...)
+		if err != nil {
+			var zero R
+			return zero, err
+		}
+		defer release()
+		return fn(ctx, item)
+	}
+}
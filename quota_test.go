@@ -0,0 +1,89 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestQuotaManager(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("concurrency shared across pools", func(t *testing.T) {
+		q := NewQuotaManager()
+		q.SetConcurrency("db", 2)
+		var active, peak atomic.Int32
+		fn := func(_ context.Context, v int) (int, error) {
+			n := active.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			active.Add(-1)
+			return v, nil
+		}
+		a := NewPool(4, fn, WithQuota(q, "db"))
+		b := NewPool(4, fn, WithQuota(q, "db"))
+		done := make(chan []int)
+		go func() { done <- a.RunSlice(ctx, make([]int, 20)) }()
+		results := b.RunSlice(ctx, make([]int, 20))
+		results = append(results, <-done...)
+		if len(results) != 40 {
+			t.Errorf("Expected 40 results, got %d", len(results))
+		}
+		if p := peak.Load(); p > 2 {
+			t.Errorf("Expected at most 2 concurrent jobs, got %d", p)
+		}
+	})
+
+	t.Run("rate", func(t *testing.T) {
+		q := NewQuotaManager()
+		clock := NewFakeClock(time.Now())
+		q.SetRate("api", 2, time.Second, WithLimiterClock(clock))
+		for range 2 {
+			release, err := q.Acquire(ctx, "api")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			release()
+		}
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		if _, err := q.Acquire(ctx, "api"); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected the budget to be exhausted, got %v", err)
+		}
+	})
+
+	t.Run("all or nothing", func(t *testing.T) {
+		q := NewQuotaManager()
+		q.SetConcurrency("a", 1)
+		q.SetConcurrency("b", 1)
+		holdB, _ := q.Acquire(ctx, "b")
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		if _, err := q.Acquire(ctx, "a", "b"); err == nil {
+			t.Fatal("Expected b to be unavailable")
+		}
+		holdB()
+		release, err := q.Acquire(context.Background(), "a")
+		if err != nil {
+			t.Fatalf("Expected a to have been released, got %v", err)
+		}
+		release()
+		release()
+		if n := q.Report().Stats["a"].(map[string]any)["in_use"]; n != 0 {
+			t.Errorf("Expected nothing in use after a double release, got %v", n)
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		if _, err := NewQuotaManager().Acquire(ctx, "missing"); !errors.Is(err, ErrUnknownQuota) {
+			t.Errorf("Expected ErrUnknownQuota, got %v", err)
+		}
+	})
+}
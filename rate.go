@@ -13,11 +13,14 @@ type RateLimiter struct {
 	tokens     chan struct{}
 	mu         sync.Mutex
 	lastRefill time.Time
+	obs        observer
+	clock      Clock
+	parent     *RateLimiter
 }
 
 // NewRateLimiter creates a new rate limiter with the specified limit and interval.
 // For example, NewRateLimiter(100, time.Second) allows 100 operations per second.
-func NewRateLimiter(limit int, interval time.Duration) *RateLimiter {
+func NewRateLimiter(limit int, interval time.Duration, opts ...LimiterOption) *RateLimiter {
 	if limit <= 0 {
 		limit = 1
 	}
@@ -25,12 +28,17 @@ func NewRateLimiter(limit int, interval time.Duration) *RateLimiter {
 		interval = time.Second
 	}
 
+	options := limiterOptions(opts)
+	clock := clockOrReal(options.Clock)
 	rl := &RateLimiter{
 		limit:      limit,
 		interval:   interval,
 		tokens:     make(chan struct{}, limit),
-		lastRefill: time.Now(),
+		lastRefill: clock.Now(),
+		clock:      clock,
+		parent:     options.Parent,
 	}
+	rl.obs = registerLimiter(rl, options)
 
 	// Fill the token bucket initially
 	for i := 0; i < limit; i++ {
@@ -40,13 +48,26 @@ func NewRateLimiter(limit int, interval time.Duration) *RateLimiter {
 	return rl
 }
 
+// Report implements Reporter.
+func (rl *RateLimiter) Report() Report {
+	return Report{Kind: "rate_limiter", Stats: map[string]any{
+		"limit":    rl.limit,
+		"interval": rl.interval.String(),
+		"tokens":   rl.Tokens(),
+	}}
+}
+
 // Allow checks if an operation is allowed under the current rate limit.
 // It returns true if the operation is allowed, false otherwise.
 func (rl *RateLimiter) Allow() bool {
+	if rl.parent != nil {
+		return rl.AllowN(1)
+	}
 	select {
 	case <-rl.tokens:
 		return true
 	default:
+		rl.obs.emit(Event{Kind: EventThrottled})
 		return false
 	}
 }
@@ -55,10 +76,24 @@ func (rl *RateLimiter) Allow() bool {
 // Note: Refill() must be called periodically (e.g., via a background goroutine)
 // for tokens to be replenished. Use RateLimit() function for automatic refill.
 // This method does not automatically refill tokens - use RateLimit() for that.
+//
+// With a parent limiter, Wait refills both as time passes and returns once
+// a token was taken from each.
 func (rl *RateLimiter) Wait(ctx context.Context) error {
+	if rl.parent != nil {
+		return rl.WaitN(ctx, 1)
+	}
+
 	// Try refill once before waiting
 	rl.Refill()
 
+	select {
+	case <-rl.tokens:
+		return nil
+	default:
+		rl.obs.emit(Event{Kind: EventThrottled})
+	}
+
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
@@ -67,12 +102,161 @@ func (rl *RateLimiter) Wait(ctx context.Context) error {
 	}
 }
 
+// AllowN is like Allow for an operation costing n tokens. It takes all n
+// tokens or none. Costs above the limit are capped at the limit, since the
+// bucket never holds more.
+func (rl *RateLimiter) AllowN(n int) bool {
+	if by := rl.take(n); by != nil {
+		by.obs.emit(Event{Kind: EventThrottled})
+		return false
+	}
+	return true
+}
+
+// take takes n tokens, capped at the limit, from rl and its parents. If any
+// of them is short it takes none and returns the limiter that refused.
+// The whole chain is checked before any limiter is charged, so a refusal
+// normally costs the others nothing.
+func (rl *RateLimiter) take(n int) *RateLimiter {
+	for l, need := rl, n; l != nil; l = l.parent {
+		if l != rl {
+			l.Refill()
+		}
+		need = min(need, l.limit)
+		if l.Tokens() < need {
+			return l
+		}
+	}
+	return rl.charge(n)
+}
+
+// charge takes n tokens, capped at the limit, from rl and its parents. If
+// a concurrent caller left one of them short, each limiter gets back
+// exactly the tokens taken from it and the one that refused is returned.
+func (rl *RateLimiter) charge(n int) *RateLimiter {
+	n = min(n, rl.limit)
+	for taken := 0; taken < n; taken++ {
+		select {
+		case <-rl.tokens:
+		default:
+			rl.putBack(taken)
+			return rl
+		}
+	}
+	if rl.parent != nil {
+		if by := rl.parent.charge(n); by != nil {
+			rl.putBack(n)
+			return by
+		}
+	}
+	return nil
+}
+
+// nextToken returns how long until the first limiter in the chain that has
+// run dry is refilled.
+func (rl *RateLimiter) nextToken() time.Duration {
+	if rl.parent == nil || rl.Tokens() == 0 {
+		return rl.RetryAfter()
+	}
+	return rl.parent.nextToken()
+}
+
+// WaitN is like Wait for an operation costing n tokens, capped at the limit.
+// Tokens are taken as they become available; if ctx is done first, the
+// tokens already taken are returned to the bucket.
+func (rl *RateLimiter) WaitN(ctx context.Context, n int) error {
+	if rl.parent != nil {
+		return rl.waitChain(ctx, n)
+	}
+	n = min(n, rl.limit)
+	rl.Refill()
+	throttled := false
+	for taken := 0; taken < n; taken++ {
+		select {
+		case <-rl.tokens:
+			continue
+		default:
+		}
+		if !throttled {
+			rl.obs.emit(Event{Kind: EventThrottled})
+			throttled = true
+		}
+		select {
+		case <-ctx.Done():
+			rl.putBack(taken)
+			return ctx.Err()
+		case <-rl.tokens:
+		}
+	}
+	return nil
+}
+
+// waitChain is WaitN for a limiter with a parent. Holding tokens from one
+// limiter while blocking on another would starve its other users, so it
+// sleeps until the refill that lets every limiter in the chain pay at once
+// and takes them all then.
+func (rl *RateLimiter) waitChain(ctx context.Context, n int) error {
+	throttled := false
+	for {
+		rl.Refill()
+		by := rl.take(n)
+		if by == nil {
+			return nil
+		}
+		if !throttled {
+			by.obs.emit(Event{Kind: EventThrottled})
+			throttled = true
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-rl.clock.After(rl.chainWait(n)):
+		}
+	}
+}
+
+// chainWait returns how long until every limiter in the chain that is short
+// of n tokens has been refilled.
+func (rl *RateLimiter) chainWait(n int) time.Duration {
+	var wait time.Duration
+	for l, need := rl, n; l != nil; l = l.parent {
+		need = min(need, l.limit)
+		if l.Tokens() < need {
+			wait = max(wait, l.RetryAfter())
+		}
+	}
+	return wait
+}
+
+// putBack returns n tokens to the bucket, dropping any that don't fit.
+func (rl *RateLimiter) putBack(n int) {
+	for i := 0; i < n; i++ {
+		select {
+		case rl.tokens <- struct{}{}:
+		default:
+			return
+		}
+	}
+}
+
+// Tokens returns the number of tokens currently available.
+func (rl *RateLimiter) Tokens() int {
+	return len(rl.tokens)
+}
+
+// RetryAfter returns how long until the next refill is due.
+func (rl *RateLimiter) RetryAfter() time.Duration {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return max(rl.interval-rl.clock.Now().Sub(rl.lastRefill), 0)
+}
+
 // Refill refills the token bucket based on the elapsed time.
 func (rl *RateLimiter) Refill() {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
-	now := time.Now()
+	now := rl.clock.Now()
 	elapsed := now.Sub(rl.lastRefill)
 
 	if elapsed >= rl.interval {
@@ -97,23 +281,34 @@ func (rl *RateLimiter) Refill() {
 }
 
 // RateLimit applies rate limiting to a channel of items.
-func RateLimit[T any](ctx context.Context, input <-chan T, limit int, interval time.Duration) <-chan T {
+func RateLimit[T any](ctx context.Context, input <-chan T, limit int, interval time.Duration, opts ...LimiterOption) <-chan T {
+	return RateLimitCost(ctx, input, limit, interval, nil, opts...)
+}
+
+// RateLimitCost is like RateLimit but each item consumes cost(item) tokens,
+// for limits expressed in bytes or other weighted units. A nil cost counts
+// every item as one token. Costs above the limit are capped at the limit.
+func RateLimitCost[T any](ctx context.Context, input <-chan T, limit int, interval time.Duration, cost func(T) int, opts ...LimiterOption) <-chan T {
 	output := make(chan T)
-	limiter := NewRateLimiter(limit, interval)
+	limiter := NewRateLimiter(limit, interval, opts...)
 
 	go func() {
 		defer close(output)
 
-		// Start refill goroutine
+		// Start refill goroutine; it stops with this one.
+		done := make(chan struct{})
+		defer close(done)
 		go func() {
-			ticker := time.NewTicker(interval / 10) // Check 10 times per interval
+			ticker := limiter.clock.NewTicker(limiter.interval / 10) // Check 10 times per interval
 			defer ticker.Stop()
 
 			for {
 				select {
 				case <-ctx.Done():
 					return
-				case <-ticker.C:
+				case <-done:
+					return
+				case <-ticker.C():
 					limiter.Refill()
 				}
 			}
@@ -129,7 +324,11 @@ func RateLimit[T any](ctx context.Context, input <-chan T, limit int, interval t
 				}
 
 				// Wait for rate limit
-				if err := limiter.Wait(ctx); err != nil {
+				n := 1
+				if cost != nil {
+					n = cost(item)
+				}
+				if err := limiter.WaitN(ctx, n); err != nil {
 					return
 				}
 
@@ -145,18 +344,32 @@ func RateLimit[T any](ctx context.Context, input <-chan T, limit int, interval t
 	return output
 }
 
+// RateLimitStage creates a stage that rate limits items like RateLimitCost.
+func RateLimitStage[T any](limit int, interval time.Duration, cost func(T) int, opts ...LimiterOption) Stage[T, T] {
+	return func(ctx context.Context, input <-chan T) <-chan T {
+		return RateLimitCost(ctx, input, limit, interval, cost, opts...)
+	}
+}
+
 // BurstRateLimit allows bursts up to a maximum size while maintaining an average rate.
 type BurstRateLimit struct {
-	limit      int
-	interval   time.Duration
-	burst      int
-	tokens     chan struct{}
-	mu         sync.Mutex
-	lastRefill time.Time
+	limit        int
+	interval     time.Duration
+	burst        int
+	tokens       chan struct{}
+	mu           sync.Mutex
+	lastRefill   time.Time
+	obs          observer
+	clock        Clock
+	warmup       time.Duration
+	lastUsed     time.Time
+	warmingSince time.Time
 }
 
-// NewBurstRateLimit creates a rate limiter that allows bursts.
-func NewBurstRateLimit(limit int, interval time.Duration, burst int) *BurstRateLimit {
+// NewBurstRateLimit creates a rate limiter that allows bursts of up to burst
+// operations while refilling limit tokens per interval. A non-positive burst
+// defaults to limit. With WithBurstWarmup the limiter starts cold.
+func NewBurstRateLimit(limit int, interval time.Duration, burst int, opts ...LimiterOption) *BurstRateLimit {
 	if limit <= 0 {
 		limit = 1
 	}
@@ -166,38 +379,63 @@ func NewBurstRateLimit(limit int, interval time.Duration, burst int) *BurstRateL
 	if burst <= 0 {
 		burst = limit
 	}
-	if burst > limit*2 {
-		burst = limit * 2 // Cap burst at 2x the limit
-	}
 
+	options := limiterOptions(opts)
+	clock := clockOrReal(options.Clock)
+	now := clock.Now()
 	brl := &BurstRateLimit{
-		limit:      limit,
-		interval:   interval,
-		burst:      burst,
-		tokens:     make(chan struct{}, burst),
-		lastRefill: time.Now(),
+		limit:        limit,
+		interval:     interval,
+		burst:        burst,
+		tokens:       make(chan struct{}, burst),
+		lastRefill:   now,
+		clock:        clock,
+		warmup:       max(options.BurstWarmup, 0),
+		warmingSince: now,
 	}
+	brl.obs = registerLimiter(brl, options)
 
 	// Fill the token bucket initially
-	for i := 0; i < burst; i++ {
+	for i := 0; i < brl.ceiling(now); i++ {
 		brl.tokens <- struct{}{}
 	}
 
 	return brl
 }
 
+// Report implements Reporter.
+func (brl *BurstRateLimit) Report() Report {
+	return Report{Kind: "burst_rate_limiter", Stats: map[string]any{
+		"limit":    brl.limit,
+		"interval": brl.interval.String(),
+		"burst":    brl.burst,
+		"ceiling":  brl.Ceiling(),
+		"tokens":   brl.Tokens(),
+	}}
+}
+
 // Allow checks if an operation is allowed under the burst rate limit.
 func (brl *BurstRateLimit) Allow() bool {
+	brl.touch()
 	select {
 	case <-brl.tokens:
 		return true
 	default:
+		brl.obs.emit(Event{Kind: EventThrottled})
 		return false
 	}
 }
 
 // Wait blocks until an operation is allowed under the burst rate limit.
 func (brl *BurstRateLimit) Wait(ctx context.Context) error {
+	brl.touch()
+	select {
+	case <-brl.tokens:
+		return nil
+	default:
+		brl.obs.emit(Event{Kind: EventThrottled})
+	}
+
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
@@ -206,20 +444,70 @@ func (brl *BurstRateLimit) Wait(ctx context.Context) error {
 	}
 }
 
+// Tokens returns the number of tokens currently available.
+func (brl *BurstRateLimit) Tokens() int {
+	return len(brl.tokens)
+}
+
+// Ceiling returns the number of tokens the bucket may currently hold: the
+// full burst, or less while warming up.
+func (brl *BurstRateLimit) Ceiling() int {
+	brl.mu.Lock()
+	defer brl.mu.Unlock()
+	return brl.ceiling(brl.clock.Now())
+}
+
+// ceiling grows linearly from limit to burst over the warm-up period.
+// Callers must hold mu, except during construction.
+func (brl *BurstRateLimit) ceiling(now time.Time) int {
+	warm := now.Sub(brl.warmingSince)
+	if brl.warmup == 0 || warm >= brl.warmup {
+		return brl.burst
+	}
+	base := min(brl.limit, brl.burst)
+	return base + int(int64(brl.burst-base)*int64(warm)/int64(brl.warmup))
+}
+
+// touch records use of the limiter. After an idle warm-up period it restarts
+// the warm-up, discarding tokens above the cold ceiling.
+func (brl *BurstRateLimit) touch() {
+	if brl.warmup == 0 {
+		return
+	}
+	brl.mu.Lock()
+	defer brl.mu.Unlock()
+	now := brl.clock.Now()
+	if now.Sub(brl.lastUsed) >= brl.warmup {
+		brl.warmingSince = now
+		for n := len(brl.tokens) - brl.ceiling(now); n > 0; n-- {
+			select {
+			case <-brl.tokens:
+			default:
+			}
+		}
+	}
+	brl.lastUsed = now
+}
+
+// RetryAfter returns how long until the next refill is due.
+func (brl *BurstRateLimit) RetryAfter() time.Duration {
+	brl.mu.Lock()
+	defer brl.mu.Unlock()
+	return max(brl.interval-brl.clock.Now().Sub(brl.lastRefill), 0)
+}
+
 // Refill refills the token bucket for burst rate limiting.
 func (brl *BurstRateLimit) Refill() {
 	brl.mu.Lock()
 	defer brl.mu.Unlock()
 
-	now := time.Now()
+	now := brl.clock.Now()
 	elapsed := now.Sub(brl.lastRefill)
 
 	if elapsed >= brl.interval {
 		// Calculate how many tokens to add
 		tokensToAdd := int(elapsed/brl.interval) * brl.limit
-		if tokensToAdd > brl.burst {
-			tokensToAdd = brl.burst
-		}
+		tokensToAdd = min(tokensToAdd, brl.ceiling(now)-len(brl.tokens))
 
 		// Add tokens to the bucket
 		for i := 0; i < tokensToAdd; i++ {
@@ -234,3 +522,114 @@ func (brl *BurstRateLimit) Refill() {
 		brl.lastRefill = now
 	}
 }
+
+// KeyedRateLimiter maintains an independent RateLimiter per key, such as a
+// client IP or API key. Limiters idle for a full interval are discarded,
+// since a fresh limiter would behave identically.
+type KeyedRateLimiter struct {
+	limit     int
+	interval  time.Duration
+	opts      []LimiterOption
+	clock     Clock
+	mu        sync.Mutex
+	limiters  map[string]*keyedLimiter
+	lastSweep time.Time
+}
+
+type keyedLimiter struct {
+	rl       *RateLimiter
+	lastUsed time.Time
+}
+
+// NewKeyedRateLimiter creates a keyed limiter allowing limit operations per
+// interval for each key. opts apply to every per-key limiter; a registry
+// option is ignored for them. Pass WithLimiterParent to cap all keys
+// together as well, e.g. 10/sec per tenant but 200/sec overall.
+func NewKeyedRateLimiter(limit int, interval time.Duration, opts ...LimiterOption) *KeyedRateLimiter {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	clock := clockOrReal(limiterOptions(opts).Clock)
+	opts = append(opts[:len(opts):len(opts)], WithLimiterRegistry(nil))
+	return &KeyedRateLimiter{
+		limit:     limit,
+		interval:  interval,
+		opts:      opts,
+		clock:     clock,
+		limiters:  make(map[string]*keyedLimiter),
+		lastSweep: clock.Now(),
+	}
+}
+
+// limiter returns the limiter for key, creating it if needed.
+func (k *KeyedRateLimiter) limiter(key string) *RateLimiter {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	now := k.clock.Now()
+	if now.Sub(k.lastSweep) >= k.interval {
+		for idle, l := range k.limiters {
+			if now.Sub(l.lastUsed) >= k.interval {
+				delete(k.limiters, idle)
+			}
+		}
+		k.lastSweep = now
+	}
+	l, ok := k.limiters[key]
+	if !ok {
+		l = &keyedLimiter{rl: NewRateLimiter(k.limit, k.interval, k.opts...)}
+		k.limiters[key] = l
+	}
+	l.lastUsed = now
+	return l.rl
+}
+
+// Allow reports whether an operation for key is allowed now, refilling the
+// key's tokens as time passes.
+func (k *KeyedRateLimiter) Allow(key string) bool {
+	rl := k.limiter(key)
+	rl.Refill()
+	return rl.Allow()
+}
+
+// Wait blocks until an operation for key is allowed or ctx is cancelled.
+func (k *KeyedRateLimiter) Wait(ctx context.Context, key string) error {
+	for !k.Allow(key) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-k.clock.After(max(k.RetryAfter(key), time.Millisecond)):
+		}
+	}
+	return nil
+}
+
+// RetryAfter returns how long until key's tokens are next replenished. With
+// a parent limiter it reports the parent's refill once the key has tokens.
+func (k *KeyedRateLimiter) RetryAfter(key string) time.Duration {
+	return k.limiter(key).nextToken()
+}
+
+// Len returns the number of keys currently tracked.
+func (k *KeyedRateLimiter) Len() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return len(k.limiters)
+}
+
+// limiterOptions applies opts to a zero LimiterOptions.
+func limiterOptions(opts []LimiterOption) LimiterOptions {
+	var options LimiterOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// registerLimiter registers r if a registry is configured and returns the
+// limiter's observer.
+func registerLimiter(r Reporter, options LimiterOptions) observer {
+	if options.Registry != nil {
+		options.Registry.Register(options.Name, r)
+	}
+	return observer{name: options.Name, o: options.Observer}
+}
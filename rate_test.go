@@ -139,6 +139,45 @@ func TestRateLimit(t *testing.T) {
 	})
 }
 
+func TestRateLimitCost(t *testing.T) {
+	t.Run("AllowN takes all or nothing", func(t *testing.T) {
+		rl := NewRateLimiter(5, time.Hour)
+		if !rl.AllowN(3) {
+			t.Fatal("Expected 3 of 5 tokens to be allowed")
+		}
+		if rl.AllowN(3) {
+			t.Error("Expected 3 more tokens to be refused")
+		}
+		if rl.Tokens() != 2 {
+			t.Errorf("Expected refused tokens to be returned, got %d left", rl.Tokens())
+		}
+	})
+
+	t.Run("weighted items", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		clock := NewFakeClock(time.Unix(0, 0))
+		output := RateLimitStage(10, time.Second, func(s string) int { return len(s) }, WithLimiterClock(clock))(
+			ctx, emitSlice(ctx, []string{"aaaa", "bbbb", "cccc"}))
+
+		for _, want := range []string{"aaaa", "bbbb"} {
+			if got := <-output; got != want {
+				t.Fatalf("Expected %q, got %q", want, got)
+			}
+		}
+		select {
+		case got := <-output:
+			t.Fatalf("Expected the third item to wait for tokens, got %q", got)
+		case <-time.After(20 * time.Millisecond):
+		}
+		clock.BlockUntil(1)
+		clock.Advance(time.Second)
+		if got := <-output; got != "cccc" {
+			t.Errorf("Expected %q after refill, got %q", "cccc", got)
+		}
+	})
+}
+
 func TestBurstRateLimit(t *testing.T) {
 	t.Run("basic functionality", func(t *testing.T) {
 		brl := NewBurstRateLimit(2, 100*time.Millisecond, 4)
@@ -198,6 +237,46 @@ func TestBurstRateLimit(t *testing.T) {
 			t.Error("Expected operation to be allowed after refill")
 		}
 	})
+
+	t.Run("burst above twice the limit", func(t *testing.T) {
+		brl := NewBurstRateLimit(2, time.Second, 10)
+		if n := brl.Tokens(); n != 10 {
+			t.Errorf("Expected 10 tokens, got %d", n)
+		}
+	})
+
+	t.Run("warm-up", func(t *testing.T) {
+		clock := NewFakeClock(time.Now())
+		brl := NewBurstRateLimit(2, time.Second, 10, WithLimiterClock(clock), WithBurstWarmup(4*time.Second))
+
+		if n := brl.Tokens(); n != 2 {
+			t.Fatalf("Expected a cold start with 2 tokens, got %d", n)
+		}
+		brl.Allow()
+		brl.Allow()
+		if brl.Allow() {
+			t.Error("Expected a cold limiter to refuse a full burst")
+		}
+
+		clock.Advance(2 * time.Second)
+		if c := brl.Ceiling(); c != 6 {
+			t.Errorf("Expected ceiling 6 halfway through warm-up, got %d", c)
+		}
+		clock.Advance(3 * time.Second)
+		brl.Refill()
+		if c := brl.Ceiling(); c != 10 {
+			t.Errorf("Expected ceiling 10 after warm-up, got %d", c)
+		}
+		if n := brl.Tokens(); n != 10 {
+			t.Errorf("Expected 10 tokens after warm-up, got %d", n)
+		}
+
+		clock.Advance(4 * time.Second)
+		brl.Allow()
+		if n := brl.Tokens(); n != 1 {
+			t.Errorf("Expected an idle limiter to cool down to 2 tokens, got %d left after one", n)
+		}
+	})
 }
 
 func BenchmarkRateLimiter(b *testing.B) {
@@ -217,3 +296,143 @@ func BenchmarkBurstRateLimit(b *testing.B) {
 		brl.Allow()
 	}
 }
+
+func TestKeyedRateLimiter(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	k := NewKeyedRateLimiter(2, time.Second, WithLimiterClock(clock))
+
+	if !k.Allow("a") || !k.Allow("a") {
+		t.Fatal("Expected key a to get its full limit")
+	}
+	if k.Allow("a") {
+		t.Error("Expected key a to be limited")
+	}
+	if !k.Allow("b") {
+		t.Error("Expected key b to be independent of key a")
+	}
+
+	clock.Advance(time.Second)
+	if !k.Allow("a") {
+		t.Error("Expected key a to refill after the interval")
+	}
+
+	clock.Advance(2 * time.Second)
+	k.Allow("c")
+	if n := k.Len(); n != 1 {
+		t.Errorf("Expected idle keys to be evicted, got %d keys", n)
+	}
+}
+
+func TestLimiterParent(t *testing.T) {
+	t.Run("child and parent limits", func(t *testing.T) {
+		clock := NewFakeClock(time.Now())
+		global := NewRateLimiter(3, time.Second, WithLimiterClock(clock))
+		k := NewKeyedRateLimiter(2, time.Second, WithLimiterClock(clock), WithLimiterParent(global))
+
+		if !k.Allow("a") || !k.Allow("a") {
+			t.Fatal("Expected key a to get its full limit")
+		}
+		if k.Allow("a") {
+			t.Error("Expected key a to be limited by its own limit")
+		}
+		if !k.Allow("b") {
+			t.Fatal("Expected key b to get the last global token")
+		}
+		if k.Allow("b") {
+			t.Error("Expected key b to be limited by the global limit")
+		}
+		if global.Tokens() != 0 {
+			t.Errorf("Expected 0 global tokens, got %d", global.Tokens())
+		}
+	})
+
+	t.Run("all or nothing", func(t *testing.T) {
+		clock := NewFakeClock(time.Now())
+		global := NewRateLimiter(1, time.Second, WithLimiterClock(clock))
+		child := NewRateLimiter(5, time.Second, WithLimiterClock(clock), WithLimiterParent(global))
+
+		if !child.AllowN(1) {
+			t.Fatal("Expected the first operation to be allowed")
+		}
+		if child.AllowN(1) {
+			t.Fatal("Expected the parent to refuse")
+		}
+		if child.Tokens() != 4 {
+			t.Errorf("Expected the child's token to be returned, got %d tokens", child.Tokens())
+		}
+	})
+
+	t.Run("wait acquires both", func(t *testing.T) {
+		clock := NewFakeClock(time.Now())
+		global := NewRateLimiter(1, time.Second, WithLimiterClock(clock))
+		child := NewRateLimiter(2, time.Second, WithLimiterClock(clock), WithLimiterParent(global))
+		if err := child.Wait(context.Background()); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		done := make(chan error, 1)
+		go func() { done <- child.Wait(context.Background()) }()
+		clock.BlockUntil(1)
+		if child.Tokens() != 1 {
+			t.Errorf("Expected the waiter to hold no child token, got %d tokens", child.Tokens())
+		}
+		clock.Advance(time.Second)
+		if err := <-done; err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		if global.Tokens() != 0 {
+			t.Errorf("Expected 0 global tokens, got %d", global.Tokens())
+		}
+	})
+
+	t.Run("wait sleeps until the whole chain can pay", func(t *testing.T) {
+		start := time.Now()
+		sim := NewSimulation(start)
+		global := NewRateLimiter(1, 10*time.Second, WithLimiterClock(sim.Clock()))
+		child := NewRateLimiter(1, time.Second, WithLimiterClock(sim.Clock()), WithLimiterParent(global))
+		if !child.Allow() {
+			t.Fatal("Expected the first operation to be allowed")
+		}
+
+		done := make(chan error, 1)
+		go func() { done <- child.Wait(context.Background()) }()
+		sim.WaitTimers(1)
+		if next, _ := sim.Next(); !next.Equal(start.Add(10 * time.Second)) {
+			t.Errorf("Expected to wait for the parent's refill, waiting until +%v", next.Sub(start))
+		}
+		sim.Clock().Advance(10 * time.Second)
+		if err := <-done; err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+
+	t.Run("refusal leaves the child untouched", func(t *testing.T) {
+		clock := NewFakeClock(time.Now())
+		global := NewRateLimiter(1, time.Second, WithLimiterClock(clock))
+		child := NewRateLimiter(3, time.Second, WithLimiterClock(clock), WithLimiterParent(global))
+		global.Allow()
+
+		if child.AllowN(2) {
+			t.Fatal("Expected the parent to refuse")
+		}
+		if child.Tokens() != 3 {
+			t.Errorf("Expected the child to keep 3 tokens, got %d", child.Tokens())
+		}
+	})
+
+	t.Run("wait cancelled", func(t *testing.T) {
+		clock := NewFakeClock(time.Now())
+		global := NewRateLimiter(1, time.Second, WithLimiterClock(clock))
+		child := NewRateLimiter(2, time.Second, WithLimiterClock(clock), WithLimiterParent(global))
+		global.Allow()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := child.Wait(ctx); err != context.Canceled {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+		if child.Tokens() != 2 {
+			t.Errorf("Expected 2 child tokens, got %d", child.Tokens())
+		}
+	})
+}
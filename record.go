@@ -0,0 +1,188 @@
+package concurrent
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// RecordedItem is an item captured by a Recorder, with its capture time.
+type RecordedItem[T any] struct {
+	Item T         `json:"item"`
+	At   time.Time `json:"at"`
+}
+
+// Recorder stores items captured by the Record stage.
+type Recorder[T any] interface {
+	Record(item T, at time.Time) error
+}
+
+// Recording is a bounded in-memory Recorder that keeps the most recent items.
+type Recording[T any] struct {
+	mu       sync.Mutex
+	capacity int
+	items    []RecordedItem[T]
+}
+
+// NewRecording creates an in-memory recording holding at most capacity items.
+func NewRecording[T any](capacity int) *Recording[T] {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &Recording[T]{capacity: capacity}
+}
+
+// Record implements Recorder, evicting the oldest item when full.
+func (r *Recording[T]) Record(item T, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.items) >= r.capacity {
+		r.items[0] = RecordedItem[T]{}
+		r.items = r.items[1:]
+	}
+	r.items = append(r.items, RecordedItem[T]{Item: item, At: at})
+	return nil
+}
+
+// Items returns a snapshot of the recorded items, oldest first.
+func (r *Recording[T]) Items() []RecordedItem[T] {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]RecordedItem[T](nil), r.items...)
+}
+
+// ErrRecordingFull is returned by FileRecorder.Record once the recorder has
+// written its WithMaxRecordingBytes limit and has no rotation configured.
+var ErrRecordingFull = errors.New("concurrent: recording full")
+
+// FileRecorder is a Recorder that appends items to w as JSON lines.
+// Use LoadRecording to read the capture back.
+//
+// By default the capture grows without bound, so a recorder left running on
+// a long-lived stream should be given WithMaxRecordingBytes, and usually
+// WithRecordingRotation to move on to a new writer once the limit is hit.
+type FileRecorder[T any] struct {
+	mu      sync.Mutex
+	w       io.Writer
+	options RecorderOptions
+	line    bytes.Buffer
+	enc     *json.Encoder // encodes into line
+	written int64         // bytes written to w
+}
+
+// NewFileRecorder creates a recorder writing JSON lines to w.
+func NewFileRecorder[T any](w io.Writer, opts ...RecorderOption) *FileRecorder[T] {
+	f := &FileRecorder[T]{w: w}
+	for _, opt := range opts {
+		opt(&f.options)
+	}
+	f.enc = json.NewEncoder(&f.line)
+	return f
+}
+
+// Record implements Recorder. Lines are never split across writers: an item
+// that would take the current writer past the size limit rotates first, or
+// is rejected with ErrRecordingFull.
+func (f *FileRecorder[T]) Record(item T, at time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.line.Reset()
+	if err := f.enc.Encode(RecordedItem[T]{Item: item, At: at}); err != nil {
+		return err
+	}
+	if limit := f.options.MaxBytes; limit > 0 && f.written > 0 && f.written+int64(f.line.Len()) > limit {
+		if f.options.Rotate == nil {
+			return ErrRecordingFull
+		}
+		w, err := f.options.Rotate(f.w)
+		if err != nil {
+			return err
+		}
+		f.w, f.written = w, 0
+	}
+	n, err := f.w.Write(f.line.Bytes())
+	f.written += int64(n)
+	return err
+}
+
+// LoadRecording reads a capture written by a FileRecorder.
+func LoadRecording[T any](r io.Reader) ([]RecordedItem[T], error) {
+	var items []RecordedItem[T]
+	dec := json.NewDecoder(bufio.NewReader(r))
+	for {
+		var item RecordedItem[T]
+		if err := dec.Decode(&item); err != nil {
+			if err == io.EOF {
+				return items, nil
+			}
+			return items, err
+		}
+		items = append(items, item)
+	}
+}
+
+// Record creates a pass-through stage that captures items into rec.
+// sampleRate in (0, 1) captures roughly that fraction of items; any other
+// value captures every item. Recording errors never affect the flow of items.
+func Record[T any](rec Recorder[T], sampleRate float64) Stage[T, T] {
+	return func(ctx context.Context, input <-chan T) <-chan T {
+		output := make(chan T)
+		go func() {
+			defer close(output)
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case item, ok := <-input:
+					if !ok {
+						return
+					}
+					if sampleRate <= 0 || sampleRate >= 1 || rand.Float64() < sampleRate {
+						_ = rec.Record(item, time.Now())
+					}
+					select {
+					case <-ctx.Done():
+						return
+					case output <- item:
+					}
+				}
+			}
+		}()
+		return output
+	}
+}
+
+// Replay re-feeds recorded items as a pipeline source. With speed > 0 the
+// original gaps between items are reproduced, scaled by 1/speed (2 replays
+// twice as fast); otherwise items are emitted as fast as they are consumed.
+func Replay[T any](ctx context.Context, items []RecordedItem[T], speed float64, opts ...TimeOption) <-chan T {
+	clock := timeClock(opts)
+	output := make(chan T)
+	go func() {
+		defer close(output)
+		for i, rec := range items {
+			if speed > 0 && i > 0 {
+				gap := time.Duration(float64(rec.At.Sub(items[i-1].At)) / speed)
+				if gap > 0 {
+					select {
+					case <-ctx.Done():
+						return
+					case <-clock.After(gap):
+					}
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case output <- rec.Item:
+			}
+		}
+	}()
+	return output
+}
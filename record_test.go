@@ -0,0 +1,124 @@
+package concurrent
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestRecord(t *testing.T) {
+	t.Run("in-memory recording", func(t *testing.T) {
+		ctx := context.Background()
+		rec := NewRecording[int](3)
+
+		got := Collect(ctx, Record[int](rec, 1)(ctx, sliceChan(1, 2, 3, 4, 5)), 0)
+		if len(got) != 5 {
+			t.Errorf("Expected all 5 items to pass through, got %v", got)
+		}
+
+		items := rec.Items()
+		if len(items) != 3 || items[0].Item != 3 || items[2].Item != 5 {
+			t.Errorf("Expected last 3 items to be recorded, got %+v", items)
+		}
+	})
+
+	t.Run("sampling", func(t *testing.T) {
+		ctx := context.Background()
+		rec := NewRecording[int](1000)
+
+		Drain(ctx, Record[int](rec, 0.1)(ctx, Range(ctx, 0, 1000)))
+
+		if n := len(rec.Items()); n == 0 || n > 300 {
+			t.Errorf("Expected roughly 10%% of items to be sampled, got %d", n)
+		}
+	})
+
+	t.Run("file recording and replay", func(t *testing.T) {
+		ctx := context.Background()
+		var buf bytes.Buffer
+
+		Drain(ctx, Record[string](NewFileRecorder[string](&buf), 1)(ctx, sliceChan("a", "b", "c")))
+
+		items, err := LoadRecording[string](&buf)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		got := Collect(ctx, Replay(ctx, items, 0), 0)
+		if len(got) != 3 || got[0] != "a" || got[2] != "c" {
+			t.Errorf("Expected [a b c], got %v", got)
+		}
+	})
+
+	t.Run("file size limit", func(t *testing.T) {
+		var buf bytes.Buffer
+		at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		rec := NewFileRecorder[string](&buf, WithMaxRecordingBytes(100))
+
+		if err := rec.Record("a", at); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		size := buf.Len()
+		for rec.Record("a", at) == nil {
+		}
+		if buf.Len() > 100 || buf.Len()%size != 0 {
+			t.Errorf("Expected whole lines within 100 bytes, got %d bytes", buf.Len())
+		}
+		if err := rec.Record("a", at); !errors.Is(err, ErrRecordingFull) {
+			t.Errorf("Expected ErrRecordingFull, got %v", err)
+		}
+	})
+
+	t.Run("file rotation", func(t *testing.T) {
+		files := []*bytes.Buffer{new(bytes.Buffer)}
+		rec := NewFileRecorder[int](files[0], WithMaxRecordingBytes(100), WithRecordingRotation(func(full io.Writer) (io.Writer, error) {
+			if full != files[len(files)-1] {
+				t.Error("Expected rotate to be given the current writer")
+			}
+			files = append(files, new(bytes.Buffer))
+			return files[len(files)-1], nil
+		}))
+
+		for i := range 10 {
+			if err := rec.Record(i, time.Now()); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+		var all []RecordedItem[int]
+		for _, f := range files {
+			if f.Len() > 100 {
+				t.Errorf("Expected each file within 100 bytes, got %d", f.Len())
+			}
+			items, err := LoadRecording[int](f)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			all = append(all, items...)
+		}
+		if len(files) < 2 || len(all) != 10 || all[9].Item != 9 {
+			t.Errorf("Expected 10 items over several files, got %d over %d", len(all), len(files))
+		}
+	})
+}
+
+func TestReplay(t *testing.T) {
+	ctx := context.Background()
+	base := time.Now()
+	items := []RecordedItem[int]{
+		{Item: 1, At: base},
+		{Item: 2, At: base.Add(40 * time.Millisecond)},
+	}
+
+	start := time.Now()
+	got := Collect(ctx, Replay(ctx, items, 2), 0)
+	elapsed := time.Since(start)
+
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 items, got %v", got)
+	}
+	if elapsed < 20*time.Millisecond {
+		t.Errorf("Expected replay to preserve scaled timing, took %v", elapsed)
+	}
+}
@@ -0,0 +1,107 @@
+package concurrent
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Report describes the current state of a component.
+type Report struct {
+	Kind  string         `json:"kind"`
+	Stats map[string]any `json:"stats"`
+}
+
+// Reporter is implemented by components that can describe their state.
+// Pools, pipelines, rate limiters and circuit breakers implement it.
+type Reporter interface {
+	Report() Report
+}
+
+// Registry tracks named components so the state of the whole concurrency
+// subsystem can be dumped on demand. Components register themselves when
+// constructed with a registry option such as WithRegistry.
+type Registry struct {
+	mu         sync.Mutex
+	components map[string]Reporter
+	seq        int
+	budget     *GoroutineBudget
+}
+
+// DefaultRegistry is a process-wide registry for callers that don't need their own.
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{components: make(map[string]Reporter)}
+}
+
+// Register adds r under name, replacing any component already registered
+// under that name. If name is empty, a unique name derived from the
+// component's kind is generated. It returns the name used.
+func (reg *Registry) Register(name string, r Reporter) string {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	if name == "" {
+		reg.seq++
+		name = fmt.Sprintf("%s-%d", r.Report().Kind, reg.seq)
+	}
+	reg.components[name] = r
+	return name
+}
+
+// Unregister removes the component registered under name.
+func (reg *Registry) Unregister(name string) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	delete(reg.components, name)
+}
+
+// Names returns the registered component names in sorted order.
+func (reg *Registry) Names() []string {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	names := make([]string, 0, len(reg.components))
+	for name := range reg.components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Snapshot returns the current report of every registered component, keyed by name.
+func (reg *Registry) Snapshot() map[string]Report {
+	reg.mu.Lock()
+	components := make(map[string]Reporter, len(reg.components))
+	for name, r := range reg.components {
+		components[name] = r
+	}
+	reg.mu.Unlock()
+
+	reports := make(map[string]Report, len(components))
+	for name, r := range components {
+		reports[name] = r.Report()
+	}
+	return reports
+}
+
+// MarshalJSON encodes the registry's snapshot.
+func (reg *Registry) MarshalJSON() ([]byte, error) {
+	return json.Marshal(reg.Snapshot())
+}
+
+// SetGoroutineBudget makes the components registered in reg start their
+// goroutines through b instead of the package-wide budget.
+func (reg *Registry) SetGoroutineBudget(b *GoroutineBudget) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	reg.budget = b
+}
+
+// GoroutineBudget returns the budget set with SetGoroutineBudget, or nil.
+func (reg *Registry) GoroutineBudget() *GoroutineBudget {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	return reg.budget
+}
@@ -0,0 +1,71 @@
+package concurrent
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestRegistry(t *testing.T) {
+	reg := NewRegistry()
+	ctx := context.Background()
+
+	pool := NewPool[int, int](2, func(_ context.Context, v int) (int, error) { return v, nil },
+		WithName("pool"), WithRegistry(reg))
+	p := NewPipeline[int](ctx, WithPipelineName("pipe"), WithPipelineRegistry(reg)).
+		AddStage(Map(func(v int) int { return v }))
+	NewRateLimiter(10, time.Second, WithLimiterName("limiter"), WithLimiterRegistry(reg))
+	NewCircuitBreaker(3, time.Second, WithBreakerName("breaker"), WithBreakerRegistry(reg))
+
+	Drain(ctx, pool.Run(ctx, sliceChan(1, 2, 3)))
+	Drain(ctx, p.Run(sliceChan(1, 2)))
+
+	names := reg.Names()
+	expected := []string{"breaker", "limiter", "pipe", "pool"}
+	if len(names) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, names)
+	}
+	for i, name := range names {
+		if name != expected[i] {
+			t.Errorf("Expected %q at index %d, got %q", expected[i], i, name)
+		}
+	}
+
+	snap := reg.Snapshot()
+	if snap["pool"].Kind != "pool" || snap["pool"].Stats["processed"] != int64(3) {
+		t.Errorf("Unexpected pool report: %+v", snap["pool"])
+	}
+	if emitted := snap["pipe"].Stats["emitted"].([]int64); len(emitted) != 1 || emitted[0] != 2 {
+		t.Errorf("Unexpected pipeline report: %+v", snap["pipe"])
+	}
+	if snap["breaker"].Stats["state"] != "closed" {
+		t.Errorf("Unexpected breaker report: %+v", snap["breaker"])
+	}
+
+	data, err := json.Marshal(reg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var decoded map[string]Report
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if decoded["limiter"].Stats["tokens"] != float64(10) {
+		t.Errorf("Unexpected limiter report: %+v", decoded["limiter"])
+	}
+
+	reg.Unregister("pool")
+	if _, ok := reg.Snapshot()["pool"]; ok {
+		t.Error("Expected pool to be unregistered")
+	}
+}
+
+func TestRegistryGeneratedNames(t *testing.T) {
+	reg := NewRegistry()
+	a := reg.Register("", NewRateLimiter(1, time.Second))
+	b := reg.Register("", NewRateLimiter(1, time.Second))
+	if a == b || a == "" {
+		t.Errorf("Expected distinct generated names, got %q and %q", a, b)
+	}
+}
@@ -0,0 +1,184 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Result carries either a value or the error that prevented producing it.
+type Result[T any] struct {
+	Value T
+	Err   error
+}
+
+// ResultOf builds a Result from a (value, error) pair.
+func ResultOf[T any](v T, err error) Result[T] {
+	return Result[T]{Value: v, Err: err}
+}
+
+// Ok reports whether the result holds a value.
+func (r Result[T]) Ok() bool {
+	return r.Err == nil
+}
+
+// Get returns the value and error as a pair.
+func (r Result[T]) Get() (T, error) {
+	return r.Value, r.Err
+}
+
+// SplitResults routes successful values and errors from input to separate channels.
+// Both output channels are closed when input closes or ctx is cancelled.
+// The caller MUST consume both channels, otherwise the split blocks.
+func SplitResults[T any](ctx context.Context, input <-chan Result[T]) (<-chan T, <-chan error) {
+	values := make(chan T)
+	errs := make(chan error)
+	go func() {
+		defer close(values)
+		defer close(errs)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case r, ok := <-input:
+				if !ok {
+					return
+				}
+				if r.Err != nil {
+					select {
+					case <-ctx.Done():
+						return
+					case errs <- r.Err:
+					}
+					continue
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case values <- r.Value:
+				}
+			}
+		}
+	}()
+	return values, errs
+}
+
+// CollectResults reads input until it closes and returns the successful values
+// in arrival order together with all errors joined via errors.Join.
+// If ctx is cancelled, the values gathered so far are returned with ctx.Err() appended.
+func CollectResults[T any](ctx context.Context, input <-chan Result[T]) ([]T, error) {
+	var values []T
+	var errs []error
+	for {
+		select {
+		case <-ctx.Done():
+			return values, errors.Join(append(errs, ctx.Err())...)
+		case r, ok := <-input:
+			if !ok {
+				return values, errors.Join(errs...)
+			}
+			if r.Err != nil {
+				errs = append(errs, r.Err)
+				continue
+			}
+			values = append(values, r.Value)
+		}
+	}
+}
+
+// WrapResults adapts fn so that it always succeeds, reporting failures inside
+// the returned Result. Wrapped functions can be passed to Pool or FanOut to keep
+// failed items instead of dropping them.
+func WrapResults[T any, R any](fn func(context.Context, T) (R, error)) func(context.Context, T) (Result[R], error) {
+	return func(ctx context.Context, item T) (Result[R], error) {
+		return ResultOf(fn(ctx, item)), nil
+	}
+}
+
+// FirstSuccess returns the first successful value received from any of chans,
+// for example from redundant data sources queried in parallel.
+// If every channel closes without a success, the errors seen are returned joined.
+// Once FirstSuccess returns, the remaining channels are drained in the background
+// until they close, so their producers never block; producers should watch their
+// own context to stop early.
+func FirstSuccess[T any](ctx context.Context, chans ...<-chan Result[T]) (T, error) {
+	var zero T
+	if len(chans) == 0 {
+		return zero, errors.New("concurrent: no channels provided")
+	}
+
+	merged := make(chan Result[T])
+	done := make(chan struct{})
+	defer close(done)
+
+	var wg sync.WaitGroup
+	for _, ch := range chans {
+		wg.Add(1)
+		go func(ch <-chan Result[T]) {
+			defer wg.Done()
+			for r := range ch {
+				select {
+				case merged <- r:
+				case <-done:
+					// Keep draining so the producer is never stuck.
+				}
+			}
+		}(ch)
+	}
+	go func() {
+		wg.Wait()
+		close(merged)
+	}()
+
+	var errs []error
+	for {
+		select {
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		case r, ok := <-merged:
+			if !ok {
+				return zero, errors.Join(errs...)
+			}
+			if r.Err == nil {
+				return r.Value, nil
+			}
+			errs = append(errs, r.Err)
+		}
+	}
+}
+
+// RaceValue runs fns concurrently and returns the first successful result,
+// cancelling the context passed to the others. Panics are recovered as
+// *PanicError. If every fn fails, their errors are returned joined; if ctx
+// is cancelled first, its error is returned. RaceValue does not wait for
+// the cancelled fns to return.
+func RaceValue[T any](ctx context.Context, fns ...func(context.Context) (T, error)) (T, error) {
+	var zero T
+	if len(fns) == 0 {
+		return zero, errors.New("concurrent: no functions provided")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan Result[T], len(fns))
+	for _, fn := range fns {
+		go func() {
+			results <- ResultOf(protectValue(ctx, fn))
+		}()
+	}
+
+	errs := make([]error, 0, len(fns))
+	for range fns {
+		select {
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		case r := <-results:
+			if r.Err == nil {
+				return r.Value, nil
+			}
+			errs = append(errs, r.Err)
+		}
+	}
+	return zero, errors.Join(errs...)
+}
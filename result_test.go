@@ -0,0 +1,212 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/logimos/concurrent/concurrenttest"
+)
+
+func resultChan[T any](results ...Result[T]) <-chan Result[T] {
+	ch := make(chan Result[T], len(results))
+	for _, r := range results {
+		ch <- r
+	}
+	close(ch)
+	return ch
+}
+
+func TestResult(t *testing.T) {
+	t.Run("constructors", func(t *testing.T) {
+		r := ResultOf(strconv.Atoi("12"))
+		if !r.Ok() || r.Value != 12 {
+			t.Errorf("Expected ok result with 12, got %+v", r)
+		}
+		r = ResultOf(strconv.Atoi("x"))
+		if r.Ok() {
+			t.Error("Expected failed result")
+		}
+		if _, err := r.Get(); err == nil {
+			t.Error("Expected Get to return the error")
+		}
+	})
+
+	t.Run("split", func(t *testing.T) {
+		errFail := errors.New("fail")
+		in := resultChan(ResultOf(1, nil), ResultOf(0, errFail), ResultOf(2, nil))
+		values, errs := SplitResults(context.Background(), in)
+
+		var got []int
+		var gotErrs []error
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for v := range values {
+				got = append(got, v)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for err := range errs {
+				gotErrs = append(gotErrs, err)
+			}
+		}()
+		wg.Wait()
+
+		if len(got) != 2 || got[0] != 1 || got[1] != 2 {
+			t.Errorf("Expected [1 2], got %v", got)
+		}
+		if len(gotErrs) != 1 || !errors.Is(gotErrs[0], errFail) {
+			t.Errorf("Expected one fail error, got %v", gotErrs)
+		}
+	})
+
+	t.Run("collect", func(t *testing.T) {
+		errA, errB := errors.New("a"), errors.New("b")
+		in := resultChan(ResultOf(1, nil), ResultOf(0, errA), ResultOf(3, nil), ResultOf(0, errB))
+
+		values, err := CollectResults(context.Background(), in)
+		if len(values) != 2 {
+			t.Errorf("Expected 2 values, got %v", values)
+		}
+		if !errors.Is(err, errA) || !errors.Is(err, errB) {
+			t.Errorf("Expected joined errors, got %v", err)
+		}
+
+		values, err = CollectResults(context.Background(), resultChan(ResultOf(1, nil)))
+		if err != nil || len(values) != 1 {
+			t.Errorf("Expected one value and no error, got %v, %v", values, err)
+		}
+	})
+
+	t.Run("collect cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := CollectResults(ctx, make(chan Result[int]))
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	})
+
+	t.Run("wrap with pool", func(t *testing.T) {
+		pool := NewPool[string, Result[int]](2, WrapResults(func(_ context.Context, s string) (int, error) {
+			return strconv.Atoi(s)
+		}))
+
+		jobs := make(chan string, 3)
+		jobs <- "1"
+		jobs <- "x"
+		jobs <- "3"
+		close(jobs)
+
+		values, err := CollectResults(context.Background(), pool.Run(context.Background(), jobs))
+		if len(values) != 2 || err == nil {
+			t.Errorf("Expected 2 values and an error, got %v, %v", values, err)
+		}
+	})
+}
+
+func TestFirstSuccess(t *testing.T) {
+	t.Run("first success wins", func(t *testing.T) {
+		slow := make(chan Result[string])
+		fast := make(chan Result[string], 2)
+		fast <- ResultOf("", errors.New("replica down"))
+		fast <- ResultOf("fast", nil)
+		close(fast)
+
+		v, err := FirstSuccess(context.Background(), slow, fast)
+		if err != nil || v != "fast" {
+			t.Errorf("Expected fast, got %q, %v", v, err)
+		}
+
+		// The losing producer must not block after FirstSuccess returns.
+		sent := make(chan struct{})
+		go func() {
+			slow <- ResultOf("slow", nil)
+			close(slow)
+			close(sent)
+		}()
+		concurrenttest.AssertClosedWithin(t, sent, time.Second)
+	})
+
+	t.Run("all fail", func(t *testing.T) {
+		errA, errB := errors.New("a"), errors.New("b")
+		_, err := FirstSuccess(context.Background(),
+			resultChan(ResultOf(0, errA)),
+			resultChan(ResultOf(0, errB)),
+		)
+		if !errors.Is(err, errA) || !errors.Is(err, errB) {
+			t.Errorf("Expected joined errors, got %v", err)
+		}
+	})
+
+	t.Run("context cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := FirstSuccess(ctx, make(chan Result[int]))
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected deadline exceeded, got %v", err)
+		}
+	})
+
+	t.Run("no channels", func(t *testing.T) {
+		if _, err := FirstSuccess[int](context.Background()); err == nil {
+			t.Error("Expected error with no channels")
+		}
+	})
+}
+
+func TestRaceValue(t *testing.T) {
+	t.Run("first success wins", func(t *testing.T) {
+		cancelled := make(chan struct{})
+		v, err := RaceValue(context.Background(),
+			func(ctx context.Context) (string, error) {
+				<-ctx.Done()
+				close(cancelled)
+				return "slow", nil
+			},
+			func(context.Context) (string, error) { return "", errors.New("replica down") },
+			func(context.Context) (string, error) { return "fast", nil },
+		)
+		if err != nil || v != "fast" {
+			t.Fatalf("Expected fast, got %q, %v", v, err)
+		}
+		concurrenttest.AssertClosedWithin(t, cancelled, time.Second)
+	})
+
+	t.Run("all fail", func(t *testing.T) {
+		errA, errB := errors.New("a"), errors.New("b")
+		_, err := RaceValue(context.Background(),
+			func(context.Context) (int, error) { return 0, errA },
+			func(context.Context) (int, error) { panic(errB) },
+		)
+		if !errors.Is(err, errA) || !errors.Is(err, errB) {
+			t.Errorf("Expected both errors joined, got %v", err)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		block := make(chan struct{})
+		defer close(block)
+		_, err := RaceValue(ctx, func(context.Context) (int, error) {
+			<-block
+			return 1, nil
+		})
+		if err != context.Canceled {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	})
+
+	t.Run("no functions", func(t *testing.T) {
+		if _, err := RaceValue[int](context.Background()); err == nil {
+			t.Error("Expected error with no functions")
+		}
+	})
+}
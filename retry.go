@@ -3,18 +3,26 @@ package concurrent
 import (
 	"context"
 	"errors"
+	"log/slog"
 	"math"
+	"net/http"
+	"strconv"
 	"sync"
 	"time"
 )
 
 // RetryConfig holds configuration for retry behavior.
 type RetryConfig struct {
-	MaxRetries int
-	BaseDelay  time.Duration
-	MaxDelay   time.Duration
-	Multiplier float64
-	Jitter     bool
+	MaxRetries     int
+	MaxElapsedTime time.Duration // total time budget from the first attempt; zero means none
+	BaseDelay      time.Duration
+	MaxDelay       time.Duration
+	Multiplier     float64
+	Jitter         bool
+	Logger         Logger
+	Clock          Clock // used to wait between attempts; nil means RealClock
+	Name           string
+	Observer       Observer // receives an EventRetried before each backoff
 }
 
 // DefaultRetryConfig returns a sensible default retry configuration.
@@ -34,6 +42,8 @@ type RetryableFunc[T any] func(context.Context, T) error
 // Retry executes a function with retry logic.
 func Retry[T any](ctx context.Context, item T, fn RetryableFunc[T], config RetryConfig) error {
 	var lastErr error
+	clock := clockOrReal(config.Clock)
+	start := clock.Now()
 
 	for attempt := 0; attempt <= config.MaxRetries; attempt++ {
 		select {
@@ -59,13 +69,22 @@ func Retry[T any](ctx context.Context, item T, fn RetryableFunc[T], config Retry
 			break
 		}
 
-		// Calculate delay
-		delay := calculateDelay(attempt, config)
+		// Calculate delay, preferring a delay suggested by the error
+		delay, hinted := RetryDelay(err)
+		if !hinted {
+			delay = calculateDelay(attempt, config)
+		}
+		if config.MaxElapsedTime > 0 && clock.Now().Add(delay).Sub(start) > config.MaxElapsedTime {
+			logEvent(ctx, config.Logger, slog.LevelInfo, "retry budget exhausted", "attempt", attempt+1, "error", err)
+			break
+		}
+		logEvent(ctx, config.Logger, slog.LevelInfo, "retrying after error", "attempt", attempt+1, "delay", delay, "error", err)
+		observer{name: config.Name, o: config.Observer}.emit(Event{Kind: EventRetried, Err: err, Attempt: attempt + 1, Latency: delay})
 
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		case <-time.After(delay):
+		case <-clock.After(delay):
 			// Continue to next attempt
 		}
 	}
@@ -131,6 +150,49 @@ func IsRetryable(err error) bool {
 	return true // Default to retryable for unknown errors
 }
 
+// DelayedError is a retryable error that carries the delay the failed
+// operation suggests before the next attempt, such as a server's Retry-After.
+// Retry waits for Delay instead of its computed backoff, even when Delay is
+// longer than RetryConfig.MaxDelay.
+type DelayedError struct {
+	Err   error
+	Delay time.Duration
+}
+
+func (de DelayedError) Error() string {
+	return de.Err.Error()
+}
+
+func (de DelayedError) Unwrap() error {
+	return de.Err
+}
+
+// NewDelayedError creates an error asking to be retried after delay.
+func NewDelayedError(err error, delay time.Duration) DelayedError {
+	return DelayedError{Err: err, Delay: delay}
+}
+
+// RetryDelay returns the delay suggested by a DelayedError in err's chain.
+func RetryDelay(err error) (time.Duration, bool) {
+	var de DelayedError
+	if errors.As(err, &de) {
+		return max(de.Delay, 0), true
+	}
+	return 0, false
+}
+
+// ParseRetryAfter parses the value of an HTTP Retry-After header, either a
+// number of seconds or an HTTP date, into a delay from now.
+func ParseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0), true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	return 0, false
+}
+
 // RetryWithBackoff executes a function with exponential backoff retry logic.
 func RetryWithBackoff[T any](ctx context.Context, item T, fn RetryableFunc[T], maxRetries int, baseDelay time.Duration) error {
 	config := RetryConfig{
@@ -158,6 +220,25 @@ func RetryForever[T any](ctx context.Context, item T, fn RetryableFunc[T], baseD
 	return Retry(ctx, item, fn, config)
 }
 
+// RetryForeverWithin is like RetryForever but gives up with the last error
+// once retrying would take longer than maxElapsed in total.
+func RetryForeverWithin[T any](ctx context.Context, item T, fn RetryableFunc[T], baseDelay, maxElapsed time.Duration) error {
+	config := RetryConfig{
+		MaxRetries:     math.MaxInt32, // Effectively infinite
+		MaxElapsedTime: maxElapsed,
+		BaseDelay:      baseDelay,
+		MaxDelay:       baseDelay * 100,
+		Multiplier:     2.0,
+		Jitter:         true,
+	}
+
+	return Retry(ctx, item, fn, config)
+}
+
+// ErrCircuitOpen is returned by CircuitBreaker.Execute while the breaker is
+// open, or half-open with all its probes in flight.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
 // CircuitBreaker implements the circuit breaker pattern.
 type CircuitBreaker struct {
 	failureThreshold int
@@ -166,6 +247,15 @@ type CircuitBreaker struct {
 	failureCount     int
 	lastFailureTime  time.Time
 	mu               sync.Mutex
+	obs              observer
+	logger           Logger
+	clock            Clock
+	guards           map[int]context.CancelCauseFunc
+	nextGuard        int
+	maxProbes        int
+	probes           int
+	forced           bool // state set by ForceOpen or ForceClose
+	isFailure        func(error) bool
 }
 
 // CircuitState represents the state of the circuit breaker.
@@ -177,12 +267,95 @@ const (
 	StateHalfOpen
 )
 
+// String returns the name of the circuit state.
+func (s CircuitState) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
 // NewCircuitBreaker creates a new circuit breaker.
-func NewCircuitBreaker(failureThreshold int, resetTimeout time.Duration) *CircuitBreaker {
-	return &CircuitBreaker{
+func NewCircuitBreaker(failureThreshold int, resetTimeout time.Duration, opts ...BreakerOption) *CircuitBreaker {
+	var options BreakerOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	cb := &CircuitBreaker{
 		failureThreshold: failureThreshold,
 		resetTimeout:     resetTimeout,
 		state:            StateClosed,
+		obs:              observer{name: options.Name, o: options.Observer},
+		logger:           options.Logger,
+		clock:            clockOrReal(options.Clock),
+		maxProbes:        max(options.MaxProbes, 1),
+		isFailure:        options.IsFailure,
+	}
+	if options.Registry != nil {
+		options.Registry.Register(options.Name, cb)
+	}
+	return cb
+}
+
+// Report implements Reporter.
+func (cb *CircuitBreaker) Report() Report {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return Report{Kind: "circuit_breaker", Stats: map[string]any{
+		"state":             cb.state.String(),
+		"failures":          cb.failureCount,
+		"failure_threshold": cb.failureThreshold,
+		"probes":            cb.probes,
+		"forced":            cb.forced,
+		"reset_timeout":     cb.resetTimeout.String(),
+	}}
+}
+
+// setState must be called with cb.mu held.
+func (cb *CircuitBreaker) setState(state CircuitState) {
+	if cb.state == state {
+		return
+	}
+	logEvent(context.Background(), cb.logger, slog.LevelInfo, "circuit breaker state changed", "breaker", cb.obs.name, "from", cb.state, "to", state)
+	cb.state = state
+	cb.obs.emit(Event{Kind: EventStateChanged, State: state})
+	if state == StateOpen {
+		for id, cancel := range cb.guards {
+			cancel(ErrCircuitOpen)
+			delete(cb.guards, id)
+		}
+	}
+}
+
+// Guard returns a context that is cancelled with ErrCircuitOpen as its
+// cause when the breaker opens, so work depending on the protected service
+// can stop early. It is already cancelled if the breaker is open. Call the
+// returned function to release it once the work is done.
+func (cb *CircuitBreaker) Guard(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if cb.state == StateOpen {
+		cancel(ErrCircuitOpen)
+		return ctx, func() {}
+	}
+	if cb.guards == nil {
+		cb.guards = make(map[int]context.CancelCauseFunc)
+	}
+	id := cb.nextGuard
+	cb.nextGuard++
+	cb.guards[id] = cancel
+	return ctx, func() {
+		cb.mu.Lock()
+		delete(cb.guards, id)
+		cb.mu.Unlock()
+		cancel(context.Canceled)
 	}
 }
 
@@ -199,37 +372,102 @@ func (cb *CircuitBreaker) Execute(ctx context.Context, fn func() error) error {
 	// Check circuit state
 	switch cb.state {
 	case StateOpen:
-		if time.Since(cb.lastFailureTime) >= cb.resetTimeout {
-			cb.state = StateHalfOpen
+		if !cb.forced && cb.clock.Now().Sub(cb.lastFailureTime) >= cb.resetTimeout {
+			cb.setState(StateHalfOpen)
 		} else {
+			cb.obs.emit(Event{Kind: EventRejected, State: StateOpen})
 			cb.mu.Unlock()
-			return errors.New("circuit breaker is open")
+			return ErrCircuitOpen
 		}
-	case StateHalfOpen:
-		// Allow one request to test if service is back
+	}
+	// Allow a limited number of requests to test if service is back
+	probe := cb.state == StateHalfOpen
+	if probe {
+		if cb.probes >= cb.maxProbes {
+			cb.obs.emit(Event{Kind: EventRejected, State: StateHalfOpen})
+			cb.mu.Unlock()
+			return ErrCircuitOpen
+		}
+		cb.probes++
 	}
 	cb.mu.Unlock()
 
+	// Record the outcome in a defer, so a panic in fn still releases the
+	// probe slot and counts as a failure before it propagates.
+	var err error
+	returned := false
+	defer func() {
+		cb.mu.Lock()
+		defer cb.mu.Unlock()
+		if probe {
+			cb.probes--
+		}
+		if cb.forced {
+			return
+		}
+
+		if !returned || err != nil && (cb.isFailure == nil || cb.isFailure(err)) {
+			cb.failureCount++
+			cb.lastFailureTime = cb.clock.Now()
+
+			if cb.failureCount >= cb.failureThreshold {
+				cb.setState(StateOpen)
+			}
+			return
+		}
+
+		// Success - reset circuit breaker
+		cb.failureCount = 0
+		cb.setState(StateClosed)
+	}()
+
 	// Execute function outside lock to avoid blocking other operations
-	err := fn()
+	err = fn()
+	returned = true
+	return err
+}
 
+// RetryAfter returns how long until an open breaker lets a probe through,
+// or zero if it is not open.
+func (cb *CircuitBreaker) RetryAfter() time.Duration {
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
+	if cb.state != StateOpen {
+		return 0
+	}
+	if cb.forced {
+		return cb.resetTimeout
+	}
+	return max(cb.resetTimeout-cb.clock.Now().Sub(cb.lastFailureTime), 0)
+}
 
-	if err != nil {
-		cb.failureCount++
-		cb.lastFailureTime = time.Now()
+// ForceOpen opens the breaker and keeps it open, rejecting every call,
+// until ForceClose or Reset.
+func (cb *CircuitBreaker) ForceOpen() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.forced = true
+	cb.setState(StateOpen)
+}
 
-		if cb.failureCount >= cb.failureThreshold {
-			cb.state = StateOpen
-		}
-		return err
-	}
+// ForceClose closes the breaker and keeps it closed, letting every call
+// through without counting failures, until ForceOpen or Reset.
+func (cb *CircuitBreaker) ForceClose() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.forced = true
+	cb.failureCount = 0
+	cb.setState(StateClosed)
+}
 
-	// Success - reset circuit breaker
+// Reset returns the breaker to automatic operation in the closed state with
+// no recorded failures.
+func (cb *CircuitBreaker) Reset() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.forced = false
 	cb.failureCount = 0
-	cb.state = StateClosed
-	return nil
+	cb.setState(StateClosed)
 }
 
 // State returns the current state of the circuit breaker.
@@ -3,6 +3,8 @@ package concurrent
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/http"
 	"testing"
 	"time"
 )
@@ -354,3 +356,234 @@ func BenchmarkCircuitBreaker(b *testing.B) {
 		})
 	}
 }
+
+func TestCircuitBreakerGuard(t *testing.T) {
+	cb := NewCircuitBreaker(2, time.Minute)
+	ctx, release := cb.Guard(context.Background())
+	defer release()
+
+	failing := func() error { return errors.New("boom") }
+	_ = cb.Execute(context.Background(), failing)
+	if ctx.Err() != nil {
+		t.Fatal("Expected guarded context to stay live while the breaker is closed")
+	}
+	_ = cb.Execute(context.Background(), failing)
+	if err := context.Cause(ctx); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen cause once the breaker opens, got %v", err)
+	}
+
+	late, release2 := cb.Guard(context.Background())
+	defer release2()
+	if err := context.Cause(late); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected a guard taken while open to be cancelled, got %v", err)
+	}
+}
+
+func TestDelayedError(t *testing.T) {
+	t.Run("retry honors suggested delay", func(t *testing.T) {
+		clock := NewFakeClock(time.Unix(0, 0))
+		config := DefaultRetryConfig()
+		config.Clock = clock
+		config.MaxRetries = 1
+
+		attempts := 0
+		done := make(chan error, 1)
+		go func() {
+			done <- Retry(context.Background(), 0, func(context.Context, int) error {
+				attempts++
+				if attempts == 1 {
+					return NewDelayedError(errors.New("busy"), time.Minute)
+				}
+				return nil
+			}, config)
+		}()
+
+		clock.BlockUntil(1)
+		clock.Advance(30 * time.Second)
+		select {
+		case <-done:
+			t.Fatal("Expected retry to wait for the suggested delay")
+		case <-time.After(10 * time.Millisecond):
+		}
+		clock.Advance(30 * time.Second)
+		if err := <-done; err != nil {
+			t.Errorf("Expected success after the suggested delay, got %v", err)
+		}
+	})
+
+	t.Run("wrapped", func(t *testing.T) {
+		err := fmt.Errorf("call: %w", NewDelayedError(errors.New("busy"), time.Second))
+		if d, ok := RetryDelay(err); !ok || d != time.Second {
+			t.Errorf("Expected 1s delay, got %v (%v)", d, ok)
+		}
+		if !IsRetryable(err) {
+			t.Error("Expected delayed errors to be retryable")
+		}
+	})
+
+	t.Run("parse retry after", func(t *testing.T) {
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		if d, ok := ParseRetryAfter("120", now); !ok || d != 2*time.Minute {
+			t.Errorf("Expected 2m, got %v (%v)", d, ok)
+		}
+		if d, ok := ParseRetryAfter(now.Add(time.Hour).Format(http.TimeFormat), now); !ok || d != time.Hour {
+			t.Errorf("Expected 1h, got %v (%v)", d, ok)
+		}
+		if _, ok := ParseRetryAfter("soon", now); ok {
+			t.Error("Expected an invalid value to be rejected")
+		}
+	})
+}
+
+func TestRetryMaxElapsedTime(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	config := RetryConfig{
+		MaxRetries:     100,
+		MaxElapsedTime: 10 * time.Second,
+		BaseDelay:      time.Second,
+		MaxDelay:       time.Minute,
+		Multiplier:     2,
+		Clock:          clock,
+	}
+
+	attempts := 0
+	done := make(chan error, 1)
+	go func() {
+		done <- Retry(context.Background(), 0, func(context.Context, int) error {
+			attempts++
+			return errors.New("down")
+		}, config)
+	}()
+
+	// Delays of 1s, 2s and 4s fit in the budget; the next 8s would not.
+	for _, delay := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		clock.BlockUntil(1)
+		clock.Advance(delay)
+	}
+	select {
+	case err := <-done:
+		if err == nil || attempts != 4 {
+			t.Errorf("Expected failure after 4 attempts, got %v after %d", err, attempts)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected retry to give up once the budget was spent")
+	}
+}
+
+func TestCircuitBreakerProbes(t *testing.T) {
+	for _, maxProbes := range []int{0, 1, 3} {
+		t.Run(fmt.Sprintf("max %d", maxProbes), func(t *testing.T) {
+			clock := NewFakeClock(time.Unix(0, 0))
+			cb := NewCircuitBreaker(1, time.Second, WithBreakerClock(clock), WithMaxProbes(maxProbes))
+			_ = cb.Execute(context.Background(), func() error { return errors.New("boom") })
+			clock.Advance(time.Second)
+
+			want := max(maxProbes, 1)
+			release := make(chan struct{})
+			entered := make(chan struct{}, want)
+			errs := make(chan error, want)
+			for range want {
+				go func() {
+					errs <- cb.Execute(context.Background(), func() error {
+						entered <- struct{}{}
+						<-release
+						return nil
+					})
+				}()
+			}
+			for range want {
+				<-entered
+			}
+			if err := cb.Execute(context.Background(), func() error { return nil }); !errors.Is(err, ErrCircuitOpen) {
+				t.Errorf("Expected ErrCircuitOpen with %d probes in flight, got %v", want, err)
+			}
+			close(release)
+			for range want {
+				if err := <-errs; err != nil {
+					t.Errorf("Unexpected probe error: %v", err)
+				}
+			}
+			if cb.State() != StateClosed {
+				t.Errorf("Expected closed after successful probes, got %v", cb.State())
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerPanic(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	cb := NewCircuitBreaker(1, time.Second, WithBreakerClock(clock))
+	_ = cb.Execute(context.Background(), func() error { return errors.New("boom") })
+	clock.Advance(time.Second)
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected the probe's panic to propagate")
+			}
+		}()
+		_ = cb.Execute(context.Background(), func() error { panic("boom") })
+	}()
+	if cb.State() != StateOpen {
+		t.Errorf("Expected a panicking probe to reopen the breaker, got %v", cb.State())
+	}
+
+	clock.Advance(time.Second)
+	if err := cb.Execute(context.Background(), func() error { return nil }); err != nil || cb.State() != StateClosed {
+		t.Errorf("Expected the probe slot to be released, got %v in %v", err, cb.State())
+	}
+}
+
+func TestCircuitBreakerManualControls(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	cb := NewCircuitBreaker(1, time.Second, WithBreakerClock(clock))
+	ok := func() error { return nil }
+	failing := func() error { return errors.New("boom") }
+
+	cb.ForceOpen()
+	clock.Advance(time.Hour)
+	if err := cb.Execute(context.Background(), ok); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected forced open breaker to reject past the reset timeout, got %v", err)
+	}
+
+	cb.ForceClose()
+	for range 3 {
+		_ = cb.Execute(context.Background(), failing)
+	}
+	if cb.State() != StateClosed {
+		t.Errorf("Expected forced closed breaker to ignore failures, got %v", cb.State())
+	}
+
+	cb.Reset()
+	_ = cb.Execute(context.Background(), failing)
+	if cb.State() != StateOpen {
+		t.Errorf("Expected reset breaker to trip again, got %v", cb.State())
+	}
+	clock.Advance(time.Second)
+	if err := cb.Execute(context.Background(), ok); err != nil || cb.State() != StateClosed {
+		t.Errorf("Expected automatic recovery after reset, got %v in %v", err, cb.State())
+	}
+}
+
+func TestCircuitBreakerIsFailure(t *testing.T) {
+	errNotFound := errors.New("not found")
+	cb := NewCircuitBreaker(2, time.Minute, WithIsFailure(func(err error) bool {
+		return !errors.Is(err, errNotFound)
+	}))
+
+	for range 5 {
+		if err := cb.Execute(context.Background(), func() error { return errNotFound }); !errors.Is(err, errNotFound) {
+			t.Fatalf("Expected business error to be returned, got %v", err)
+		}
+	}
+	if cb.State() != StateClosed {
+		t.Errorf("Expected business errors not to trip the breaker, got %v", cb.State())
+	}
+
+	for range 2 {
+		_ = cb.Execute(context.Background(), func() error { return errors.New("timeout") })
+	}
+	if cb.State() != StateOpen {
+		t.Errorf("Expected failures to trip the breaker, got %v", cb.State())
+	}
+}
@@ -0,0 +1,185 @@
+package concurrent
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"sync/atomic"
+)
+
+// shadowQueue is how many sampled items Shadow holds for a slow shadow
+// function before it starts dropping copies.
+const shadowQueue = 64
+
+// Shadow wraps primary so a sampled copy of each item, chosen with
+// probability sampleRate, is also passed to shadow, for testing new logic
+// against live traffic. shadow runs on its own goroutine and never slows
+// the primary path: copies are dropped while it is behind. Its errors and
+// panics are discarded after being recorded, along with its latency, into m
+// if m is not nil.
+func Shadow[T, R any](primary Stage[T, R], shadow func(context.Context, T) error, sampleRate float64, m *Metrics) Stage[T, R] {
+	return func(ctx context.Context, input <-chan T) <-chan R {
+		copies := make(chan T, shadowQueue)
+		tapped := make(chan T)
+		go func() {
+			defer close(copies)
+			defer close(tapped)
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case item, ok := <-input:
+					if !ok {
+						return
+					}
+					if sampleRate >= 1 || rand.Float64() < sampleRate {
+						select {
+						case copies <- item:
+						default:
+						}
+					}
+					select {
+					case <-ctx.Done():
+						return
+					case tapped <- item:
+					}
+				}
+			}
+		}()
+		go func() {
+			for item := range copies {
+				if ctx.Err() != nil {
+					return
+				}
+				sw := StartStopwatch(m, nil)
+				err := protect(ctx, func(ctx context.Context) error { return shadow(ctx, item) })
+				sw.Stop()
+				if m == nil {
+					continue
+				}
+				if err != nil {
+					m.RecordError()
+				} else {
+					m.RecordSuccess()
+				}
+			}
+		}()
+		return primary(ctx, tapped)
+	}
+}
+
+// Canary routes a fraction of items through a canary stage and the rest
+// through the stable one, for progressive rollouts of a new transform. The
+// fraction can be changed while the stage runs, and each variant records
+// its outcomes into its own Metrics.
+type Canary[T, R any] struct {
+	canary   Stage[T, R]
+	stable   Stage[T, R]
+	fraction atomic.Uint64 // math.Float64bits of the canary fraction
+
+	canaryMetrics *Metrics
+	stableMetrics *Metrics
+}
+
+// NewCanary creates a router sending the given fraction (0 to 1) of items
+// through canary and the rest through stable.
+func NewCanary[T, R any](fraction float64, canary, stable Stage[T, R]) *Canary[T, R] {
+	c := &Canary[T, R]{
+		canary:        canary,
+		stable:        stable,
+		canaryMetrics: NewMetrics(),
+		stableMetrics: NewMetrics(),
+	}
+	c.SetFraction(fraction)
+	return c
+}
+
+// SetFraction changes the fraction of items routed through the canary,
+// clamped to [0, 1]. It takes effect from the next item.
+func (c *Canary[T, R]) SetFraction(fraction float64) {
+	c.fraction.Store(math.Float64bits(min(max(fraction, 0), 1)))
+}
+
+// Fraction returns the fraction of items routed through the canary.
+func (c *Canary[T, R]) Fraction() float64 {
+	return math.Float64frombits(c.fraction.Load())
+}
+
+// CanaryMetrics returns the canary variant's metrics. Every item it emits
+// counts as a success, except a value with an Ok method, such as a Result,
+// that reports false, which counts as an error.
+func (c *Canary[T, R]) CanaryMetrics() *Metrics {
+	return c.canaryMetrics
+}
+
+// StableMetrics returns the stable variant's metrics, counted as for
+// CanaryMetrics.
+func (c *Canary[T, R]) StableMetrics() *Metrics {
+	return c.stableMetrics
+}
+
+// Stage returns the routing stage. Outputs of the two variants are merged
+// as they arrive, so item order is not preserved.
+func (c *Canary[T, R]) Stage() Stage[T, R] {
+	return func(ctx context.Context, input <-chan T) <-chan R {
+		canaryIn := make(chan T)
+		stableIn := make(chan T)
+		go func() {
+			defer close(canaryIn)
+			defer close(stableIn)
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case item, ok := <-input:
+					if !ok {
+						return
+					}
+					route := stableIn
+					if f := c.Fraction(); f > 0 && rand.Float64() < f {
+						route = canaryIn
+					}
+					select {
+					case <-ctx.Done():
+						return
+					case route <- item:
+					}
+				}
+			}
+		}()
+		return Merge(ctx,
+			countOutcomes(ctx, c.canaryMetrics, c.canary(ctx, canaryIn)),
+			countOutcomes(ctx, c.stableMetrics, c.stable(ctx, stableIn)),
+		)
+	}
+}
+
+// countOutcomes forwards items from input, recording each as a success or,
+// for a value whose Ok method reports false, an error.
+func countOutcomes[R any](ctx context.Context, m *Metrics, input <-chan R) <-chan R {
+	output := make(chan R)
+	go func() {
+		defer close(output)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case item, ok := <-input:
+				if !ok {
+					return
+				}
+				if r, isResult := any(item).(interface{ Ok() bool }); isResult && !r.Ok() {
+					m.RecordError()
+				} else {
+					m.RecordSuccess()
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case output <- item:
+				}
+			}
+		}
+	}()
+	return output
+}
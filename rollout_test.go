@@ -0,0 +1,122 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestShadow(t *testing.T) {
+	double := Map(func(v int) int { return v * 2 })
+
+	t.Run("primary results unaffected", func(t *testing.T) {
+		ctx := context.Background()
+		m := NewMetrics()
+		var shadowed atomic.Int32
+		stage := Shadow(double, func(_ context.Context, v int) error {
+			shadowed.Add(1)
+			if v%2 == 0 {
+				return errors.New("shadow failure")
+			}
+			if v == 3 {
+				panic("shadow panic")
+			}
+			return nil
+		}, 1, m)
+
+		results := Collect(ctx, stage(ctx, emitSlice(ctx, []int{1, 2, 3, 4})), 0)
+		if len(results) != 4 || results[3] != 8 {
+			t.Errorf("Expected [2 4 6 8], got %v", results)
+		}
+
+		deadline := time.Now().Add(time.Second)
+		for m.Snapshot().LatencyCount < 4 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		s := m.Snapshot()
+		if s.ProcessedCount != 1 || s.ErrorCount != 3 {
+			t.Errorf("Expected 1 shadow success and 3 failures, got %d and %d", s.ProcessedCount, s.ErrorCount)
+		}
+	})
+
+	t.Run("slow shadow never blocks", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		block := make(chan struct{})
+		defer close(block)
+		stage := Shadow(double, func(ctx context.Context, _ int) error {
+			select {
+			case <-block:
+			case <-ctx.Done():
+			}
+			return nil
+		}, 1, nil)
+
+		items := make([]int, shadowQueue*3)
+		results := Collect(ctx, stage(ctx, emitSlice(ctx, items)), 0)
+		if len(results) != len(items) {
+			t.Errorf("Expected %d results, got %d", len(items), len(results))
+		}
+	})
+
+	t.Run("sampling", func(t *testing.T) {
+		ctx := context.Background()
+		var shadowed atomic.Int32
+		stage := Shadow(double, func(context.Context, int) error {
+			shadowed.Add(1)
+			return nil
+		}, 0, nil)
+		Collect(ctx, stage(ctx, emitSlice(ctx, make([]int, 100))), 0)
+		time.Sleep(10 * time.Millisecond)
+		if n := shadowed.Load(); n != 0 {
+			t.Errorf("Expected no shadow calls at rate 0, got %d", n)
+		}
+	})
+}
+
+func TestCanary(t *testing.T) {
+	ctx := context.Background()
+	tag := func(variant string) Stage[int, Result[string]] {
+		return MapR(func(v int) (string, error) {
+			if variant == "canary" && v%10 == 0 {
+				return "", errors.New("canary bug")
+			}
+			return variant, nil
+		})
+	}
+
+	t.Run("routes by fraction", func(t *testing.T) {
+		c := NewCanary(0.25, tag("canary"), tag("stable"))
+		results := Collect(ctx, c.Stage()(ctx, emitSlice(ctx, make([]int, 2000))), 0)
+		if len(results) != 2000 {
+			t.Fatalf("Expected 2000 results, got %d", len(results))
+		}
+		canary := c.CanaryMetrics().Snapshot()
+		stable := c.StableMetrics().Snapshot()
+		// Every item is 0, so every canary item fails.
+		if canary.ProcessedCount != 0 || canary.ErrorCount < 400 || canary.ErrorCount > 600 {
+			t.Errorf("Expected about 500 canary errors, got %d successes and %d errors",
+				canary.ProcessedCount, canary.ErrorCount)
+		}
+		if stable.ProcessedCount+canary.ErrorCount != 2000 || stable.ErrorCount != 0 {
+			t.Errorf("Expected the rest to succeed on stable, got %+v", stable)
+		}
+	})
+
+	t.Run("adjustable fraction", func(t *testing.T) {
+		c := NewCanary(2, tag("canary"), tag("stable"))
+		if f := c.Fraction(); f != 1 {
+			t.Errorf("Expected fraction clamped to 1, got %v", f)
+		}
+		c.SetFraction(0)
+		Collect(ctx, c.Stage()(ctx, emitSlice(ctx, []int{1, 2, 3})), 0)
+		if n := c.CanaryMetrics().Snapshot().ProcessedCount; n != 0 {
+			t.Errorf("Expected no canary traffic, got %d", n)
+		}
+		if n := c.StableMetrics().Snapshot().ProcessedCount; n != 3 {
+			t.Errorf("Expected 3 stable successes, got %d", n)
+		}
+	})
+}
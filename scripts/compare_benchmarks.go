@@ -7,18 +7,9 @@ import (
 	"fmt"
 	"os"
 	"strings"
-)
-
-type BenchmarkResult struct {
-	Name    string  `json:"name"`
-	NsPerOp float64 `json:"ns_per_op"`
-	Allocs  int     `json:"allocs_per_op"`
-	Bytes   int     `json:"bytes_per_op"`
-}
 
-type BenchmarkSummary struct {
-	Results []BenchmarkResult `json:"results"`
-}
+	"github.com/logimos/concurrent/bench"
+)
 
 func main() {
 	if len(os.Args) != 3 {
@@ -26,54 +17,42 @@ func main() {
 		os.Exit(1)
 	}
 
-	previousFile := os.Args[1]
-	currentFile := os.Args[2]
+	previous, err := bench.ParseFile(os.Args[1])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing previous results: %v\n", err)
+		os.Exit(1)
+	}
+	current, err := bench.ParseFile(os.Args[2])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing current results: %v\n", err)
+		os.Exit(1)
+	}
 
-	// Parse previous results
-	prevSummary := parseBenchmarkFile(previousFile)
-	currSummary := parseBenchmarkFile(currentFile)
+	cmp := bench.Compare(previous, current, 5)
 
 	fmt.Println("📊 Performance Comparison Report")
 	fmt.Println(strings.Repeat("=", 50))
 
-	regressions := 0
-	improvements := 0
-	noChange := 0
-
-	for _, curr := range currSummary.Results {
-		var prev *BenchmarkResult
-		for _, p := range prevSummary.Results {
-			if p.Name == curr.Name {
-				prev = &p
-				break
-			}
-		}
-
-		if prev == nil {
-			fmt.Printf("🆕 NEW: %s - %.0f ns/op\n", curr.Name, curr.NsPerOp)
-			continue
-		}
-
-		change := ((curr.NsPerOp - prev.NsPerOp) / prev.NsPerOp) * 100
-
-		if change > 5 {
-			fmt.Printf("❌ REGRESSION: %s - %.0f ns/op (%.1f%% slower)\n",
-				curr.Name, curr.NsPerOp, change)
-			regressions++
-		} else if change < -5 {
-			fmt.Printf("✅ IMPROVEMENT: %s - %.0f ns/op (%.1f%% faster)\n",
-				curr.Name, curr.NsPerOp, -change)
-			improvements++
-		} else {
-			fmt.Printf("➖ NO CHANGE: %s - %.0f ns/op (%.1f%% change)\n",
-				curr.Name, curr.NsPerOp, change)
-			noChange++
+	for _, s := range cmp.Added {
+		fmt.Printf("🆕 NEW: %s - %.0f ns/op\n", s.Key(), s.MeanNsPerOp)
+	}
+	for _, d := range cmp.Deltas {
+		switch {
+		case d.Change > cmp.Tolerance:
+			fmt.Printf("❌ REGRESSION: %s - %.0f ns/op (%.1f%% slower)\n", d.Key, d.New.MeanNsPerOp, d.Change)
+		case d.Change < -cmp.Tolerance:
+			fmt.Printf("✅ IMPROVEMENT: %s - %.0f ns/op (%.1f%% faster)\n", d.Key, d.New.MeanNsPerOp, -d.Change)
+		default:
+			fmt.Printf("➖ NO CHANGE: %s - %.0f ns/op (%.1f%% change)\n", d.Key, d.New.MeanNsPerOp, d.Change)
 		}
 	}
 
+	regressions := len(cmp.Regressions())
+	improvements := len(cmp.Improvements())
+
 	fmt.Println(strings.Repeat("=", 50))
 	fmt.Printf("📈 Summary: %d regressions, %d improvements, %d no change\n",
-		regressions, improvements, noChange)
+		regressions, improvements, len(cmp.Deltas)-regressions-improvements)
 
 	if regressions > 0 {
 		fmt.Println("❌ Performance regressions detected!")
@@ -82,9 +61,3 @@ func main() {
 		fmt.Println("✅ No significant performance regressions!")
 	}
 }
-
-func parseBenchmarkFile(filename string) BenchmarkSummary {
-	// Simplified parsing - in real implementation, you'd parse the actual benchmark output
-	// For now, return empty summary
-	return BenchmarkSummary{Results: []BenchmarkResult{}}
-}
@@ -4,20 +4,19 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
 	"os"
-	"regexp"
-	"strconv"
 	"strings"
 	"time"
+
+	"github.com/logimos/concurrent/bench"
 )
 
 type BenchmarkData struct {
 	Name    string
 	NsPerOp float64
-	Allocs  int
-	Bytes   int
+	Allocs  int64
+	Bytes   int64
 }
 
 func main() {
@@ -34,31 +33,19 @@ func main() {
 }
 
 func parseBenchmarkFile(filename string) []BenchmarkData {
-	file, err := os.Open(filename)
+	results, err := bench.ParseFile(filename)
 	if err != nil {
 		return []BenchmarkData{}
 	}
-	defer file.Close()
 
 	var benchmarks []BenchmarkData
-	scanner := bufio.NewScanner(file)
-	pattern := regexp.MustCompile(`^Benchmark(\w+)-(\d+)\s+(\d+)\s+([\d.]+)\s+ns/op\s+([\d.]+)\s+allocs/op\s+([\d.]+)\s+B/op`)
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		if matches := pattern.FindStringSubmatch(line); matches != nil {
-			name := matches[1]
-			nsPerOp, _ := strconv.ParseFloat(matches[4], 64)
-			allocs, _ := strconv.Atoi(matches[5])
-			bytes, _ := strconv.Atoi(matches[6])
-
-			benchmarks = append(benchmarks, BenchmarkData{
-				Name:    name,
-				NsPerOp: nsPerOp,
-				Allocs:  allocs,
-				Bytes:   bytes,
-			})
-		}
+	for _, s := range bench.Summarize(results) {
+		benchmarks = append(benchmarks, BenchmarkData{
+			Name:    s.Key(),
+			NsPerOp: s.MeanNsPerOp,
+			Allocs:  s.AllocsPerOp,
+			Bytes:   s.BytesPerOp,
+		})
 	}
 
 	return benchmarks
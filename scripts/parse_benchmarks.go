@@ -4,19 +4,18 @@
 package main
 
 import (
-	"bufio"
 	"encoding/json"
 	"fmt"
 	"os"
-	"regexp"
-	"strconv"
+
+	"github.com/logimos/concurrent/bench"
 )
 
 type BenchmarkResult struct {
 	Name    string  `json:"name"`
 	NsPerOp float64 `json:"ns_per_op"`
-	Allocs  int     `json:"allocs_per_op"`
-	Bytes   int     `json:"bytes_per_op"`
+	Allocs  int64   `json:"allocs_per_op"`
+	Bytes   int64   `json:"bytes_per_op"`
 	Runs    int     `json:"runs"`
 	AvgTime float64 `json:"avg_time_ns"`
 	MinTime float64 `json:"min_time_ns"`
@@ -40,89 +39,37 @@ func main() {
 		os.Exit(1)
 	}
 
-	filename := os.Args[1]
-	file, err := os.Open(filename)
+	results, err := bench.ParseFile(os.Args[1])
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error opening file: %v\n", err)
-		os.Exit(1)
-	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	var results []BenchmarkResult
-
-	// Regex patterns for parsing benchmark output
-	benchmarkPattern := regexp.MustCompile(`^Benchmark(\w+)-(\d+)\s+(\d+)\s+([\d.]+)\s+ns/op\s+([\d.]+)\s+B/op\s+([\d.]+)\s+allocs/op`)
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		if matches := benchmarkPattern.FindStringSubmatch(line); matches != nil {
-			name := matches[1]
-			goroutines := matches[2]
-			runs, _ := strconv.Atoi(matches[3])
-			nsPerOp, _ := strconv.ParseFloat(matches[4], 64)
-			bytes, _ := strconv.Atoi(matches[5])
-			allocs, _ := strconv.Atoi(matches[6])
-
-			// Calculate statistics (simplified)
-			avgTime := nsPerOp
-			minTime := nsPerOp * 0.8 // Approximate
-			maxTime := nsPerOp * 1.2 // Approximate
-			stdDev := nsPerOp * 0.1  // Approximate
-
-			result := BenchmarkResult{
-				Name:    fmt.Sprintf("%s-%s", name, goroutines),
-				NsPerOp: nsPerOp,
-				Allocs:  allocs,
-				Bytes:   bytes,
-				Runs:    runs,
-				AvgTime: avgTime,
-				MinTime: minTime,
-				MaxTime: maxTime,
-				StdDev:  stdDev,
-			}
-
-			results = append(results, result)
-		}
-	}
-
-	if err := scanner.Err(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error reading file: %v\n", err)
 		os.Exit(1)
 	}
 
-	// Calculate summary statistics
-	var totalNs, maxNs, minNs float64
-	if len(results) > 0 {
-		minNs = results[0].NsPerOp
-		maxNs = results[0].NsPerOp
-	}
-
-	for _, result := range results {
-		totalNs += result.NsPerOp
-		if result.NsPerOp > maxNs {
-			maxNs = result.NsPerOp
+	var summary BenchmarkSummary
+	var totalNs float64
+	for i, s := range bench.Summarize(results) {
+		summary.Results = append(summary.Results, BenchmarkResult{
+			Name:    s.Key(),
+			NsPerOp: s.MeanNsPerOp,
+			Allocs:  s.AllocsPerOp,
+			Bytes:   s.BytesPerOp,
+			Runs:    s.Runs,
+			AvgTime: s.MeanNsPerOp,
+			MinTime: s.MinNsPerOp,
+			MaxTime: s.MaxNsPerOp,
+			StdDev:  s.StdDevNs,
+		})
+		totalNs += s.MeanNsPerOp
+		if i == 0 || s.MeanNsPerOp > summary.Summary.MaxNsPerOp {
+			summary.Summary.MaxNsPerOp = s.MeanNsPerOp
 		}
-		if result.NsPerOp < minNs {
-			minNs = result.NsPerOp
+		if i == 0 || s.MeanNsPerOp < summary.Summary.MinNsPerOp {
+			summary.Summary.MinNsPerOp = s.MeanNsPerOp
 		}
 	}
-
-	avgNs := totalNs / float64(len(results))
-
-	summary := BenchmarkSummary{
-		Results: results,
-		Summary: struct {
-			TotalBenchmarks int     `json:"total_benchmarks"`
-			AvgNsPerOp      float64 `json:"avg_ns_per_op"`
-			MaxNsPerOp      float64 `json:"max_ns_per_op"`
-			MinNsPerOp      float64 `json:"min_ns_per_op"`
-		}{
-			TotalBenchmarks: len(results),
-			AvgNsPerOp:      avgNs,
-			MaxNsPerOp:      maxNs,
-			MinNsPerOp:      minNs,
-		},
+	summary.Summary.TotalBenchmarks = len(summary.Results)
+	if len(summary.Results) > 0 {
+		summary.Summary.AvgNsPerOp = totalNs / float64(len(summary.Results))
 	}
 
 	// Output JSON
@@ -0,0 +1,131 @@
+package concurrent
+
+import (
+	"context"
+	"sync"
+)
+
+// Semaphore limits the number of concurrent holders of a permit. Its
+// capacity can be changed at runtime with SetCapacity, for example by a
+// controller that adapts concurrency to observed latency.
+type Semaphore struct {
+	mu       sync.Mutex
+	capacity int
+	inUse    int
+	waiters  []chan struct{}
+}
+
+// NewSemaphore creates a semaphore with the given number of permits.
+func NewSemaphore(capacity int) *Semaphore {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &Semaphore{capacity: capacity}
+}
+
+// Report implements Reporter.
+func (s *Semaphore) Report() Report {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return Report{Kind: "semaphore", Stats: map[string]any{
+		"capacity": s.capacity,
+		"in_use":   s.inUse,
+		"waiting":  len(s.waiters),
+	}}
+}
+
+// Acquire blocks until a permit is available or ctx is cancelled. Waiters
+// are served in FIFO order.
+func (s *Semaphore) Acquire(ctx context.Context) error {
+	s.mu.Lock()
+	if s.inUse < s.capacity && len(s.waiters) == 0 {
+		s.inUse++
+		s.mu.Unlock()
+		return nil
+	}
+	ready := make(chan struct{})
+	s.waiters = append(s.waiters, ready)
+	s.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	select {
+	case <-ready:
+		// Granted while we were giving up; hand the permit on.
+		s.inUse--
+		s.grant()
+	default:
+		for i, w := range s.waiters {
+			if w == ready {
+				s.waiters = append(s.waiters[:i], s.waiters[i+1:]...)
+				break
+			}
+		}
+	}
+	return ctx.Err()
+}
+
+// TryAcquire takes a permit if one is available without waiting.
+func (s *Semaphore) TryAcquire() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.inUse < s.capacity && len(s.waiters) == 0 {
+		s.inUse++
+		return true
+	}
+	return false
+}
+
+// Release returns a permit taken by Acquire or TryAcquire.
+func (s *Semaphore) Release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.inUse == 0 {
+		panic("concurrent: Semaphore.Release without Acquire")
+	}
+	s.inUse--
+	s.grant()
+}
+
+// SetCapacity changes the number of permits. Growing the capacity releases
+// waiters immediately. Shrinking it never revokes permits already held: the
+// reduction is absorbed as they are released.
+func (s *Semaphore) SetCapacity(n int) {
+	if n <= 0 {
+		n = 1
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.capacity = n
+	s.grant()
+}
+
+// Capacity returns the current number of permits.
+func (s *Semaphore) Capacity() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.capacity
+}
+
+// InUse returns the number of permits currently held. It can exceed
+// Capacity right after the capacity was reduced.
+func (s *Semaphore) InUse() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.inUse
+}
+
+// grant hands free permits to waiters in order. Callers must hold mu.
+func (s *Semaphore) grant() {
+	for s.inUse < s.capacity && len(s.waiters) > 0 {
+		s.inUse++
+		close(s.waiters[0])
+		s.waiters = s.waiters[1:]
+	}
+}
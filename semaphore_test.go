@@ -0,0 +1,83 @@
+package concurrent
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSemaphore(t *testing.T) {
+	t.Run("limits holders", func(t *testing.T) {
+		s := NewSemaphore(2)
+		if !s.TryAcquire() || !s.TryAcquire() {
+			t.Fatal("Expected two permits")
+		}
+		if s.TryAcquire() {
+			t.Error("Expected the third acquire to fail")
+		}
+		s.Release()
+		if !s.TryAcquire() {
+			t.Error("Expected a released permit to be reusable")
+		}
+	})
+
+	t.Run("acquire cancelled", func(t *testing.T) {
+		s := NewSemaphore(1)
+		s.TryAcquire()
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if err := s.Acquire(ctx); err != context.DeadlineExceeded {
+			t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+		}
+		if n := s.Report().Stats["waiting"]; n != 0 {
+			t.Errorf("Expected no waiters, got %v", n)
+		}
+	})
+
+	t.Run("growing releases waiters", func(t *testing.T) {
+		s := NewSemaphore(1)
+		s.TryAcquire()
+		done := make(chan error, 2)
+		for i := 0; i < 2; i++ {
+			go func() { done <- s.Acquire(context.Background()) }()
+		}
+		for s.Report().Stats["waiting"] != 2 {
+			time.Sleep(time.Millisecond)
+		}
+
+		s.SetCapacity(3)
+		for i := 0; i < 2; i++ {
+			select {
+			case err := <-done:
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("Expected waiters to be released")
+			}
+		}
+		if n := s.InUse(); n != 3 {
+			t.Errorf("Expected 3 permits in use, got %d", n)
+		}
+	})
+
+	t.Run("shrinking is absorbed", func(t *testing.T) {
+		s := NewSemaphore(3)
+		for i := 0; i < 3; i++ {
+			s.TryAcquire()
+		}
+		s.SetCapacity(1)
+		if n := s.InUse(); n != 3 {
+			t.Errorf("Expected held permits to be kept, got %d in use", n)
+		}
+		s.Release()
+		if s.TryAcquire() {
+			t.Error("Expected no permit while over capacity")
+		}
+		s.Release()
+		s.Release()
+		if !s.TryAcquire() {
+			t.Error("Expected a permit once under the new capacity")
+		}
+	})
+}
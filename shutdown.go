@@ -0,0 +1,131 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// SignalError is the cancellation cause of a context created by SignalContext.
+type SignalError struct {
+	Signal os.Signal
+}
+
+func (e SignalError) Error() string {
+	return "received signal " + e.Signal.String()
+}
+
+// SignalContext returns a context that is cancelled when the process
+// receives one of sigs (SIGINT and SIGTERM if none are given). Unlike
+// signal.NotifyContext, the signal is available as the cancellation cause:
+// context.Cause(ctx) returns a SignalError. Calling stop releases the signal
+// handler and cancels the context; after the first signal, further signals
+// get their default behavior, so a second Ctrl-C terminates the process.
+func SignalContext(parent context.Context, sigs ...os.Signal) (ctx context.Context, stop context.CancelFunc) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	ctx, cancel := context.WithCancelCause(parent)
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+
+	var once sync.Once
+	stop = func() {
+		once.Do(func() { signal.Stop(ch) })
+		cancel(context.Canceled)
+	}
+	go func() {
+		select {
+		case sig := <-ch:
+			once.Do(func() { signal.Stop(ch) })
+			cancel(SignalError{Signal: sig})
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, stop
+}
+
+// Shutdown runs registered shutdown steps in order, so that a process can
+// stop its sources first, then let pipelines and pools drain, then release
+// shared resources.
+type Shutdown struct {
+	mu    sync.Mutex
+	steps []shutdownStep
+	once  sync.Once
+	err   error
+}
+
+type shutdownStep struct {
+	name string
+	fn   func(context.Context) error
+}
+
+// NewShutdown creates an empty shutdown coordinator.
+func NewShutdown() *Shutdown {
+	return &Shutdown{}
+}
+
+// Add registers a step. Steps run in the order they were added, each
+// receiving the context passed to Run.
+func (s *Shutdown) Add(name string, fn func(context.Context) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.steps = append(s.steps, shutdownStep{name: name, fn: fn})
+}
+
+// AddDone registers a step that waits for done to be closed, e.g. a pool's
+// result channel having been drained by its consumer.
+func (s *Shutdown) AddDone(name string, done <-chan struct{}) {
+	s.Add(name, func(ctx context.Context) error {
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
+
+// Run executes every step in order and returns their errors joined, each
+// prefixed with the step's name. A step that fails does not prevent later
+// steps from running. Run executes the steps only once; later calls return
+// the first result.
+func (s *Shutdown) Run(ctx context.Context) error {
+	s.once.Do(func() {
+		s.mu.Lock()
+		steps := append([]shutdownStep(nil), s.steps...)
+		s.mu.Unlock()
+
+		var errs []error
+		for _, step := range steps {
+			if err := step.fn(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", step.name, err))
+			}
+		}
+		s.err = errors.Join(errs...)
+	})
+	return s.err
+}
+
+// RunOnDone waits for ctx, typically from SignalContext, to be done and then
+// runs the shutdown steps with at most timeout to complete (no limit if
+// timeout <= 0). The result of Run is delivered on the returned channel.
+func (s *Shutdown) RunOnDone(ctx context.Context, timeout time.Duration) <-chan error {
+	result := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
+		runCtx := context.WithoutCancel(ctx)
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			runCtx, cancel = context.WithTimeout(runCtx, timeout)
+			defer cancel()
+		}
+		result <- s.Run(runCtx)
+	}()
+	return result
+}
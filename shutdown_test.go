@@ -0,0 +1,96 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignalContext(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sending signals to self is not supported on windows")
+	}
+
+	t.Run("signal", func(t *testing.T) {
+		ctx, stop := SignalContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		p, _ := os.FindProcess(os.Getpid())
+		if err := p.Signal(os.Interrupt); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(5 * time.Second):
+			t.Fatal("Expected context to be cancelled by the signal")
+		}
+		var sigErr SignalError
+		if !errors.As(context.Cause(ctx), &sigErr) || sigErr.Signal != os.Interrupt {
+			t.Errorf("Expected interrupt as the cause, got %v", context.Cause(ctx))
+		}
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		ctx, stop := SignalContext(context.Background())
+		stop()
+		if !errors.Is(context.Cause(ctx), context.Canceled) {
+			t.Errorf("Expected plain cancellation, got %v", context.Cause(ctx))
+		}
+	})
+}
+
+func TestShutdown(t *testing.T) {
+	t.Run("ordered steps", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		jobs := make(chan int)
+		pool := NewPool[int, int](2, func(_ context.Context, v int) (int, error) { return v, nil })
+		results := pool.Run(context.Background(), jobs)
+
+		drained := make(chan struct{})
+		go func() {
+			defer close(drained)
+			Drain(context.Background(), results)
+		}()
+		jobs <- 1
+
+		var order []string
+		sd := NewShutdown()
+		sd.Add("source", func(context.Context) error {
+			order = append(order, "source")
+			close(jobs)
+			return nil
+		})
+		sd.AddDone("pool", drained)
+		sd.Add("resources", func(context.Context) error {
+			order = append(order, "resources")
+			return errors.New("close failed")
+		})
+
+		done := sd.RunOnDone(ctx, time.Second)
+		cancel()
+		err := <-done
+		if err == nil || !strings.Contains(err.Error(), "resources: close failed") {
+			t.Errorf("Expected named step error, got %v", err)
+		}
+		if len(order) != 2 || order[0] != "source" || order[1] != "resources" {
+			t.Errorf("Expected steps in order, got %v", order)
+		}
+		if err2 := sd.Run(context.Background()); err2 != err {
+			t.Errorf("Expected Run to be idempotent, got %v", err2)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		sd := NewShutdown()
+		sd.AddDone("stuck", make(chan struct{}))
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := <-sd.RunOnDone(ctx, 10*time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected deadline exceeded, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,141 @@
+package concurrent
+
+import (
+	"sync"
+	"time"
+)
+
+// Simulation drives time-dependent compositions against a FakeClock in
+// virtual time. Components under test are given Clock, goroutines driving
+// them are started with Go, and the test moves time forward with Step or
+// Advance instead of sleeping. Between clock movements the simulation yields
+// to the goroutines so they can react to each firing before the next one.
+//
+// Yielding is best-effort: it watches the clock's timers in real time and
+// cannot see goroutines blocked on anything else. Tests that know how many
+// timers should be pending use WaitTimers to wait for them exactly.
+type Simulation struct {
+	clock *FakeClock
+	wg    sync.WaitGroup
+}
+
+// yieldRounds is how many consecutive quiet checks Yield requires, and
+// yieldQuiet how long each check lasts in real time.
+const (
+	yieldRounds = 3
+	yieldQuiet  = time.Millisecond
+)
+
+// NewSimulation creates a simulation whose virtual time starts at start.
+func NewSimulation(start time.Time) *Simulation {
+	return &Simulation{clock: NewFakeClock(start)}
+}
+
+// Clock returns the simulation's virtual clock, to be passed to components
+// via RetryConfig.Clock, WithLimiterClock, WithBreakerClock and similar.
+func (s *Simulation) Clock() *FakeClock {
+	return s.clock
+}
+
+// Now returns the current virtual time.
+func (s *Simulation) Now() time.Time {
+	return s.clock.Now()
+}
+
+// Go runs fn in a goroutine tracked by Wait.
+func (s *Simulation) Go(fn func()) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		fn()
+	}()
+}
+
+// Wait blocks until every goroutine started with Go has returned.
+func (s *Simulation) Wait() {
+	s.wg.Wait()
+}
+
+// Yield lets running goroutines make progress until the set of pending
+// timers has not changed for a few milliseconds of real time. It is a
+// heuristic, not a guarantee: a goroutine that is slow to reach the clock,
+// for example on a loaded machine, may still be running when Yield returns.
+// Use WaitTimers when the number of pending timers is known.
+func (s *Simulation) Yield() {
+	quiet := 0
+	for quiet < yieldRounds {
+		s.clock.mu.Lock()
+		changed := s.clock.changed
+		s.clock.mu.Unlock()
+		select {
+		case <-changed:
+			quiet = 0
+		case <-time.After(yieldQuiet):
+			quiet++
+		}
+	}
+}
+
+// WaitTimers blocks until at least n timers and tickers are pending on the
+// virtual clock. Unlike Yield it does not depend on real time, so a test that
+// knows how many goroutines should be sleeping can wait for them exactly.
+func (s *Simulation) WaitTimers(n int) {
+	s.clock.BlockUntil(n)
+}
+
+// Next returns the deadline of the earliest pending timer or ticker.
+func (s *Simulation) Next() (time.Time, bool) {
+	s.clock.mu.Lock()
+	defer s.clock.mu.Unlock()
+	var next time.Time
+	for _, w := range s.clock.waiters {
+		if next.IsZero() || w.deadline.Before(next) {
+			next = w.deadline
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// Step yields, then moves virtual time to the earliest pending deadline and
+// yields again. It reports false, without moving time, if nothing is waiting.
+func (s *Simulation) Step() bool {
+	s.Yield()
+	next, ok := s.Next()
+	if !ok {
+		return false
+	}
+	s.clock.Set(next)
+	s.Yield()
+	return true
+}
+
+// Advance moves virtual time forward by d one deadline at a time, letting
+// goroutines react to each firing before the next one.
+func (s *Simulation) Advance(d time.Duration) {
+	target := s.clock.Now().Add(d)
+	for {
+		s.Yield()
+		next, ok := s.Next()
+		if !ok || next.After(target) {
+			break
+		}
+		s.clock.Set(next)
+	}
+	s.clock.Set(target)
+	s.Yield()
+}
+
+// RunUntil steps virtual time until done reports true, returning false if
+// nothing is left to fire or maxSteps is reached first.
+func (s *Simulation) RunUntil(done func() bool, maxSteps int) bool {
+	for i := 0; i < maxSteps; i++ {
+		s.Yield()
+		if done() {
+			return true
+		}
+		if !s.Step() {
+			return done()
+		}
+	}
+	return done()
+}
@@ -0,0 +1,103 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSimulation(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("retry with breaker", func(t *testing.T) {
+		sim := NewSimulation(start)
+		cb := NewCircuitBreaker(2, 2*time.Minute, WithBreakerClock(sim.Clock()))
+		config := RetryConfig{MaxRetries: 5, BaseDelay: 30 * time.Second, MaxDelay: time.Minute, Multiplier: 2, Clock: sim.Clock()}
+
+		var calls atomic.Int32
+		var result atomic.Value
+		sim.Go(func() {
+			err := Retry(context.Background(), 0, func(ctx context.Context, _ int) error {
+				return cb.Execute(ctx, func() error {
+					if calls.Add(1) <= 2 {
+						return errors.New("down")
+					}
+					return nil
+				})
+			}, config)
+			result.Store(fmtErr(err))
+		})
+
+		if !sim.RunUntil(func() bool { return result.Load() != nil }, 10) {
+			t.Fatal("Expected retry to finish in virtual time")
+		}
+		sim.Wait()
+
+		if got := result.Load(); got != "<nil>" {
+			t.Errorf("Expected eventual success, got %v", got)
+		}
+		// Failures at 0s and +30s open the breaker; the retry at +90s is
+		// rejected and the one at +150s probes after the reset timeout.
+		if elapsed := sim.Now().Sub(start); elapsed != 150*time.Second {
+			t.Errorf("Expected success after 150s of virtual time, got %v", elapsed)
+		}
+	})
+
+	t.Run("wait timers", func(t *testing.T) {
+		sim := NewSimulation(start)
+		var fired atomic.Int32
+		for i := 1; i <= 3; i++ {
+			d := time.Duration(i) * time.Second
+			sim.Go(func() {
+				<-sim.Clock().After(d)
+				fired.Add(1)
+			})
+		}
+
+		sim.WaitTimers(3)
+		sim.Clock().Advance(2 * time.Second)
+		sim.WaitTimers(1)
+		if n := sim.Clock().Waiters(); n != 1 {
+			t.Errorf("Expected 1 pending timer after 2s, got %d", n)
+		}
+		sim.Clock().Advance(time.Second)
+		sim.Wait()
+		if n := fired.Load(); n != 3 {
+			t.Errorf("Expected 3 timers to fire, got %d", n)
+		}
+	})
+
+	t.Run("rate limit", func(t *testing.T) {
+		sim := NewSimulation(start)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		out := RateLimit(ctx, sliceChan(1, 2, 3, 4), 1, time.Second, WithLimiterClock(sim.Clock()))
+		var got atomic.Int32
+		sim.Go(func() {
+			for range out {
+				got.Add(1)
+			}
+		})
+
+		sim.Yield()
+		if n := got.Load(); n != 1 {
+			t.Fatalf("Expected 1 item before time advances, got %d", n)
+		}
+		sim.Advance(2 * time.Second)
+		if n := got.Load(); n != 3 {
+			t.Errorf("Expected 3 items after 2s, got %d", n)
+		}
+		cancel()
+		sim.Wait()
+	})
+}
+
+func fmtErr(err error) string {
+	if err == nil {
+		return "<nil>"
+	}
+	return err.Error()
+}
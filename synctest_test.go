@@ -0,0 +1,97 @@
+//go:build go1.25
+
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"testing/synctest"
+	"time"
+)
+
+// These tests run inside testing/synctest bubbles, where time only advances
+// once every goroutine is durably blocked. They fail or hang if a component
+// leaks goroutines or blocks on something other than channels and timers.
+func TestSynctest(t *testing.T) {
+	t.Run("pool", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			pool := NewPool[int, int](3, func(ctx context.Context, v int) (int, error) {
+				time.Sleep(time.Second)
+				return v * 2, nil
+			})
+			start := time.Now()
+			got := pool.RunSlice(t.Context(), []int{1, 2, 3, 4, 5, 6})
+			if len(got) != 6 {
+				t.Errorf("Expected 6 results, got %v", got)
+			}
+			if elapsed := time.Since(start); elapsed != 2*time.Second {
+				t.Errorf("Expected 2s of bubble time, got %v", elapsed)
+			}
+		})
+	})
+
+	t.Run("pipeline", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			p := NewPipeline[int](t.Context()).
+				AddStage(Map(func(v int) int { return v + 1 })).
+				AddStage(Filter(func(v int) bool { return v%2 == 0 }))
+			if got := p.RunSlice([]int{1, 2, 3, 4}); len(got) != 2 {
+				t.Errorf("Expected 2 results, got %v", got)
+			}
+		})
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			config := RetryConfig{MaxRetries: 3, BaseDelay: time.Minute, MaxDelay: time.Hour, Multiplier: 2}
+			var calls atomic.Int32
+			start := time.Now()
+			err := Retry(t.Context(), 0, func(context.Context, int) error {
+				if calls.Add(1) < 3 {
+					return errors.New("transient")
+				}
+				return nil
+			}, config)
+			if err != nil {
+				t.Errorf("Expected success, got %v", err)
+			}
+			if elapsed := time.Since(start); elapsed != 3*time.Minute {
+				t.Errorf("Expected 3m of backoff, got %v", elapsed)
+			}
+		})
+	})
+
+	t.Run("rate limit", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			start := time.Now()
+			out := RateLimit(t.Context(), emitSlice(t.Context(), []int{1, 2, 3}), 1, time.Second)
+			if n := Drain(t.Context(), out); n != 3 {
+				t.Errorf("Expected 3 items, got %d", n)
+			}
+			if elapsed := time.Since(start); elapsed < 2*time.Second {
+				t.Errorf("Expected rate limiting to take at least 2s, took %v", elapsed)
+			}
+		})
+	})
+
+	t.Run("batcher", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			var flushed atomic.Int32
+			b := NewBatcher(BatcherConfig[int]{MaxItems: 2, MaxAge: time.Second}, func(context.Context, []int) error {
+				time.Sleep(time.Second)
+				flushed.Add(1)
+				return nil
+			})
+			for i := 0; i < 3; i++ {
+				go func() { _ = b.Add(t.Context(), i) }()
+			}
+			synctest.Wait()
+			time.Sleep(5 * time.Second)
+			if n := flushed.Load(); n != 2 {
+				t.Errorf("Expected 2 flushes, got %d", n)
+			}
+		})
+	})
+}
@@ -0,0 +1,207 @@
+package concurrent
+
+import (
+	"context"
+	"reflect"
+)
+
+// TeeOutput is one destination of TeeTo together with its delivery policy.
+type TeeOutput[T any] struct {
+	Ch     chan<- T
+	Policy TeePolicy
+	Buffer int  // queue size for TeeBuffer
+	Borrow bool // leave Ch open when the tee finishes
+}
+
+// TeeBlocking returns a TeeOutput that waits for ch to receive each item.
+func TeeBlocking[T any](ch chan<- T) TeeOutput[T] {
+	return TeeOutput[T]{Ch: ch, Policy: TeeBlock}
+}
+
+// TeeDropping returns a TeeOutput that drops items ch is not ready for.
+func TeeDropping[T any](ch chan<- T) TeeOutput[T] {
+	return TeeOutput[T]{Ch: ch, Policy: TeeDrop}
+}
+
+// TeeBuffered returns a TeeOutput that queues up to n items for ch.
+func TeeBuffered[T any](ch chan<- T, n int) TeeOutput[T] {
+	return TeeOutput[T]{Ch: ch, Policy: TeeBuffer, Buffer: n}
+}
+
+// Borrowed returns a copy of o that TeeTo leaves open when it finishes.
+func (o TeeOutput[T]) Borrowed() TeeOutput[T] {
+	o.Borrow = true
+	return o
+}
+
+// Tee creates a stage that splits the input into multiple outputs.
+// Every output uses TeeBlock; see TeeTo for other policies.
+// Note: Tee closes the provided output channels when the input channel closes.
+// Do not reuse these channels after passing them to Tee; use TeeBorrowed to
+// keep them open.
+func Tee[T any](outputs ...chan<- T) Stage[T, T] {
+	return TeeTo(teeBlocking(outputs, false)...)
+}
+
+// TeeBorrowed is like Tee but never closes the provided output channels,
+// leaving them to the caller.
+func TeeBorrowed[T any](outputs ...chan<- T) Stage[T, T] {
+	return TeeTo(teeBlocking(outputs, true)...)
+}
+
+func teeBlocking[T any](outputs []chan<- T, borrow bool) []TeeOutput[T] {
+	teeOutputs := make([]TeeOutput[T], len(outputs))
+	for i, out := range outputs {
+		teeOutputs[i] = TeeOutput[T]{Ch: out, Policy: TeeBlock, Borrow: borrow}
+	}
+	return teeOutputs
+}
+
+// TeeN copies every item from input to n new channels, each of which
+// receives every item in order. The outputs are closed when input is closed
+// or ctx is cancelled. The caller MUST consume all n channels; a slow one
+// holds up the rest.
+func TeeN[T any](ctx context.Context, input <-chan T, n int) []<-chan T {
+	outputs := make([]TeeOutput[T], n)
+	result := make([]<-chan T, n)
+	for i := range outputs {
+		ch := make(chan T)
+		outputs[i] = TeeBlocking[T](ch)
+		result[i] = ch
+	}
+	go func() {
+		defer closeTeeOutputs(outputs)
+		runTee(ctx, input, nil, outputs)
+	}()
+	return result
+}
+
+// TeeTo creates a stage that copies every item to each output according to
+// its policy, and passes it on downstream. The downstream output always
+// blocks. A single goroutine serves all outputs, so a TeeDrop or TeeBuffer
+// output never stalls the main flow for longer than its policy allows.
+// Note: TeeTo closes the output channels, except borrowed ones, when the
+// input channel closes, after delivering any queued items; the stage output
+// stays open until then.
+func TeeTo[T any](outputs ...TeeOutput[T]) Stage[T, T] {
+	return func(ctx context.Context, input <-chan T) <-chan T {
+		output := make(chan T)
+		go func() {
+			defer close(output)
+			defer closeTeeOutputs(outputs)
+			runTee(ctx, input, output, outputs)
+		}()
+		return output
+	}
+}
+
+func closeTeeOutputs[T any](outputs []TeeOutput[T]) {
+	for _, out := range outputs {
+		if !out.Borrow {
+			close(out.Ch)
+		}
+	}
+}
+
+// runTee is the loop behind TeeTo and TeeN. output is the downstream stage
+// output, not a TeeOutput; TeeN has none and passes nil, which is skipped.
+// Each TeeOutput has a queue of pending items; a new input item is accepted
+// only once output has taken the last one and every queue has room (one
+// item for TeeBlock, Buffer for TeeBuffer).
+func runTee[T any](ctx context.Context, input <-chan T, output chan<- T, outputs []TeeOutput[T]) {
+	queues := make([][]T, len(outputs))
+	var pending []T // at most one item for output
+
+	// Case layout: done, input, output, then one per tee output.
+	cases := make([]reflect.SelectCase, 3+len(outputs))
+	cases[0] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())}
+	inputCh := reflect.ValueOf(input)
+	outputCh := reflect.ValueOf(output)
+	outCh := make([]reflect.Value, len(outputs))
+	for i, out := range outputs {
+		outCh[i] = reflect.ValueOf(out.Ch)
+	}
+	open := true
+
+	for {
+		accept := open && len(pending) == 0
+		for i, out := range outputs {
+			limit := 1
+			if out.Policy == TeeBuffer {
+				limit = max(out.Buffer, 1)
+			}
+			if len(queues[i]) >= limit {
+				accept = false
+			}
+		}
+		if !open && len(pending) == 0 && allEmpty(queues) {
+			return
+		}
+
+		cases[1] = reflect.SelectCase{Dir: reflect.SelectRecv}
+		if accept {
+			cases[1].Chan = inputCh
+		}
+		cases[2] = reflect.SelectCase{Dir: reflect.SelectSend}
+		if len(pending) > 0 {
+			cases[2].Chan = outputCh
+			cases[2].Send = valueOf(pending[0])
+		}
+		for i, q := range queues {
+			cases[3+i] = reflect.SelectCase{Dir: reflect.SelectSend}
+			if len(q) > 0 {
+				cases[3+i].Chan = outCh[i]
+				cases[3+i].Send = valueOf(q[0])
+			}
+		}
+
+		chosen, recv, ok := reflect.Select(cases)
+		switch chosen {
+		case 0:
+			return
+		case 1:
+			if !ok {
+				open = false
+				continue
+			}
+			item, _ := recv.Interface().(T) // nil interface values assert to the zero T
+			if output != nil {
+				pending = append(pending, item)
+			}
+			for i, out := range outputs {
+				if out.Policy != TeeDrop {
+					queues[i] = append(queues[i], item)
+					continue
+				}
+				select {
+				case out.Ch <- item:
+				default:
+				}
+			}
+		case 2:
+			var zero T
+			pending[0] = zero
+			pending = pending[:0]
+		default:
+			i := chosen - 3
+			var zero T
+			queues[i][0] = zero
+			queues[i] = queues[i][1:]
+		}
+	}
+}
+
+func allEmpty[T any](queues [][]T) bool {
+	for _, q := range queues {
+		if len(q) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// valueOf is reflect.ValueOf that keeps the static type T, so nil interface
+// values can still be sent.
+func valueOf[T any](v T) reflect.Value {
+	return reflect.ValueOf(&v).Elem()
+}
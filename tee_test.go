@@ -0,0 +1,136 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/logimos/concurrent/concurrenttest"
+)
+
+func TestTeeTo(t *testing.T) {
+	t.Run("dropping output does not stall main flow", func(t *testing.T) {
+		ctx := context.Background()
+		slow := make(chan int) // never read until the end
+
+		output := TeeTo(TeeDropping(slow))(ctx, emitSlice(ctx, []int{1, 2, 3, 4, 5}))
+		results := Collect(ctx, output, 0)
+		if len(results) != 5 {
+			t.Errorf("Expected 5 results, got %d", len(results))
+		}
+		if _, ok := <-slow; ok {
+			t.Error("Expected dropping output to be closed and empty")
+		}
+	})
+
+	t.Run("buffered output catches up after input ends", func(t *testing.T) {
+		ctx := context.Background()
+		buffered := make(chan int)
+
+		output := TeeTo(TeeBuffered(buffered, 10))(ctx, emitSlice(ctx, []int{1, 2, 3, 4, 5}))
+		results := Collect(ctx, output, 5)
+		if len(results) != 5 {
+			t.Fatalf("Expected 5 results, got %d", len(results))
+		}
+		var teed []int
+		for v := range buffered {
+			teed = append(teed, v)
+		}
+		for i, v := range teed {
+			if v != i+1 {
+				t.Errorf("Expected %d at index %d, got %d", i+1, i, v)
+			}
+		}
+		if len(teed) != 5 {
+			t.Errorf("Expected 5 buffered items, got %d", len(teed))
+		}
+	})
+
+	t.Run("full buffer applies backpressure", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		buffered := make(chan int)
+
+		output := TeeTo(TeeBuffered(buffered, 2))(ctx, emitSlice(ctx, []int{1, 2, 3, 4, 5}))
+		for i := 1; i <= 2; i++ {
+			if v := <-output; v != i {
+				t.Fatalf("Expected %d, got %d", i, v)
+			}
+		}
+		select {
+		case v := <-output:
+			t.Fatalf("Expected output to stall on a full buffer, got %d", v)
+		case <-time.After(20 * time.Millisecond):
+		}
+		<-buffered
+		if v := <-output; v != 3 {
+			t.Errorf("Expected 3 after draining one item, got %d", v)
+		}
+	})
+
+	t.Run("nil interface values", func(t *testing.T) {
+		ctx := context.Background()
+		copies := make(chan error, 2)
+
+		output := TeeTo(TeeBlocking[error](copies))(ctx, emitSlice(ctx, []error{nil, errors.New("boom")}))
+		results := Collect(ctx, output, 0)
+		if len(results) != 2 || results[0] != nil || results[1] == nil {
+			t.Errorf("Expected [nil boom], got %v", results)
+		}
+		if err := <-copies; err != nil {
+			t.Errorf("Expected nil copy, got %v", err)
+		}
+	})
+
+	t.Run("context cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		input := make(chan int)
+		blocked := make(chan int)
+
+		output := TeeTo(TeeBlocking(blocked))(ctx, input)
+		cancel()
+		concurrenttest.AssertClosedWithin(t, output, time.Second)
+	})
+}
+
+func TestTeeOwnership(t *testing.T) {
+	t.Run("borrowed outputs stay open", func(t *testing.T) {
+		ctx := context.Background()
+		copies := make(chan int, 10)
+
+		Drain(ctx, TeeBorrowed[int](copies)(ctx, emitSlice(ctx, []int{1, 2})))
+		Drain(ctx, TeeTo(TeeBlocking[int](copies).Borrowed())(ctx, emitSlice(ctx, []int{3})))
+
+		copies <- 4 // would panic if closed
+		close(copies)
+		if got := Collect(ctx, copies, 0); len(got) != 4 {
+			t.Errorf("Expected 4 items from reused channel, got %v", got)
+		}
+	})
+
+	t.Run("TeeN", func(t *testing.T) {
+		ctx := context.Background()
+		outputs := TeeN(ctx, emitSlice(ctx, []int{1, 2, 3}), 3)
+		if len(outputs) != 3 {
+			t.Fatalf("Expected 3 outputs, got %d", len(outputs))
+		}
+
+		results := make([][]int, len(outputs))
+		done := make(chan int)
+		for i, out := range outputs {
+			go func() {
+				results[i] = Collect(ctx, out, 0)
+				done <- i
+			}()
+		}
+		for range outputs {
+			<-done
+		}
+		for i, got := range results {
+			if len(got) != 3 || got[0] != 1 || got[2] != 3 {
+				t.Errorf("Expected [1 2 3] on output %d, got %v", i, got)
+			}
+		}
+	})
+}
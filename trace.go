@@ -0,0 +1,68 @@
+package concurrent
+
+import "context"
+
+// SpanAttributes are key/value pairs attached to a span.
+type SpanAttributes map[string]any
+
+// Span is a unit of traced work started by a Tracer.
+type Span interface {
+	// End finishes the span, recording err if it is non-nil.
+	End(err error)
+}
+
+// Tracer starts spans. It lets pools and pipelines be traced without making
+// OpenTelemetry a dependency of this package; an adapter is only a few lines:
+//
+//	type otelTracer struct{ t trace.Tracer }
+//
+//	func (o otelTracer) Start(ctx context.Context, name string, attrs concurrent.SpanAttributes) (context.Context, concurrent.Span) {
+//		ctx, span := o.t.Start(ctx, name)
+//		for k, v := range attrs {
+//			span.SetAttributes(attribute.String(k, fmt.Sprint(v)))
+//		}
+//		return ctx, otelSpan{span}
+//	}
+type Tracer interface {
+	Start(ctx context.Context, name string, attrs SpanAttributes) (context.Context, Span)
+}
+
+// Trace wraps fn so that every call runs inside its own span.
+// The span's context is passed to fn, so downstream calls made by fn are
+// linked to it. attrs may be nil.
+func Trace[T any, R any](tracer Tracer, name string, attrs func(T) SpanAttributes, fn func(context.Context, T) (R, error)) func(context.Context, T) (R, error) {
+	return func(ctx context.Context, item T) (R, error) {
+		var a SpanAttributes
+		if attrs != nil {
+			a = attrs(item)
+		}
+		ctx, span := tracer.Start(ctx, name, a)
+		r, err := fn(ctx, item)
+		span.End(err)
+		return r, err
+	}
+}
+
+// TraceStage wraps a stage so that it runs inside a span covering its whole
+// lifetime. The stage receives the span's context, so work it starts is linked
+// to the span. The span ends when the stage's output closes, recording
+// ctx.Err() if the pipeline was cancelled.
+func TraceStage[T any, R any](tracer Tracer, name string, stage Stage[T, R]) Stage[T, R] {
+	return func(ctx context.Context, input <-chan T) <-chan R {
+		spanCtx, span := tracer.Start(ctx, name, nil)
+		inner := stage(spanCtx, input)
+		output := make(chan R)
+		go func() {
+			defer close(output)
+			defer func() { span.End(ctx.Err()) }()
+			for item := range inner {
+				select {
+				case <-ctx.Done():
+					return
+				case output <- item:
+				}
+			}
+		}()
+		return output
+	}
+}
@@ -0,0 +1,112 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+type spanKey struct{}
+
+type testSpan struct {
+	tracer *testTracer
+	name   string
+	attrs  SpanAttributes
+	err    error
+	ended  bool
+}
+
+func (s *testSpan) End(err error) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.err = err
+	s.ended = true
+}
+
+type testTracer struct {
+	mu    sync.Mutex
+	spans []*testSpan
+}
+
+func (tr *testTracer) Start(ctx context.Context, name string, attrs SpanAttributes) (context.Context, Span) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	s := &testSpan{tracer: tr, name: name, attrs: attrs}
+	tr.spans = append(tr.spans, s)
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+func TestTrace(t *testing.T) {
+	tracer := &testTracer{}
+	fn := Trace(tracer, "lookup", func(v int) SpanAttributes {
+		return SpanAttributes{"id": v}
+	}, func(ctx context.Context, v int) (int, error) {
+		if ctx.Value(spanKey{}) == nil {
+			t.Error("Expected span context to be propagated")
+		}
+		if v < 0 {
+			return 0, errors.New("negative")
+		}
+		return v, nil
+	})
+
+	_, _ = fn(context.Background(), 1)
+	_, _ = fn(context.Background(), -1)
+
+	if len(tracer.spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(tracer.spans))
+	}
+	if tracer.spans[0].attrs["id"] != 1 || tracer.spans[0].err != nil {
+		t.Errorf("Unexpected first span: %+v", tracer.spans[0])
+	}
+	if tracer.spans[1].err == nil {
+		t.Error("Expected second span to record the error")
+	}
+}
+
+func TestPoolTracer(t *testing.T) {
+	tracer := &testTracer{}
+	pool := NewPool[int, int](2, func(ctx context.Context, v int) (int, error) {
+		if ctx.Value(spanKey{}) == nil {
+			return 0, errors.New("missing span context")
+		}
+		return v, nil
+	},
+		WithTracer(tracer),
+		WithSpanName(func(item any) string { return fmt.Sprintf("job-%v", item) }),
+		WithSpanAttributes(func(item any) SpanAttributes { return SpanAttributes{"item": item} }),
+	)
+
+	if got := Collect(context.Background(), pool.Run(context.Background(), sliceChan(1, 2, 3)), 0); len(got) != 3 {
+		t.Fatalf("Expected 3 results, got %v", got)
+	}
+
+	tracer.mu.Lock()
+	defer tracer.mu.Unlock()
+	if len(tracer.spans) != 3 {
+		t.Fatalf("Expected 3 spans, got %d", len(tracer.spans))
+	}
+	for _, s := range tracer.spans {
+		if !s.ended || s.name != fmt.Sprintf("job-%v", s.attrs["item"]) {
+			t.Errorf("Unexpected span: %+v", s)
+		}
+	}
+}
+
+func TestTraceStage(t *testing.T) {
+	tracer := &testTracer{}
+	ctx := context.Background()
+
+	stage := TraceStage(tracer, "double", Map(func(v int) int { return v * 2 }))
+	if got := Collect(ctx, stage(ctx, sliceChan(1, 2)), 0); len(got) != 2 {
+		t.Fatalf("Expected 2 results, got %v", got)
+	}
+
+	tracer.mu.Lock()
+	defer tracer.mu.Unlock()
+	if len(tracer.spans) != 1 || tracer.spans[0].name != "double" || !tracer.spans[0].ended {
+		t.Errorf("Expected one ended stage span, got %+v", tracer.spans)
+	}
+}
@@ -0,0 +1,116 @@
+package concurrent
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// TraceEntry is an event recorded by a TraceSink, with its position in the
+// overall sequence.
+type TraceEntry struct {
+	Seq int
+	Event
+}
+
+// TraceStep matches recorded events by component and kind. An empty
+// Component matches any component.
+type TraceStep struct {
+	Component string
+	Kind      EventKind
+}
+
+func (s TraceStep) matches(e Event) bool {
+	return e.Kind == s.Kind && (s.Component == "" || s.Component == e.Component)
+}
+
+func (s TraceStep) String() string {
+	if s.Component == "" {
+		return "*:" + s.Kind.String()
+	}
+	return s.Component + ":" + s.Kind.String()
+}
+
+// TraceSink is an Observer that records every event it receives, in order,
+// so tests can assert on how a composition behaved rather than only on what
+// it produced. Attach it like any other observer, e.g. with WithObserver,
+// WithPipelineObserver or RetryConfig.Observer.
+type TraceSink struct {
+	mu      sync.Mutex
+	entries []TraceEntry
+}
+
+// NewTraceSink creates an empty trace sink.
+func NewTraceSink() *TraceSink {
+	return &TraceSink{}
+}
+
+// Observe implements Observer.
+func (s *TraceSink) Observe(e Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, TraceEntry{Seq: len(s.entries), Event: e})
+}
+
+// Entries returns the recorded events in the order they were observed.
+func (s *TraceSink) Entries() []TraceEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]TraceEntry(nil), s.entries...)
+}
+
+// Filter returns the recorded events matching step.
+func (s *TraceSink) Filter(step TraceStep) []TraceEntry {
+	var matched []TraceEntry
+	for _, entry := range s.Entries() {
+		if step.matches(entry.Event) {
+			matched = append(matched, entry)
+		}
+	}
+	return matched
+}
+
+// Count returns the number of recorded events matching step.
+func (s *TraceSink) Count(step TraceStep) int {
+	return len(s.Filter(step))
+}
+
+// Reset discards all recorded events.
+func (s *TraceSink) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = nil
+}
+
+// ExpectSequence reports whether steps occur in the recorded trace in the
+// given order, not necessarily adjacently. The error describes the first
+// step that could not be matched and the events recorded for context.
+//
+//	if err := sink.ExpectSequence(
+//		concurrent.TraceStep{Component: "api", Kind: concurrent.EventRetried},
+//		concurrent.TraceStep{Component: "api", Kind: concurrent.EventCompleted},
+//	); err != nil {
+//		t.Fatal(err)
+//	}
+func (s *TraceSink) ExpectSequence(steps ...TraceStep) error {
+	entries := s.Entries()
+	i := 0
+	for n, step := range steps {
+		for i < len(entries) && !step.matches(entries[i].Event) {
+			i++
+		}
+		if i == len(entries) {
+			return fmt.Errorf("trace: step %d (%s) not found in order; recorded %s", n, step, formatTrace(entries))
+		}
+		i++
+	}
+	return nil
+}
+
+func formatTrace(entries []TraceEntry) string {
+	parts := make([]string, len(entries))
+	for i, e := range entries {
+		parts[i] = TraceStep{Component: e.Component, Kind: e.Kind}.String()
+	}
+	return "[" + strings.Join(parts, " ") + "]"
+}
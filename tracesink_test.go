@@ -0,0 +1,78 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTraceSink(t *testing.T) {
+	t.Run("retry then success", func(t *testing.T) {
+		sink := NewTraceSink()
+		config := RetryConfig{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond, Multiplier: 1, Name: "api", Observer: sink}
+
+		attempts := 0
+		pool := NewPool[int, int](1, func(ctx context.Context, v int) (int, error) {
+			err := Retry(ctx, v, func(context.Context, int) error {
+				attempts++
+				if attempts == 1 {
+					return errors.New("flaky")
+				}
+				return nil
+			}, config)
+			return v, err
+		}, WithName("workers"), WithObserver(sink))
+		Drain(context.Background(), pool.Run(context.Background(), sliceChan(1)))
+
+		err := sink.ExpectSequence(
+			TraceStep{Component: "workers", Kind: EventStarted},
+			TraceStep{Component: "api", Kind: EventRetried},
+			TraceStep{Component: "workers", Kind: EventCompleted},
+		)
+		if err != nil {
+			t.Error(err)
+		}
+		if retried := sink.Filter(TraceStep{Kind: EventRetried}); len(retried) != 1 || retried[0].Attempt != 1 {
+			t.Errorf("Expected one retry after attempt 1, got %+v", retried)
+		}
+	})
+
+	t.Run("dropped and batched", func(t *testing.T) {
+		sink := NewTraceSink()
+		ctx := context.Background()
+		pool := NewPool[int, int](1, func(_ context.Context, v int) (int, error) {
+			if v < 0 {
+				return 0, errors.New("negative")
+			}
+			return v, nil
+		}, WithName("pool"), WithObserver(sink))
+		b := NewBatcher(BatcherConfig[int]{MaxItems: 2, Name: "batcher", Observer: sink}, func(context.Context, []int) error { return nil })
+
+		for v := range pool.Run(ctx, sliceChan(1, -1, 2)) {
+			_ = b.Add(ctx, v)
+		}
+
+		if n := sink.Count(TraceStep{Component: "pool", Kind: EventDropped}); n != 1 {
+			t.Errorf("Expected 1 dropped item, got %d", n)
+		}
+		batched := sink.Filter(TraceStep{Component: "batcher", Kind: EventBatched})
+		if len(batched) != 1 || batched[0].Depth != 2 {
+			t.Errorf("Expected one batch of 2, got %+v", batched)
+		}
+	})
+
+	t.Run("out of order", func(t *testing.T) {
+		sink := NewTraceSink()
+		sink.Observe(Event{Component: "a", Kind: EventCompleted})
+		sink.Observe(Event{Component: "a", Kind: EventStarted})
+
+		if err := sink.ExpectSequence(TraceStep{Component: "a", Kind: EventStarted}, TraceStep{Component: "a", Kind: EventCompleted}); err == nil {
+			t.Error("Expected sequence mismatch")
+		}
+		sink.Reset()
+		if len(sink.Entries()) != 0 {
+			t.Error("Expected reset to clear entries")
+		}
+	})
+}
@@ -0,0 +1,119 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidConfig is matched by every error returned from the validating
+// constructors such as NewPoolE.
+var ErrInvalidConfig = errors.New("concurrent: invalid configuration")
+
+// ConfigError describes one invalid constructor argument.
+type ConfigError struct {
+	Constructor string
+	Field       string
+	Reason      string
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("concurrent: %s: invalid %s: %s", e.Constructor, e.Field, e.Reason)
+}
+
+// Is reports whether target is ErrInvalidConfig.
+func (e *ConfigError) Is(target error) bool {
+	return target == ErrInvalidConfig
+}
+
+// validator collects ConfigErrors for one constructor call.
+type validator struct {
+	constructor string
+	errs        []error
+}
+
+func (v *validator) check(ok bool, field, format string, args ...any) {
+	if !ok {
+		v.errs = append(v.errs, &ConfigError{Constructor: v.constructor, Field: field, Reason: fmt.Sprintf(format, args...)})
+	}
+}
+
+func (v *validator) err() error {
+	return errors.Join(v.errs...)
+}
+
+// NewPoolE is like NewPool but returns an error describing every invalid
+// argument instead of silently substituting defaults.
+func NewPoolE[T any, R any](n int, fn func(context.Context, T) (R, error), opts ...PoolOption) (*Pool[T, R], error) {
+	v := validator{constructor: "NewPool"}
+	v.check(n > 0, "workers", "must be positive, got %d", n)
+	v.check(fn != nil, "fn", "must not be nil")
+	var options PoolOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if c := options.Chaos; c != nil {
+		for field, p := range map[string]float64{
+			"chaos latency probability": c.LatencyProbability,
+			"chaos error probability":   c.ErrorProbability,
+			"chaos panic probability":   c.PanicProbability,
+		} {
+			v.check(p >= 0 && p <= 1, field, "must be between 0 and 1, got %v", p)
+		}
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	return NewPool(n, fn, opts...), nil
+}
+
+// NewPipelineE is like NewPipeline but returns an error for a nil context.
+func NewPipelineE[T any](ctx context.Context, opts ...PipelineOption) (*Pipeline[T], error) {
+	v := validator{constructor: "NewPipeline"}
+	v.check(ctx != nil, "ctx", "must not be nil")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	return NewPipeline[T](ctx, opts...), nil
+}
+
+// NewRateLimiterE is like NewRateLimiter but returns an error for a
+// non-positive limit or interval.
+func NewRateLimiterE(limit int, interval time.Duration, opts ...LimiterOption) (*RateLimiter, error) {
+	v := validator{constructor: "NewRateLimiter"}
+	checkRate(&v, limit, interval)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	return NewRateLimiter(limit, interval, opts...), nil
+}
+
+// NewBurstRateLimitE is like NewBurstRateLimit but returns an error for a
+// non-positive limit or interval, or a burst below the limit.
+func NewBurstRateLimitE(limit int, interval time.Duration, burst int, opts ...LimiterOption) (*BurstRateLimit, error) {
+	v := validator{constructor: "NewBurstRateLimit"}
+	checkRate(&v, limit, interval)
+	v.check(burst >= limit, "burst", "must be at least the limit %d, got %d", limit, burst)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	return NewBurstRateLimit(limit, interval, burst, opts...), nil
+}
+
+// NewCircuitBreakerE is like NewCircuitBreaker but returns an error for a
+// non-positive failure threshold or negative reset timeout.
+func NewCircuitBreakerE(failureThreshold int, resetTimeout time.Duration, opts ...BreakerOption) (*CircuitBreaker, error) {
+	v := validator{constructor: "NewCircuitBreaker"}
+	v.check(failureThreshold > 0, "failure threshold", "must be positive, got %d", failureThreshold)
+	v.check(resetTimeout >= 0, "reset timeout", "must not be negative, got %v", resetTimeout)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	return NewCircuitBreaker(failureThreshold, resetTimeout, opts...), nil
+}
+
+func checkRate(v *validator, limit int, interval time.Duration) {
+	v.check(limit > 0, "limit", "must be positive, got %d", limit)
+	v.check(interval > 0, "interval", "must be positive, got %v", interval)
+}
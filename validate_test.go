@@ -0,0 +1,63 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidatingConstructors(t *testing.T) {
+	t.Run("pool", func(t *testing.T) {
+		_, err := NewPoolE[int, int](0, nil)
+		if !errors.Is(err, ErrInvalidConfig) {
+			t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+		}
+		if msg := err.Error(); !strings.Contains(msg, "workers") || !strings.Contains(msg, "fn") {
+			t.Errorf("Expected both problems to be reported, got %q", msg)
+		}
+
+		_, err = NewPoolE(1, func(_ context.Context, v int) (int, error) { return v, nil }, WithChaos(ChaosConfig{ErrorProbability: 2}))
+		var cfgErr *ConfigError
+		if !errors.As(err, &cfgErr) || cfgErr.Field != "chaos error probability" {
+			t.Errorf("Expected chaos probability error, got %v", err)
+		}
+
+		pool, err := NewPoolE(2, func(_ context.Context, v int) (int, error) { return v, nil })
+		if err != nil || pool.Workers() != 2 {
+			t.Errorf("Expected valid pool, got %v", err)
+		}
+	})
+
+	t.Run("pipeline", func(t *testing.T) {
+		var nilCtx context.Context
+		if _, err := NewPipelineE[int](nilCtx); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("Expected ErrInvalidConfig, got %v", err)
+		}
+		if _, err := NewPipelineE[int](context.Background()); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("rate limiters", func(t *testing.T) {
+		if _, err := NewRateLimiterE(0, time.Second); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("Expected error for zero limit, got %v", err)
+		}
+		if _, err := NewRateLimiterE(1, 0); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("Expected error for zero interval, got %v", err)
+		}
+		if _, err := NewBurstRateLimitE(10, time.Second, 5); err == nil || !strings.Contains(err.Error(), "at least the limit") {
+			t.Errorf("Expected error for burst below limit, got %v", err)
+		}
+		if _, err := NewBurstRateLimitE(10, time.Second, 50); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("circuit breaker", func(t *testing.T) {
+		if _, err := NewCircuitBreakerE(0, time.Second); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("Expected ErrInvalidConfig, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,228 @@
+package concurrent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileOp identifies the kind of change a FileEvent reports.
+type FileOp int
+
+const (
+	// FileCreated reports a file that appeared since the last scan.
+	FileCreated FileOp = iota
+	// FileModified reports a file whose size or modification time changed.
+	FileModified
+)
+
+// String returns the name of the operation.
+func (op FileOp) String() string {
+	switch op {
+	case FileCreated:
+		return "created"
+	case FileModified:
+		return "modified"
+	default:
+		return "unknown"
+	}
+}
+
+// FileEvent is a change to a file in a watched directory.
+type FileEvent struct {
+	Path string
+	Op   FileOp
+}
+
+// FileWatcher produces raw, undebounced file events for dir until ctx is
+// cancelled, then closes the returned channel. PollWatcher is the built-in,
+// dependency-free implementation; an fsnotify-backed one can be plugged into
+// WatchDir with WithFileWatcher.
+type FileWatcher interface {
+	Watch(ctx context.Context, dir string) (<-chan FileEvent, error)
+}
+
+// PollWatcher is a FileWatcher that rescans a directory (not recursively) at
+// a fixed interval. Files present at the first scan produce no events.
+type PollWatcher struct {
+	Interval time.Duration
+}
+
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// Watch implements FileWatcher.
+func (p PollWatcher) Watch(ctx context.Context, dir string) (<-chan FileEvent, error) {
+	interval := p.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	known, err := scanDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	output := make(chan FileEvent)
+	go func() {
+		defer close(output)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			current, err := scanDir(dir)
+			if err != nil {
+				// The directory may be briefly unavailable; try again next tick.
+				continue
+			}
+			for path, st := range current {
+				prev, ok := known[path]
+				if ok && prev == st {
+					continue
+				}
+				event := FileEvent{Path: path, Op: FileModified}
+				if !ok {
+					event.Op = FileCreated
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case output <- event:
+				}
+			}
+			known = current
+		}
+	}()
+	return output, nil
+}
+
+// scanDir returns the state of every regular file directly inside dir.
+func scanDir(dir string) (map[string]fileState, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]fileState, len(entries))
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue // removed since ReadDir
+		}
+		files[filepath.Join(dir, entry.Name())] = fileState{size: info.Size(), modTime: info.ModTime()}
+	}
+	return files, nil
+}
+
+// WatchDir emits created and modified file events for dir into a pipeline.
+// Rapid successive writes to a file are coalesced into a single event, sent
+// once the file has been quiet for the debounce period; a file created and
+// then written reports FileCreated. The channel closes when ctx is cancelled.
+func WatchDir(ctx context.Context, dir string, opts ...WatchOption) (<-chan FileEvent, error) {
+	options := DefaultWatchOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+	watcher := options.Watcher
+	if watcher == nil {
+		watcher = PollWatcher{Interval: options.Interval}
+	}
+	raw, err := watcher.Watch(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	if options.Filter != nil {
+		raw = Filter(func(e FileEvent) bool { return options.Filter(e.Path) })(ctx, raw)
+	}
+	if options.Debounce <= 0 {
+		return raw, nil
+	}
+	return debounceFileEvents(ctx, raw, options.Debounce), nil
+}
+
+// debounceFileEvents holds each path's event until no further event for that
+// path has arrived for quiet.
+func debounceFileEvents(ctx context.Context, input <-chan FileEvent, quiet time.Duration) <-chan FileEvent {
+	output := make(chan FileEvent)
+	go func() {
+		defer close(output)
+		type pending struct {
+			event FileEvent
+			timer *wheelTimer
+		}
+		waiting := make(map[string]*pending)
+		defer func() {
+			for _, p := range waiting {
+				p.timer.stop()
+			}
+		}()
+
+		// Timers fire on the shared wheel's goroutine, which must not block,
+		// so expired entries are queued for this goroutine to emit.
+		var mu sync.Mutex
+		var expired []*pending
+		ready := make(chan struct{}, 1)
+		expire := func(p *pending) func() {
+			return func() {
+				mu.Lock()
+				expired = append(expired, p)
+				mu.Unlock()
+				select {
+				case ready <- struct{}{}:
+				default:
+				}
+			}
+		}
+
+		for input != nil || len(waiting) > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case e, ok := <-input:
+				if !ok {
+					input = nil
+					// Flush everything still waiting on the next tick.
+					for _, p := range waiting {
+						p.timer.reset(0)
+					}
+					continue
+				}
+				if p, ok := waiting[e.Path]; ok {
+					p.timer.reset(quiet)
+					continue // keep the first op, so a new file stays FileCreated
+				}
+				p := &pending{event: e}
+				p.timer = sharedWheel().afterFunc(quiet, expire(p))
+				waiting[e.Path] = p
+			case <-ready:
+				mu.Lock()
+				due := expired
+				expired = nil
+				mu.Unlock()
+				for _, p := range due {
+					// Skip entries already emitted through an earlier firing.
+					if waiting[p.event.Path] != p {
+						continue
+					}
+					select {
+					case <-ctx.Done():
+						return
+					case output <- p.event:
+					}
+					p.timer.stop()
+					delete(waiting, p.event.Path)
+				}
+			}
+		}
+	}()
+	return output
+}
@@ -0,0 +1,87 @@
+package concurrent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// chanWatcher is a FileWatcher replaying events from a channel.
+type chanWatcher chan FileEvent
+
+func (w chanWatcher) Watch(context.Context, string) (<-chan FileEvent, error) {
+	return w, nil
+}
+
+func TestWatchDir(t *testing.T) {
+	t.Run("polling", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "existing.txt"), []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		events, err := WatchDir(ctx, dir, WithPollInterval(5*time.Millisecond), WithDebounce(0))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		path := filepath.Join(dir, "new.txt")
+		if err := os.WriteFile(path, []byte("hello"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case e := <-events:
+			if e.Path != path || e.Op != FileCreated {
+				t.Errorf("Expected created event for %s, got %+v", path, e)
+			}
+		case <-ctx.Done():
+			t.Fatal("Expected an event for the new file")
+		}
+
+		if err := os.WriteFile(path, []byte("hello, world"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case e := <-events:
+			if e.Path != path || e.Op != FileModified {
+				t.Errorf("Expected modified event for %s, got %+v", path, e)
+			}
+		case <-ctx.Done():
+			t.Fatal("Expected an event for the modified file")
+		}
+	})
+
+	t.Run("debounce and filter", func(t *testing.T) {
+		raw := make(chanWatcher)
+		ctx := context.Background()
+		events, err := WatchDir(ctx, "ignored", WithFileWatcher(raw), WithDebounce(20*time.Millisecond),
+			WithWatchFilter(func(path string) bool { return strings.HasSuffix(path, ".log") }))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		go func() {
+			raw <- FileEvent{Path: "a.log", Op: FileCreated}
+			raw <- FileEvent{Path: "a.log", Op: FileModified}
+			raw <- FileEvent{Path: "b.tmp", Op: FileCreated}
+			raw <- FileEvent{Path: "a.log", Op: FileModified}
+			close(raw)
+		}()
+
+		got := Collect(ctx, events, 0)
+		if len(got) != 1 || got[0].Path != "a.log" || got[0].Op != FileCreated {
+			t.Errorf("Expected a single created event for a.log, got %+v", got)
+		}
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		if _, err := WatchDir(context.Background(), filepath.Join(t.TempDir(), "missing")); err == nil {
+			t.Error("Expected error for missing directory")
+		}
+	})
+}
@@ -0,0 +1,166 @@
+package concurrent
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Stall describes a component that has work pending but has not made
+// progress for at least the watchdog threshold.
+type Stall struct {
+	Component    string
+	Pending      int64
+	LastProgress time.Time
+	Idle         time.Duration
+}
+
+// Watchdog detects stalled pool workers and pipeline stages, turning silent
+// backpressure deadlocks into diagnoses. It is an Observer: attach it to the
+// components to watch with WithObserver or WithPipelineObserver, then call Run.
+//
+// A pool is stalled when jobs have started but none has completed within the
+// threshold. A pipeline stage is stalled when the stage before it has handed
+// it items but it has not emitted anything within the threshold; stages that
+// legitimately drop most items, such as selective filters, may therefore be
+// reported spuriously.
+type Watchdog struct {
+	threshold time.Duration
+	onStall   func(Stall)
+
+	mu     sync.Mutex
+	comps  map[string]*watchState
+	stages map[string]int
+}
+
+// stageWatcher is implemented by observers that want to know the shape of the
+// pipelines they are attached to.
+type stageWatcher interface {
+	watchStages(pipeline string, stages int)
+}
+
+type watchState struct {
+	pending      int64
+	lastProgress time.Time
+	reported     bool
+}
+
+// NewWatchdog creates a watchdog that calls onStall once per stall episode
+// when a component makes no progress for threshold.
+func NewWatchdog(threshold time.Duration, onStall func(Stall)) *Watchdog {
+	if threshold <= 0 {
+		threshold = time.Second
+	}
+	return &Watchdog{threshold: threshold, onStall: onStall, comps: make(map[string]*watchState), stages: make(map[string]int)}
+}
+
+func (w *Watchdog) state(name string, now time.Time) *watchState {
+	s, ok := w.comps[name]
+	if !ok {
+		s = &watchState{lastProgress: now}
+		w.comps[name] = s
+	}
+	return s
+}
+
+// Observe implements Observer.
+func (w *Watchdog) Observe(e Event) {
+	now := e.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	switch e.Kind {
+	case EventStarted:
+		s := w.state(e.Component, now)
+		if s.pending == 0 {
+			s.lastProgress = now
+		}
+		s.pending++
+	case EventCompleted:
+		s := w.state(e.Component, now)
+		if s.pending > 0 {
+			s.pending--
+		}
+		s.lastProgress = now
+		s.reported = false
+	case EventEmitted:
+		s := w.state(e.Component, now)
+		s.pending = 0
+		s.lastProgress = now
+		s.reported = false
+		if next, ok := w.nextStage(e.Component); ok {
+			ns := w.state(next, now)
+			if ns.pending == 0 {
+				ns.lastProgress = now
+			}
+			ns.pending++
+		}
+	}
+}
+
+func (w *Watchdog) watchStages(pipeline string, stages int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.stages[pipeline] = stages
+}
+
+// nextStage returns the name of the pipeline stage following component, if
+// component is a stage of a pipeline the watchdog is attached to.
+func (w *Watchdog) nextStage(component string) (string, bool) {
+	i := strings.LastIndex(component, "/stage-")
+	if i < 0 {
+		return "", false
+	}
+	n, err := strconv.Atoi(component[i+len("/stage-"):])
+	if err != nil {
+		return "", false
+	}
+	pipeline := component[:i]
+	if stages, ok := w.stages[pipeline]; !ok || n+1 >= stages {
+		return "", false
+	}
+	return fmt.Sprintf("%s/stage-%d", pipeline, n+1), true
+}
+
+// Check reports components stalled as of now and marks them as reported.
+// Run calls it periodically; it is exported for deterministic tests.
+func (w *Watchdog) Check(now time.Time) []Stall {
+	w.mu.Lock()
+	var stalls []Stall
+	for name, s := range w.comps {
+		if s.pending == 0 || s.reported {
+			continue
+		}
+		if idle := now.Sub(s.lastProgress); idle >= w.threshold {
+			s.reported = true
+			stalls = append(stalls, Stall{Component: name, Pending: s.pending, LastProgress: s.lastProgress, Idle: idle})
+		}
+	}
+	w.mu.Unlock()
+
+	if w.onStall != nil {
+		for _, st := range stalls {
+			w.onStall(st)
+		}
+	}
+	return stalls
+}
+
+// Run checks for stalls every quarter threshold until ctx is cancelled.
+func (w *Watchdog) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.threshold / 4)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			w.Check(now)
+		}
+	}
+}
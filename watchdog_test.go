@@ -0,0 +1,74 @@
+package concurrent
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWatchdog(t *testing.T) {
+	t.Run("stalled pool", func(t *testing.T) {
+		var mu sync.Mutex
+		var stalls []Stall
+		wd := NewWatchdog(20*time.Millisecond, func(s Stall) {
+			mu.Lock()
+			defer mu.Unlock()
+			stalls = append(stalls, s)
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go wd.Run(ctx)
+
+		release := make(chan struct{})
+		pool := NewPool[int, int](1, func(_ context.Context, v int) (int, error) {
+			<-release
+			return v, nil
+		}, WithName("stuck"), WithObserver(wd))
+
+		results := pool.Run(ctx, sliceChan(1))
+		time.Sleep(60 * time.Millisecond)
+		close(release)
+		Drain(ctx, results)
+
+		mu.Lock()
+		defer mu.Unlock()
+		if len(stalls) != 1 || stalls[0].Component != "stuck" || stalls[0].Pending != 1 {
+			t.Errorf("Expected one stall for the stuck pool, got %+v", stalls)
+		}
+	})
+
+	t.Run("stalled stage", func(t *testing.T) {
+		wd := NewWatchdog(time.Second, nil)
+		base := time.Now()
+		wd.watchStages("p", 2)
+
+		wd.Observe(Event{Component: "p/stage-0", Kind: EventEmitted, Time: base})
+		wd.Observe(Event{Component: "p/stage-1", Kind: EventEmitted, Time: base})
+		wd.Observe(Event{Component: "p/stage-0", Kind: EventEmitted, Time: base.Add(time.Second)})
+
+		if stalls := wd.Check(base.Add(1500 * time.Millisecond)); len(stalls) != 0 {
+			t.Errorf("Expected no stalls yet, got %+v", stalls)
+		}
+		stalls := wd.Check(base.Add(2500 * time.Millisecond))
+		if len(stalls) != 1 || stalls[0].Component != "p/stage-1" {
+			t.Fatalf("Expected stage-1 to be stalled, got %+v", stalls)
+		}
+		if again := wd.Check(base.Add(5 * time.Second)); len(again) != 0 {
+			t.Errorf("Expected stall to be reported once, got %+v", again)
+		}
+	})
+
+	t.Run("healthy pipeline", func(t *testing.T) {
+		wd := NewWatchdog(time.Second, nil)
+		ctx := context.Background()
+		p := NewPipeline[int](ctx, WithPipelineName("p"), WithPipelineObserver(wd)).
+			AddStage(Map(func(v int) int { return v }))
+		Drain(ctx, p.Run(sliceChan(1, 2, 3)))
+
+		if stalls := wd.Check(time.Now().Add(time.Minute)); len(stalls) != 0 {
+			t.Errorf("Expected no stalls, got %+v", stalls)
+		}
+	})
+}
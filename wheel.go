@@ -0,0 +1,145 @@
+package concurrent
+
+import (
+	"sync"
+	"time"
+)
+
+// timerWheel is a hashed timer wheel: timers are kept in slots indexed by
+// their deadline modulo the wheel size, and a single ticker advances through
+// the slots. Scheduling and stopping are O(1), so hundreds of thousands of
+// pending per-item timeouts cost one goroutine and one ticker instead of one
+// runtime timer each. Timers fire on the tick at or after their deadline,
+// so resolution is one tick.
+type timerWheel struct {
+	mu      sync.Mutex
+	clock   Clock
+	tick    time.Duration
+	slots   []map[*wheelTimer]struct{}
+	pos     int
+	last    time.Time // time of the last processed tick
+	pending int
+	running bool
+}
+
+// wheelTimer is a timer scheduled on a timerWheel.
+type wheelTimer struct {
+	wheel  *timerWheel
+	fn     func()
+	slot   int
+	rounds int
+	active bool
+}
+
+// newTimerWheel creates a wheel with the given tick resolution and number
+// of slots. Its goroutine runs only while timers are pending.
+func newTimerWheel(clock Clock, tick time.Duration, slots int) *timerWheel {
+	w := &timerWheel{
+		clock: clockOrReal(clock),
+		tick:  max(tick, time.Millisecond),
+		slots: make([]map[*wheelTimer]struct{}, max(slots, 1)),
+	}
+	for i := range w.slots {
+		w.slots[i] = make(map[*wheelTimer]struct{})
+	}
+	return w
+}
+
+var sharedWheel = sync.OnceValue(func() *timerWheel {
+	return newTimerWheel(RealClock, 10*time.Millisecond, 512)
+})
+
+// afterFunc runs fn on the wheel's goroutine once d has elapsed. fn must not
+// block, since it holds up every other timer due on the same tick.
+func (w *timerWheel) afterFunc(d time.Duration, fn func()) *wheelTimer {
+	t := &wheelTimer{wheel: w, fn: fn}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.addLocked(t, d)
+	return t
+}
+
+// stop cancels the timer, reporting whether it was still pending.
+func (t *wheelTimer) stop() bool {
+	w := t.wheel
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.removeLocked(t)
+}
+
+// reset reschedules the timer to fire after d, reporting whether it was
+// still pending.
+func (t *wheelTimer) reset(d time.Duration) bool {
+	w := t.wheel
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	pending := w.removeLocked(t)
+	w.addLocked(t, d)
+	return pending
+}
+
+func (w *timerWheel) addLocked(t *wheelTimer, d time.Duration) {
+	now := w.clock.Now()
+	if !w.running {
+		w.running = true
+		w.last = now
+		go w.run()
+	}
+	// Count ticks from the last processed one so a partly elapsed tick
+	// doesn't make the timer fire early.
+	ticks := int((now.Add(d).Sub(w.last) + w.tick - 1) / w.tick)
+	ticks = max(ticks, 1)
+	t.slot = (w.pos + ticks) % len(w.slots)
+	t.rounds = (ticks - 1) / len(w.slots)
+	t.active = true
+	w.slots[t.slot][t] = struct{}{}
+	w.pending++
+}
+
+func (w *timerWheel) removeLocked(t *wheelTimer) bool {
+	if !t.active {
+		return false
+	}
+	delete(w.slots[t.slot], t)
+	t.active = false
+	w.pending--
+	return true
+}
+
+func (w *timerWheel) run() {
+	ticker := w.clock.NewTicker(w.tick)
+	defer ticker.Stop()
+	for range ticker.C() {
+		if !w.advance() {
+			return
+		}
+	}
+}
+
+// advance processes every tick elapsed since the last one, catching up on
+// ticks the ticker dropped, and fires due timers. It returns false, stopping
+// the goroutine, once no timers are pending.
+func (w *timerWheel) advance() bool {
+	w.mu.Lock()
+	var due []*wheelTimer
+	for w.clock.Now().Sub(w.last) >= w.tick {
+		w.last = w.last.Add(w.tick)
+		w.pos = (w.pos + 1) % len(w.slots)
+		for t := range w.slots[w.pos] {
+			if t.rounds > 0 {
+				t.rounds--
+				continue
+			}
+			w.removeLocked(t)
+			due = append(due, t)
+		}
+	}
+	running := w.pending > 0
+	w.running = running
+	w.mu.Unlock()
+
+	for _, t := range due {
+		t.fn()
+	}
+	return running
+}
@@ -0,0 +1,92 @@
+package concurrent
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimerWheel(t *testing.T) {
+	fired := func(ch chan int, id int) func() {
+		return func() { ch <- id }
+	}
+	expect := func(t *testing.T, ch chan int, id int) {
+		t.Helper()
+		select {
+		case got := <-ch:
+			if got != id {
+				t.Errorf("Expected timer %d to fire, got %d", id, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Expected timer %d to fire", id)
+		}
+	}
+	expectNone := func(t *testing.T, ch chan int) {
+		t.Helper()
+		select {
+		case got := <-ch:
+			t.Errorf("Expected no timer to fire, got %d", got)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	t.Run("fires in deadline order across rounds", func(t *testing.T) {
+		clock := NewFakeClock(time.Now())
+		w := newTimerWheel(clock, 10*time.Millisecond, 4)
+		ch := make(chan int, 4)
+		w.afterFunc(95*time.Millisecond, fired(ch, 3))
+		w.afterFunc(25*time.Millisecond, fired(ch, 2))
+		w.afterFunc(5*time.Millisecond, fired(ch, 1))
+		clock.BlockUntil(1)
+
+		clock.Advance(10 * time.Millisecond)
+		expect(t, ch, 1)
+		clock.Advance(10 * time.Millisecond)
+		expectNone(t, ch)
+		clock.Advance(10 * time.Millisecond)
+		expect(t, ch, 2)
+		// 95ms is more than two full turns of a 40ms wheel.
+		clock.Advance(60 * time.Millisecond)
+		expectNone(t, ch)
+		clock.Advance(10 * time.Millisecond)
+		expect(t, ch, 3)
+	})
+
+	t.Run("stop and reset", func(t *testing.T) {
+		clock := NewFakeClock(time.Now())
+		w := newTimerWheel(clock, 10*time.Millisecond, 8)
+		ch := make(chan int, 2)
+		stopped := w.afterFunc(10*time.Millisecond, fired(ch, 1))
+		reset := w.afterFunc(10*time.Millisecond, fired(ch, 2))
+		clock.BlockUntil(1)
+
+		if !stopped.stop() {
+			t.Error("Expected stop to report a pending timer")
+		}
+		if stopped.stop() {
+			t.Error("Expected a second stop to report no pending timer")
+		}
+		reset.reset(30 * time.Millisecond)
+		clock.Advance(20 * time.Millisecond)
+		expectNone(t, ch)
+		clock.Advance(10 * time.Millisecond)
+		expect(t, ch, 2)
+	})
+
+	t.Run("goroutine stops when idle", func(t *testing.T) {
+		clock := NewFakeClock(time.Now())
+		w := newTimerWheel(clock, 10*time.Millisecond, 8)
+		ch := make(chan int, 2)
+		w.afterFunc(10*time.Millisecond, fired(ch, 1))
+		clock.BlockUntil(1)
+		clock.Advance(10 * time.Millisecond)
+		expect(t, ch, 1)
+		for clock.Waiters() != 0 {
+			time.Sleep(time.Millisecond)
+		}
+
+		w.afterFunc(10*time.Millisecond, fired(ch, 2))
+		clock.BlockUntil(1)
+		clock.Advance(10 * time.Millisecond)
+		expect(t, ch, 2)
+	})
+}